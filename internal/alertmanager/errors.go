@@ -17,6 +17,8 @@ var (
 	ErrInvalidTimeRange   = errors.New("endsAt must be after startsAt")
 	ErrInvalidJSON        = errors.New("invalid JSON payload")
 	ErrPayloadTooLarge    = errors.New("payload too large")
+	ErrMissingLabels      = errors.New("at least one label pair required")
+	ErrEmptyLabelName     = errors.New("label name cannot be empty")
 )
 
 // AlertValidationError represents an error validating a specific alert
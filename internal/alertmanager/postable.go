@@ -0,0 +1,256 @@
+package alertmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// fnv64Offset and fnv64Prime are the FNV-1a constants used by Alertmanager fingerprints
+	fnv64Offset uint64 = 14695981039346656037
+	fnv64Prime  uint64 = 1099511628211
+
+	// labelSeparator separates label names and values when hashing
+	labelSeparator byte = 255
+
+	// GroupByAll groups alerts by all of their labels
+	GroupByAll = "..."
+)
+
+// PostableAlert represents an alert in the Alertmanager API v2 POST /api/v2/alerts format
+type PostableAlert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	StartsAt     time.Time         `json:"startsAt,omitempty"`
+	EndsAt       time.Time         `json:"endsAt,omitempty"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+// ParsePostableAlerts parses an Alertmanager API v2 alerts request body
+func ParsePostableAlerts(r *http.Request) ([]PostableAlert, error) {
+	r.Body = http.MaxBytesReader(nil, r.Body, MaxPayloadSize)
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		if err.Error() == "http: request body too large" {
+			return nil, ErrPayloadTooLarge
+		}
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	var alerts []PostableAlert
+	if err := json.Unmarshal(body, &alerts); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+	}
+
+	if len(alerts) == 0 {
+		return nil, ErrNoAlerts
+	}
+
+	for i := range alerts {
+		if err := alerts[i].IsValid(); err != nil {
+			return nil, NewAlertValidationError(i, err)
+		}
+	}
+
+	return alerts, nil
+}
+
+// IsValid validates a postable alert
+func (p *PostableAlert) IsValid() error {
+	if len(p.Labels) == 0 {
+		return ErrMissingLabels
+	}
+
+	for name := range p.Labels {
+		if name == "" {
+			return ErrEmptyLabelName
+		}
+	}
+
+	if !p.StartsAt.IsZero() && !p.EndsAt.IsZero() && p.EndsAt.Before(p.StartsAt) {
+		return ErrInvalidTimeRange
+	}
+
+	return nil
+}
+
+// ToAlert converts a postable alert into a webhook alert as Alertmanager would at the given time
+func (p *PostableAlert) ToAlert(now time.Time) Alert {
+	alert := Alert{
+		Status:       "firing",
+		Labels:       copyStringMap(p.Labels),
+		Annotations:  copyStringMap(p.Annotations),
+		StartsAt:     p.StartsAt,
+		EndsAt:       p.EndsAt,
+		GeneratorURL: p.GeneratorURL,
+		Fingerprint:  Fingerprint(p.Labels),
+	}
+
+	if alert.StartsAt.IsZero() {
+		if !alert.EndsAt.IsZero() && alert.EndsAt.Before(now) {
+			alert.StartsAt = alert.EndsAt
+		} else {
+			alert.StartsAt = now
+		}
+	}
+
+	if !alert.EndsAt.IsZero() && !alert.EndsAt.After(now) {
+		alert.Status = "resolved"
+	}
+
+	if alert.Annotations == nil {
+		alert.Annotations = map[string]string{}
+	}
+
+	return alert
+}
+
+// Fingerprint computes the Alertmanager fingerprint of a label set
+func Fingerprint(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sum := fnv64Offset
+	for _, name := range names {
+		sum = fnv64Add(sum, name)
+		sum = fnv64AddByte(sum, labelSeparator)
+		sum = fnv64Add(sum, labels[name])
+		sum = fnv64AddByte(sum, labelSeparator)
+	}
+
+	return fmt.Sprintf("%016x", sum)
+}
+
+// GroupAlerts groups alerts into webhook payloads by the given label names
+func GroupAlerts(alerts []Alert, groupBy []string, receiver, externalURL string) []*WebhookPayload {
+	groupAll := false
+	for _, name := range groupBy {
+		if name == GroupByAll {
+			groupAll = true
+			break
+		}
+	}
+
+	groups := make(map[string]*WebhookPayload)
+	order := make([]string, 0)
+
+	for _, alert := range alerts {
+		groupLabels := make(map[string]string)
+		if groupAll {
+			groupLabels = copyStringMap(alert.Labels)
+		} else {
+			for _, name := range groupBy {
+				if value, ok := alert.Labels[name]; ok {
+					groupLabels[name] = value
+				}
+			}
+		}
+
+		key := "{}:" + formatLabelSet(groupLabels)
+
+		payload, exists := groups[key]
+		if !exists {
+			payload = &WebhookPayload{
+				Version:     "4",
+				GroupKey:    key,
+				Receiver:    receiver,
+				GroupLabels: groupLabels,
+				ExternalURL: externalURL,
+			}
+			groups[key] = payload
+			order = append(order, key)
+		}
+
+		payload.Alerts = append(payload.Alerts, alert)
+	}
+
+	payloads := make([]*WebhookPayload, 0, len(order))
+	for _, key := range order {
+		payload := groups[key]
+		payload.Status = "resolved"
+		for _, alert := range payload.Alerts {
+			if alert.IsFiring() {
+				payload.Status = "firing"
+				break
+			}
+		}
+		payload.CommonLabels = commonLabels(payload.Alerts, func(a *Alert) map[string]string { return a.Labels })
+		payload.CommonAnnotations = commonLabels(payload.Alerts, func(a *Alert) map[string]string { return a.Annotations })
+		payloads = append(payloads, payload)
+	}
+
+	return payloads
+}
+
+// commonLabels returns the key/value pairs shared by all alerts
+func commonLabels(alerts []Alert, get func(*Alert) map[string]string) map[string]string {
+	common := make(map[string]string)
+	if len(alerts) == 0 {
+		return common
+	}
+
+	for k, v := range get(&alerts[0]) {
+		common[k] = v
+	}
+
+	for i := 1; i < len(alerts); i++ {
+		values := get(&alerts[i])
+		for k, v := range common {
+			if values[k] != v {
+				delete(common, k)
+			}
+		}
+	}
+
+	return common
+}
+
+// formatLabelSet formats labels the way Alertmanager renders group keys
+func formatLabelSet(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", name, labels[name]))
+	}
+
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+func fnv64Add(h uint64, s string) uint64 {
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= fnv64Prime
+	}
+	return h
+}
+
+func fnv64AddByte(h uint64, b byte) uint64 {
+	h ^= uint64(b)
+	h *= fnv64Prime
+	return h
+}
+
+func copyStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
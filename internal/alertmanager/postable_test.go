@@ -0,0 +1,191 @@
+package alertmanager
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		name     string
+		labels   map[string]string
+		expected string
+	}{
+		{
+			name:     "empty label set",
+			labels:   nil,
+			expected: "cbf29ce484222325",
+		},
+		{
+			name: "multiple labels",
+			labels: map[string]string{
+				"alertname": "HighCPU",
+				"instance":  "server1",
+			},
+			expected: "5258f5e254367467",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Fingerprint(tt.labels))
+		})
+	}
+}
+
+func TestParsePostableAlerts(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantErr     bool
+		errContains string
+		wantCount   int
+	}{
+		{
+			name:      "valid alerts",
+			body:      `[{"labels":{"alertname":"A"}},{"labels":{"alertname":"B"},"annotations":{"summary":"b"}}]`,
+			wantCount: 2,
+		},
+		{
+			name:        "invalid JSON",
+			body:        `{invalid}`,
+			wantErr:     true,
+			errContains: "invalid JSON",
+		},
+		{
+			name:        "empty list",
+			body:        `[]`,
+			wantErr:     true,
+			errContains: "no alerts",
+		},
+		{
+			name:        "missing labels",
+			body:        `[{"annotations":{"summary":"x"}}]`,
+			wantErr:     true,
+			errContains: "at least one label pair required",
+		},
+		{
+			name:        "invalid time range",
+			body:        `[{"labels":{"alertname":"A"},"startsAt":"2024-01-01T12:00:00Z","endsAt":"2024-01-01T11:00:00Z"}]`,
+			wantErr:     true,
+			errContains: "alert[0] validation failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/v2/alerts", strings.NewReader(tt.body))
+			alerts, err := ParsePostableAlerts(req)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Len(t, alerts, tt.wantCount)
+		})
+	}
+}
+
+func TestParsePostableAlerts_TooLarge(t *testing.T) {
+	req := httptest.NewRequest("POST", "/api/v2/alerts", bytes.NewReader(make([]byte, MaxPayloadSize+1)))
+	_, err := ParsePostableAlerts(req)
+	assert.ErrorIs(t, err, ErrPayloadTooLarge)
+}
+
+func TestPostableAlert_ToAlert(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("firing without timestamps", func(t *testing.T) {
+		p := PostableAlert{Labels: map[string]string{"alertname": "A"}}
+		alert := p.ToAlert(now)
+
+		assert.Equal(t, "firing", alert.Status)
+		assert.Equal(t, now, alert.StartsAt)
+		assert.True(t, alert.EndsAt.IsZero())
+		assert.Equal(t, Fingerprint(p.Labels), alert.Fingerprint)
+		assert.NotNil(t, alert.Annotations)
+		assert.NoError(t, alert.IsValid())
+	})
+
+	t.Run("resolved when endsAt is in the past", func(t *testing.T) {
+		p := PostableAlert{
+			Labels:   map[string]string{"alertname": "A"},
+			StartsAt: now.Add(-time.Hour),
+			EndsAt:   now.Add(-time.Minute),
+		}
+		alert := p.ToAlert(now)
+
+		assert.Equal(t, "resolved", alert.Status)
+		assert.NoError(t, alert.IsValid())
+	})
+
+	t.Run("firing when endsAt is in the future", func(t *testing.T) {
+		p := PostableAlert{
+			Labels: map[string]string{"alertname": "A"},
+			EndsAt: now.Add(time.Minute),
+		}
+		alert := p.ToAlert(now)
+
+		assert.Equal(t, "firing", alert.Status)
+		assert.Equal(t, now, alert.StartsAt)
+	})
+
+	t.Run("resolved without startsAt", func(t *testing.T) {
+		p := PostableAlert{
+			Labels: map[string]string{"alertname": "A"},
+			EndsAt: now.Add(-time.Minute),
+		}
+		alert := p.ToAlert(now)
+
+		assert.Equal(t, "resolved", alert.Status)
+		assert.NoError(t, alert.IsValid())
+	})
+}
+
+func TestGroupAlerts(t *testing.T) {
+	now := time.Now()
+	alerts := []Alert{
+		{Status: "firing", StartsAt: now, Fingerprint: "1", Labels: map[string]string{"alertname": "A", "instance": "a1", "env": "prod"}, Annotations: map[string]string{"summary": "s"}},
+		{Status: "resolved", StartsAt: now, Fingerprint: "2", Labels: map[string]string{"alertname": "A", "instance": "a2", "env": "prod"}, Annotations: map[string]string{"summary": "s"}},
+		{Status: "resolved", StartsAt: now, Fingerprint: "3", Labels: map[string]string{"alertname": "B", "instance": "b1"}},
+	}
+
+	t.Run("group by alertname", func(t *testing.T) {
+		payloads := GroupAlerts(alerts, []string{"alertname"}, "recv", "http://gw")
+		require.Len(t, payloads, 2)
+
+		assert.Equal(t, `{}:{alertname="A"}`, payloads[0].GroupKey)
+		assert.Equal(t, "firing", payloads[0].Status)
+		assert.Equal(t, "recv", payloads[0].Receiver)
+		assert.Equal(t, "http://gw", payloads[0].ExternalURL)
+		assert.Len(t, payloads[0].Alerts, 2)
+		assert.Equal(t, map[string]string{"alertname": "A"}, payloads[0].GroupLabels)
+		assert.Equal(t, map[string]string{"alertname": "A", "env": "prod"}, payloads[0].CommonLabels)
+		assert.Equal(t, map[string]string{"summary": "s"}, payloads[0].CommonAnnotations)
+		assert.NoError(t, payloads[0].IsValid())
+
+		assert.Equal(t, `{}:{alertname="B"}`, payloads[1].GroupKey)
+		assert.Equal(t, "resolved", payloads[1].Status)
+	})
+
+	t.Run("group by all labels", func(t *testing.T) {
+		payloads := GroupAlerts(alerts, []string{GroupByAll}, "recv", "")
+		assert.Len(t, payloads, 3)
+	})
+
+	t.Run("no group by labels", func(t *testing.T) {
+		payloads := GroupAlerts(alerts, nil, "recv", "")
+		require.Len(t, payloads, 1)
+		assert.Equal(t, "{}:{}", payloads[0].GroupKey)
+		assert.Len(t, payloads[0].Alerts, 3)
+	})
+}
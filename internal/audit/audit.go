@@ -0,0 +1,90 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/vitalvas/alertmanager-gateway/internal/requestid"
+)
+
+// Event is one audit log entry
+type Event struct {
+	Timestamp time.Time              `json:"timestamp"`
+	RequestID string                 `json:"request_id,omitempty"`
+	Actor     string                 `json:"actor,omitempty"`
+	Action    string                 `json:"action"`
+	Target    string                 `json:"target,omitempty"`
+	Result    string                 `json:"result,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// Logger appends audit events to a JSON lines file. A nil Logger discards events.
+type Logger struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// New opens the audit log file for appending
+func New(path string) (*Logger, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	return &Logger{file: file}, nil
+}
+
+// Log writes an event, filling in the timestamp and the request id of the context
+func (l *Logger) Log(ctx context.Context, event Event) error {
+	if l == nil {
+		return nil
+	}
+
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+	if event.RequestID == "" {
+		event.RequestID = requestid.FromContext(ctx)
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit event: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return fmt.Errorf("audit log is closed")
+	}
+
+	if _, err := l.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit event: %w", err)
+	}
+
+	return nil
+}
+
+// Close closes the audit log file
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+
+	err := l.file.Close()
+	l.file = nil
+
+	return err
+}
@@ -0,0 +1,59 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/alertmanager-gateway/internal/requestid"
+)
+
+func TestLogger_Log(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	logger, err := New(path)
+	require.NoError(t, err)
+
+	ctx := requestid.NewContext(context.Background(), "req-1")
+	require.NoError(t, logger.Log(ctx, Event{Actor: "admin", Action: "destination.create", Target: "chat"}))
+	require.NoError(t, logger.Log(context.Background(), Event{Action: "destination.delete", Target: "chat", Result: "error"}))
+	require.NoError(t, logger.Close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var event Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		events = append(events, event)
+	}
+
+	require.Len(t, events, 2)
+	assert.Equal(t, "req-1", events[0].RequestID)
+	assert.Equal(t, "admin", events[0].Actor)
+	assert.False(t, events[0].Timestamp.IsZero())
+	assert.Empty(t, events[1].RequestID)
+	assert.Equal(t, "error", events[1].Result)
+
+	assert.Error(t, logger.Log(context.Background(), Event{Action: "after.close"}))
+}
+
+func TestLogger_Nil(t *testing.T) {
+	var logger *Logger
+
+	assert.NoError(t, logger.Log(context.Background(), Event{Action: "noop"}))
+	assert.NoError(t, logger.Close())
+}
+
+func TestNew_InvalidPath(t *testing.T) {
+	_, err := New(filepath.Join(t.TempDir(), "missing", "audit.jsonl"))
+	assert.Error(t, err)
+}
@@ -156,6 +156,27 @@ func (tc *TemplateCache) Stats() Stats {
 	return tc.stats
 }
 
+// Entries returns a copy of the cached entries, most recently used first
+func (tc *TemplateCache) Entries() []TemplateCacheEntry {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	entries := make([]TemplateCacheEntry, 0, tc.lru.Len())
+	for elem := tc.lru.Front(); elem != nil; elem = elem.Next() {
+		entries = append(entries, *elem.Value.(*TemplateCacheEntry))
+	}
+
+	return entries
+}
+
+// Len returns the number of cached entries
+func (tc *TemplateCache) Len() int {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	return tc.lru.Len()
+}
+
 // Purge removes expired entries
 func (tc *TemplateCache) Purge() int {
 	tc.mu.Lock()
@@ -459,3 +459,19 @@ func TestTemplateCache_Stats(t *testing.T) {
 		assert.Equal(t, 3, stats.TotalSize)
 	})
 }
+
+func TestTemplateCache_Entries(t *testing.T) {
+	cache := NewTemplateCache(5, time.Hour)
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+	cache.Get("key1")
+
+	entries := cache.Entries()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, 2, cache.Len())
+
+	// Most recently used first
+	assert.Equal(t, "key1", entries[0].Key)
+	assert.Equal(t, int64(1), entries[0].AccessCount)
+	assert.Equal(t, "key2", entries[1].Key)
+}
@@ -3,6 +3,10 @@ package config
 import (
 	"fmt"
 	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"time"
 
 	"github.com/vitalvas/gokit/xconfig"
@@ -10,11 +14,19 @@ import (
 
 // LoadConfig loads configuration from a YAML file using xconfig
 func LoadConfig(path string) (*Config, error) {
+	return LoadConfigWithOverlay(path, nil)
+}
+
+// LoadConfigWithOverlay loads configuration from a YAML file and merges overlay over its
+// destinations. A nil overlay reads the overlay file of the configuration, if any.
+func LoadConfigWithOverlay(path string, overlay *Overlay) (*Config, error) {
 	var config Config
 
 	// Load configuration using xconfig with defaults
 	defaultConfig := Config{}
 	defaultConfig.setDefaults()
+	// The address default depends on the configured listeners, so it is set after loading
+	defaultConfig.Server.Address = ""
 
 	err := xconfig.Load(&config,
 		xconfig.WithDefault(defaultConfig),
@@ -28,6 +40,24 @@ func LoadConfig(path string) (*Config, error) {
 	// Set any remaining defaults that might not have been set
 	config.setDefaults()
 
+	// Merge destinations managed through the API; overlay entries are stored with defaults applied
+	if overlay == nil && config.Management.OverlayFile != "" {
+		overlay, err = LoadOverlay(config.Management.OverlayFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if overlay != nil {
+		config.ApplyOverlay(overlay)
+	}
+
+	config.initMetadata(time.Now().UTC())
+	config.path = path
+
+	if err := config.loadSourceFiles(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+
 	// Validate configuration
 	if err := config.Validate(); err != nil {
 		return nil, fmt.Errorf("config validation failed: %w", err)
@@ -38,16 +68,71 @@ func LoadConfig(path string) (*Config, error) {
 
 // setDefaults sets default values for configuration
 func (c *Config) setDefaults() {
-	// Address defaults to :8080 for dual stack (IPv4 and IPv6)
-	if c.Server.Address == "" {
+	// Address defaults to :8080 for dual stack (IPv4 and IPv6) unless only other listeners are configured
+	if c.Server.Address == "" && len(c.Server.Listeners) == 0 {
 		c.Server.Address = ":8080"
 	}
+	for i := range c.Server.Listeners {
+		if c.Server.Listeners[i].Type == ListenerTypeUnix && c.Server.Listeners[i].SocketMode == "" {
+			c.Server.Listeners[i].SocketMode = "0660"
+		}
+	}
 	if c.Server.ReadTimeout == 0 {
 		c.Server.ReadTimeout = 30 * time.Second
 	}
 	if c.Server.WriteTimeout == 0 {
 		c.Server.WriteTimeout = 30 * time.Second
 	}
+	if c.Server.WebhookTimeout == 0 {
+		c.Server.WebhookTimeout = 30 * time.Second
+	}
+	if c.Server.WatchInterval == 0 {
+		c.Server.WatchInterval = 10 * time.Second
+	}
+	if c.Server.DrainTimeout == 0 {
+		c.Server.DrainTimeout = 30 * time.Second
+	}
+
+	if c.AlertsAPI.Receiver == "" {
+		c.AlertsAPI.Receiver = "alerts-api"
+	}
+
+	if len(c.AlertsAPI.GroupBy) == 0 {
+		c.AlertsAPI.GroupBy = []string{"alertname"}
+	}
+
+	if c.State.SnapshotInterval == 0 {
+		c.State.SnapshotInterval = time.Minute
+	}
+
+	if c.State.Retention == 0 {
+		c.State.Retention = 24 * time.Hour
+	}
+
+	if c.Server.RequestIDHeader == "" {
+		c.Server.RequestIDHeader = "X-Request-ID"
+	}
+
+	if c.Tracing.Exporter == "" {
+		c.Tracing.Exporter = "otlp"
+	}
+	if c.Tracing.ServiceName == "" {
+		c.Tracing.ServiceName = "alertmanager-gateway"
+	}
+	if c.Tracing.SampleRatio == 0 {
+		c.Tracing.SampleRatio = 1
+	}
+
+	if c.Templates.CacheSize == 0 {
+		c.Templates.CacheSize = 1000
+	}
+	if c.Templates.CacheTTL == 0 {
+		c.Templates.CacheTTL = time.Hour
+	}
+
+	if c.History.Size == 0 {
+		c.History.Size = 1000
+	}
 
 	for i := range c.Destinations {
 		dest := &c.Destinations[i]
@@ -57,33 +142,249 @@ func (c *Config) setDefaults() {
 			dest.Enabled = true
 		}
 
-		// Default HTTP method
-		if dest.Method == "" {
-			dest.Method = http.MethodPost
+		dest.SetDefaults()
+	}
+}
+
+// SetDefaults sets default values for a destination
+func (d *DestinationConfig) SetDefaults() {
+	// Default HTTP method
+	if d.Method == "" {
+		d.Method = http.MethodPost
+	}
+
+	// Default format
+	if d.Format == "" {
+		d.Format = "json"
+	}
+
+	// Default engine
+	if d.Engine == "" {
+		if d.Transform != "" || d.TransformFile != "" {
+			d.Engine = "jq"
+		} else {
+			d.Engine = "go-template"
+		}
+	}
+
+	// Default batch size for split alerts
+	if d.SplitAlerts && d.BatchSize == 0 {
+		d.BatchSize = 1
+	}
+
+	// Default parallel requests
+	if d.ParallelRequests == 0 {
+		d.ParallelRequests = 1
+	}
+
+	// Default to sending the transformation result as the body
+	if d.OutputMode == "" {
+		d.OutputMode = OutputModeBody
+	}
+
+	// Default behaviour outside active time intervals
+	if d.OutsideActive == "" {
+		d.OutsideActive = OutsideActiveDrop
+	}
+
+	// Default to rejecting alerts while paused so Alertmanager retries them
+	if d.Pause.Mode == "" {
+		d.Pause.Mode = PauseModeReject
+	}
+	if d.Pause.BufferSize == 0 {
+		d.Pause.BufferSize = 1000
+	}
+
+	if d.HealthCheck.URL != "" {
+		if d.HealthCheck.Method == "" {
+			d.HealthCheck.Method = http.MethodHead
+		}
+		if d.HealthCheck.Interval == 0 {
+			d.HealthCheck.Interval = 30 * time.Second
+		}
+		if d.HealthCheck.Timeout == 0 {
+			d.HealthCheck.Timeout = 5 * time.Second
+		}
+	}
+}
+
+// loadSourceFiles reads the template and transform files of destinations, resolving
+// relative paths against dir
+func (c *Config) loadSourceFiles(dir string) error {
+	for i := range c.Destinations {
+		if err := c.Destinations[i].LoadSourceFiles(dir); err != nil {
+			return fmt.Errorf("destination %s: %w", c.Destinations[i].Name, err)
 		}
+	}
+
+	return nil
+}
 
-		// Default format
-		if dest.Format == "" {
-			dest.Format = "json"
+// LoadSourceFiles reads the template_file and transform_file of a destination into
+// template and transform, resolving relative paths against dir
+func (d *DestinationConfig) LoadSourceFiles(dir string) error {
+	if d.TemplateFile != "" {
+		if d.Template != "" {
+			return fmt.Errorf("template and template_file are mutually exclusive")
 		}
 
-		// Default engine
-		if dest.Engine == "" {
-			if dest.Transform != "" {
-				dest.Engine = "jq"
-			} else {
-				dest.Engine = "go-template"
+		file, content, err := readSourceFile(dir, d.TemplateFile)
+		if err != nil {
+			return fmt.Errorf("failed to read template_file: %w", err)
+		}
+		d.TemplateFile, d.Template = file, content
+	}
+
+	if d.TransformFile != "" {
+		if d.Transform != "" {
+			return fmt.Errorf("transform and transform_file are mutually exclusive")
+		}
+
+		file, content, err := readSourceFile(dir, d.TransformFile)
+		if err != nil {
+			return fmt.Errorf("failed to read transform_file: %w", err)
+		}
+		d.TransformFile, d.Transform = file, content
+	}
+
+	return nil
+}
+
+// ClearFileContent drops template and transform content that was read from a file,
+// so only the file path remains and the content is read again on load
+func (d *DestinationConfig) ClearFileContent() {
+	if d.TemplateFile != "" {
+		d.Template = ""
+	}
+	if d.TransformFile != "" {
+		d.Transform = ""
+	}
+}
+
+// readSourceFile reads a file relative to dir and returns its resolved path and content
+func readSourceFile(dir, file string) (string, string, error) {
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(dir, file)
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return "", "", err
+	}
+
+	return file, string(content), nil
+}
+
+// ResolveSourceFile resolves a template_file or transform_file submitted through the API
+// against root. Absolute paths and paths leaving root, also through symlinks, are rejected.
+func ResolveSourceFile(root, file string) (string, error) {
+	if filepath.IsAbs(file) {
+		return "", fmt.Errorf("absolute path %s is not allowed", file)
+	}
+
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(root, file)
+	if !isWithin(root, path) {
+		return "", fmt.Errorf("path %s is outside of %s", file, root)
+	}
+
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", err
+	}
+
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+
+	if !isWithin(resolvedRoot, resolved) {
+		return "", fmt.Errorf("path %s links outside of %s", file, root)
+	}
+
+	return path, nil
+}
+
+// isWithin reports whether path is root or inside it
+func isWithin(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// WatchedFiles returns the template files the configuration references: template_file and
+// transform_file of destinations, and the templates directory with its files
+func (c *Config) WatchedFiles() []string {
+	files := make([]string, 0)
+
+	for _, dest := range c.Destinations {
+		if dest.TemplateFile != "" {
+			files = append(files, dest.TemplateFile)
+		}
+		if dest.TransformFile != "" {
+			files = append(files, dest.TransformFile)
+		}
+	}
+
+	if dir := c.Templates.Directory; dir != "" {
+		files = append(files, dir)
+		if entries, err := os.ReadDir(dir); err == nil {
+			for _, entry := range entries {
+				if !entry.IsDir() {
+					files = append(files, filepath.Join(dir, entry.Name()))
+				}
 			}
 		}
+	}
+
+	return files
+}
+
+// CarryMetadata keeps the version and timestamps of destinations that are also in running.
+// A destination that changed gets the next version.
+func (c *Config) CarryMetadata(running []DestinationConfig, now time.Time) {
+	for i := range c.Destinations {
+		dest := &c.Destinations[i]
+
+		for _, current := range running {
+			if current.Name != dest.Name {
+				continue
+			}
 
-		// Default batch size for split alerts
-		if dest.SplitAlerts && dest.BatchSize == 0 {
-			dest.BatchSize = 1
+			if sameDestination(*dest, current) {
+				dest.Version, dest.CreatedAt, dest.UpdatedAt = current.Version, current.CreatedAt, current.UpdatedAt
+			} else {
+				dest.Version, dest.CreatedAt, dest.UpdatedAt = current.Version+1, current.CreatedAt, now
+			}
+			break
 		}
+	}
+}
 
-		// Default parallel requests
-		if dest.ParallelRequests == 0 {
-			dest.ParallelRequests = 1
+// sameDestination reports whether two destinations have the same configuration, ignoring metadata
+func sameDestination(a, b DestinationConfig) bool {
+	a.Version, a.CreatedAt, a.UpdatedAt = 0, time.Time{}, time.Time{}
+	b.Version, b.CreatedAt, b.UpdatedAt = 0, time.Time{}, time.Time{}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// initMetadata sets the version and timestamps of destinations loaded from files
+func (c *Config) initMetadata(now time.Time) {
+	for i := range c.Destinations {
+		dest := &c.Destinations[i]
+
+		if dest.Version == 0 {
+			dest.Version = 1
+		}
+		if dest.CreatedAt.IsZero() {
+			dest.CreatedAt = now
+		}
+		if dest.UpdatedAt.IsZero() {
+			dest.UpdatedAt = dest.CreatedAt
 		}
 	}
 }
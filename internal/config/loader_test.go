@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -180,3 +181,299 @@ server:
 	_, err = LoadConfig(configPath)
 	assert.Error(t, err)
 }
+
+func TestLoadConfig_Listeners(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+
+	t.Run("unix socket only", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(configPath, []byte(`
+server:
+  listeners:
+    - type: unix
+      address: /run/gateway/gateway.sock
+destinations:
+  - name: chat
+    url: https://chat.example.com/hook
+    template: '{"text": "{{ .Status }}"}'
+`), 0o600))
+
+		cfg, err := LoadConfig(configPath)
+		require.NoError(t, err)
+		assert.Empty(t, cfg.Server.Address)
+		require.Len(t, cfg.Server.Listeners, 1)
+		assert.Equal(t, "0660", cfg.Server.Listeners[0].SocketMode)
+	})
+
+	t.Run("default address", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(configPath, []byte(`
+destinations:
+  - name: chat
+    url: https://chat.example.com/hook
+    template: '{"text": "{{ .Status }}"}'
+`), 0o600))
+
+		cfg, err := LoadConfig(configPath)
+		require.NoError(t, err)
+		assert.Equal(t, ":8080", cfg.Server.Address)
+	})
+}
+
+func TestLoadConfig_Timeouts(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+
+	tests := []struct {
+		name   string
+		server string
+		dest   string
+		errMsg string
+	}{
+		{
+			name:   "within deadline",
+			server: "webhook_timeout: 20s",
+			dest:   "timeout: 10s\n    transform_timeout: 2s",
+		},
+		{
+			name:   "destination exceeds deadline",
+			server: "webhook_timeout: 10s",
+			dest:   "timeout: 10s\n    transform_timeout: 2s",
+			errMsg: "destination chat: timeout (10s) plus transform_timeout (2s) exceeds server webhook_timeout (10s)",
+		},
+		{
+			name:   "webhook deadline exceeds write timeout",
+			server: "webhook_timeout: 60s",
+			errMsg: "server webhook_timeout (1m0s) exceeds write_timeout (30s)",
+		},
+		{
+			name:   "longer write timeout",
+			server: "webhook_timeout: 60s\n  write_timeout: 90s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, os.WriteFile(configPath, []byte(fmt.Sprintf(`
+server:
+  %s
+destinations:
+  - name: chat
+    url: https://chat.example.com/hook
+    template: '{"text": "{{ .Status }}"}'
+    %s
+`, tt.server, tt.dest)), 0o600))
+
+			_, err := LoadConfig(configPath)
+			if tt.errMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestLoadConfig_SourceFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(tmpDir, "templates"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "templates", "chat.tmpl"), []byte(`{"text": "{{ .Status }}"}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "pager.jq"), []byte(`{status: .status}`), 0o600))
+
+	write := func(content string) string {
+		configPath := filepath.Join(tmpDir, "config.yaml")
+		require.NoError(t, os.WriteFile(configPath, []byte(content), 0o600))
+		return configPath
+	}
+
+	t.Run("resolves files relative to the configuration", func(t *testing.T) {
+		cfg, err := LoadConfig(write(`
+destinations:
+  - name: chat
+    url: https://chat.example.com
+    template_file: templates/chat.tmpl
+  - name: pager
+    url: https://pager.example.com
+    transform_file: pager.jq
+`))
+		require.NoError(t, err)
+
+		chat := cfg.GetDestinationByName("chat")
+		require.NotNil(t, chat)
+		assert.Equal(t, "go-template", chat.Engine)
+		assert.Equal(t, `{"text": "{{ .Status }}"}`, chat.Template)
+		assert.Equal(t, filepath.Join(tmpDir, "templates", "chat.tmpl"), chat.TemplateFile)
+
+		pager := cfg.GetDestinationByName("pager")
+		require.NotNil(t, pager)
+		assert.Equal(t, "jq", pager.Engine)
+		assert.Equal(t, `{status: .status}`, pager.Transform)
+
+		assert.Equal(t, []string{chat.TemplateFile, pager.TransformFile}, cfg.WatchedFiles())
+	})
+
+	t.Run("inline and file are mutually exclusive", func(t *testing.T) {
+		_, err := LoadConfig(write(`
+destinations:
+  - name: chat
+    url: https://chat.example.com
+    template: "{}"
+    template_file: templates/chat.tmpl
+`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "template and template_file are mutually exclusive")
+	})
+
+	t.Run("cel expressions are type-checked", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "pager.cel"), []byte("{\n  \"name\": alert.lables.alertname\n}"), 0o600))
+
+		_, err := LoadConfig(write(`
+destinations:
+  - name: pager
+    url: https://pager.example.com
+    engine: cel
+    transform_file: pager.cel
+`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "destination pager: "+filepath.Join(tmpDir, "pager.cel")+":2: ")
+		assert.Contains(t, err.Error(), "undefined field 'lables'")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := LoadConfig(write(`
+destinations:
+  - name: chat
+    url: https://chat.example.com
+    transform_file: missing.jq
+`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "destination chat: failed to read transform_file")
+	})
+}
+
+func TestResolveSourceFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	root := filepath.Join(tmpDir, "sources")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "chat"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "chat", "alerts.tmpl"), []byte(`{}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "secret.txt"), []byte("secret"), 0o600))
+	require.NoError(t, os.Symlink(filepath.Join(tmpDir, "secret.txt"), filepath.Join(root, "secret.tmpl")))
+	require.NoError(t, os.Symlink(filepath.Join(root, "chat", "alerts.tmpl"), filepath.Join(root, "alerts.tmpl")))
+
+	tests := []struct {
+		name    string
+		file    string
+		want    string
+		wantErr string
+	}{
+		{name: "relative path", file: "chat/alerts.tmpl", want: filepath.Join(root, "chat", "alerts.tmpl")},
+		{name: "symlink inside the directory", file: "alerts.tmpl", want: filepath.Join(root, "alerts.tmpl")},
+		{name: "parent directory", file: "../secret.txt", wantErr: "is outside of"},
+		{name: "parent directory after a subdirectory", file: "chat/../../secret.txt", wantErr: "is outside of"},
+		{name: "absolute path", file: filepath.Join(tmpDir, "secret.txt"), wantErr: "absolute path"},
+		{name: "absolute path inside the directory", file: filepath.Join(root, "chat", "alerts.tmpl"), wantErr: "absolute path"},
+		{name: "symlink leaving the directory", file: "secret.tmpl", wantErr: "links outside of"},
+		{name: "missing file", file: "missing.tmpl", wantErr: "no such file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := ResolveSourceFile(root, tt.file)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, path)
+		})
+	}
+}
+
+func TestLoadConfig_RequestTemplates(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+
+	t.Run("templates and allowed hosts", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(configPath, []byte(`
+destinations:
+  - name: telegram
+    url: https://api.telegram.org/bot123/sendMessage
+    url_template: 'https://api.telegram.org/bot123/sendMessage?chat_id={{ .CommonLabels.chat_id }}'
+    method_template: '{{ if eq .Status "resolved" }}PUT{{ end }}'
+    headers_template:
+      X-Priority: '{{ .CommonLabels.severity }}'
+    allowed_hosts: ["*.telegram.org"]
+    template: '{"text": "{{ .Status }}"}'
+`), 0o600))
+
+		cfg, err := LoadConfig(configPath)
+		require.NoError(t, err)
+
+		dest := cfg.Destinations[0]
+		assert.Contains(t, dest.URLTemplate, "chat_id=")
+		assert.NotEmpty(t, dest.MethodTemplate)
+		assert.Equal(t, "{{ .CommonLabels.severity }}", dest.HeadersTemplate["X-Priority"])
+		assert.Equal(t, []string{"*.telegram.org"}, dest.AllowedHosts)
+	})
+
+	t.Run("invalid allowed host", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(configPath, []byte(`
+destinations:
+  - name: telegram
+    url: https://api.telegram.org/bot123/sendMessage
+    allowed_hosts: ["https://api.telegram.org/"]
+    template: '{"text": "{{ .Status }}"}'
+`), 0o600))
+
+		_, err := LoadConfig(configPath)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `destination telegram: invalid allowed host "https://api.telegram.org/"`)
+	})
+
+	t.Run("output mode", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(configPath, []byte(`
+destinations:
+  - name: tickets
+    url: https://jira.example.com/rest/api/2/issue
+    output_mode: requests
+    engine: jq
+    transform: '[{body: {summary: .status}}]'
+  - name: chat
+    url: https://chat.example.com/hook
+    template: '{"text": "{{ .Status }}"}'
+`), 0o600))
+
+		cfg, err := LoadConfig(configPath)
+		require.NoError(t, err)
+		assert.Equal(t, OutputModeRequests, cfg.Destinations[0].OutputMode)
+		assert.Equal(t, OutputModeBody, cfg.Destinations[1].OutputMode)
+	})
+
+	t.Run("invalid output mode", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(configPath, []byte(`
+destinations:
+  - name: tickets
+    url: https://jira.example.com/rest/api/2/issue
+    output_mode: calls
+    template: '{"text": "{{ .Status }}"}'
+`), 0o600))
+
+		_, err := LoadConfig(configPath)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "destination tickets: invalid output_mode calls")
+	})
+
+	t.Run("filter expression", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(configPath, []byte(`
+destinations:
+  - name: pager
+    url: https://pager.example.com/hook
+    template: '{"text": "{{ .Status }}"}'
+    filter:
+      expression: 'alert.lables["severity"] == "critical"'
+`), 0o600))
+
+		_, err := LoadConfig(configPath)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "destination pager: invalid filter: expression")
+	})
+}
@@ -0,0 +1,176 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Overlay holds destination changes made through the management API.
+// It is merged over the configuration file on startup.
+type Overlay struct {
+	Destinations []OverlayDestination `yaml:"destinations"`
+	Deleted      []string             `yaml:"deleted,omitempty"`
+}
+
+// OverlayDestination is a destination with its runtime metadata
+type OverlayDestination struct {
+	DestinationConfig `yaml:",inline"`
+
+	Version   int       `yaml:"version"`
+	CreatedAt time.Time `yaml:"created_at"`
+	UpdatedAt time.Time `yaml:"updated_at"`
+}
+
+// LoadOverlay reads an overlay file, returning an empty overlay if it does not exist
+func LoadOverlay(path string) (*Overlay, error) {
+	overlay := &Overlay{}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return overlay, nil
+		}
+		return nil, fmt.Errorf("failed to read overlay file: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, overlay); err != nil {
+		return nil, fmt.Errorf("failed to parse overlay file: %w", err)
+	}
+
+	return overlay, nil
+}
+
+// SaveOverlay atomically writes an overlay file
+func SaveOverlay(path string, overlay *Overlay) error {
+	data, err := yaml.Marshal(overlay)
+	if err != nil {
+		return fmt.Errorf("failed to marshal overlay: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".overlay-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create overlay file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write overlay file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write overlay file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write overlay file: %w", err)
+	}
+
+	return nil
+}
+
+// ApplyOverlay replaces, adds and removes destinations according to the overlay
+func (c *Config) ApplyOverlay(overlay *Overlay) {
+	deleted := make(map[string]bool, len(overlay.Deleted))
+	for _, name := range overlay.Deleted {
+		deleted[name] = true
+	}
+
+	destinations := make([]DestinationConfig, 0, len(c.Destinations)+len(overlay.Destinations))
+	for _, dest := range c.Destinations {
+		if !deleted[dest.Name] {
+			destinations = append(destinations, dest)
+		}
+	}
+
+	for _, entry := range overlay.Destinations {
+		dest := entry.Destination()
+
+		replaced := false
+		for i := range destinations {
+			if destinations[i].Name == dest.Name {
+				destinations[i] = dest
+				replaced = true
+				break
+			}
+		}
+
+		if !replaced {
+			destinations = append(destinations, dest)
+		}
+	}
+
+	c.Destinations = destinations
+}
+
+// Set adds or replaces the overlay entry for a destination. Content loaded from
+// template_file or transform_file is left out, so the file is read again on startup.
+func (o *Overlay) Set(dest DestinationConfig) {
+	dest.ClearFileContent()
+
+	entry := OverlayDestination{
+		DestinationConfig: dest,
+		Version:           dest.Version,
+		CreatedAt:         dest.CreatedAt,
+		UpdatedAt:         dest.UpdatedAt,
+	}
+
+	o.Deleted = removeString(o.Deleted, dest.Name)
+
+	for i := range o.Destinations {
+		if o.Destinations[i].Name == dest.Name {
+			o.Destinations[i] = entry
+			return
+		}
+	}
+
+	o.Destinations = append(o.Destinations, entry)
+}
+
+// Delete removes the overlay entry for a destination and marks it as deleted
+func (o *Overlay) Delete(name string) {
+	destinations := o.Destinations[:0]
+	for _, entry := range o.Destinations {
+		if entry.Name != name {
+			destinations = append(destinations, entry)
+		}
+	}
+	o.Destinations = destinations
+
+	o.Deleted = append(removeString(o.Deleted, name), name)
+}
+
+// Clone returns a copy of the overlay that can be modified independently
+func (o *Overlay) Clone() *Overlay {
+	return &Overlay{
+		Destinations: append([]OverlayDestination(nil), o.Destinations...),
+		Deleted:      append([]string(nil), o.Deleted...),
+	}
+}
+
+// Destination returns the destination configuration with its metadata
+func (e *OverlayDestination) Destination() DestinationConfig {
+	dest := e.DestinationConfig
+	// Entries saved before file content was left out carry both the file and its content
+	dest.ClearFileContent()
+	dest.Version = e.Version
+	dest.CreatedAt = e.CreatedAt
+	dest.UpdatedAt = e.UpdatedAt
+
+	return dest
+}
+
+func removeString(values []string, value string) []string {
+	result := values[:0]
+	for _, v := range values {
+		if v != value {
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverlay_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "overlay.yaml")
+
+	overlay, err := LoadOverlay(path)
+	require.NoError(t, err)
+	assert.Empty(t, overlay.Destinations)
+
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	overlay.Set(DestinationConfig{
+		Name:           "api-dest",
+		URL:            "https://example.com",
+		Template:       "{}",
+		RepeatInterval: time.Hour,
+		Version:        2,
+		CreatedAt:      created,
+		UpdatedAt:      created.Add(time.Hour),
+	})
+	overlay.Delete("removed")
+	require.NoError(t, SaveOverlay(path, overlay))
+
+	loaded, err := LoadOverlay(path)
+	require.NoError(t, err)
+	require.Len(t, loaded.Destinations, 1)
+	assert.Equal(t, []string{"removed"}, loaded.Deleted)
+
+	dest := loaded.Destinations[0].Destination()
+	assert.Equal(t, "api-dest", dest.Name)
+	assert.Equal(t, time.Hour, dest.RepeatInterval)
+	assert.Equal(t, 2, dest.Version)
+	assert.Equal(t, created, dest.CreatedAt)
+	assert.Equal(t, created.Add(time.Hour), dest.UpdatedAt)
+}
+
+func TestOverlay_SetClearsDeleted(t *testing.T) {
+	overlay := &Overlay{}
+	overlay.Delete("dest")
+	overlay.Set(DestinationConfig{Name: "dest"})
+	assert.Empty(t, overlay.Deleted)
+	assert.Len(t, overlay.Destinations, 1)
+
+	clone := overlay.Clone()
+	clone.Delete("dest")
+	assert.Len(t, overlay.Destinations, 1)
+	assert.Empty(t, clone.Destinations)
+}
+
+func TestConfig_ApplyOverlay(t *testing.T) {
+	cfg := &Config{
+		Destinations: []DestinationConfig{
+			{Name: "keep", URL: "https://keep.example.com"},
+			{Name: "replace", URL: "https://old.example.com"},
+			{Name: "remove", URL: "https://remove.example.com"},
+		},
+	}
+
+	overlay := &Overlay{Deleted: []string{"remove"}}
+	overlay.Set(DestinationConfig{Name: "replace", URL: "https://new.example.com", Version: 3})
+	overlay.Set(DestinationConfig{Name: "added", URL: "https://added.example.com", Version: 1})
+
+	cfg.ApplyOverlay(overlay)
+
+	require.Len(t, cfg.Destinations, 3)
+	assert.Equal(t, "keep", cfg.Destinations[0].Name)
+	assert.Equal(t, "https://new.example.com", cfg.Destinations[1].URL)
+	assert.Equal(t, 3, cfg.Destinations[1].Version)
+	assert.Equal(t, "added", cfg.Destinations[2].Name)
+	assert.Nil(t, cfg.GetDestinationByNameAny("remove"))
+}
+
+func TestLoadConfig_WithOverlay(t *testing.T) {
+	dir := t.TempDir()
+	overlayPath := filepath.Join(dir, "overlay.yaml")
+
+	overlay := &Overlay{}
+	overlay.Set(DestinationConfig{
+		Name:     "from-api",
+		URL:      "https://api.example.com",
+		Method:   "POST",
+		Format:   "json",
+		Engine:   "go-template",
+		Template: "{}",
+		Enabled:  false,
+		Version:  4,
+	})
+	require.NoError(t, SaveOverlay(overlayPath, overlay))
+
+	configPath := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+server:
+  auth:
+    enabled: true
+    username: admin
+    password: secret
+management:
+  enabled: true
+  overlay_file: `+overlayPath+`
+destinations:
+  - name: from-file
+    url: https://file.example.com
+    template: "{}"
+`), 0o600))
+
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	require.Len(t, cfg.Destinations, 2)
+
+	fromFile := cfg.GetDestinationByNameAny("from-file")
+	require.NotNil(t, fromFile)
+	assert.Equal(t, 1, fromFile.Version)
+	assert.False(t, fromFile.CreatedAt.IsZero())
+
+	fromAPI := cfg.GetDestinationByNameAny("from-api")
+	require.NotNil(t, fromAPI)
+	assert.False(t, fromAPI.Enabled)
+	assert.Equal(t, 4, fromAPI.Version)
+}
@@ -1,11 +1,56 @@
 package config
 
-import "time"
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/vitalvas/alertmanager-gateway/internal/timeinterval"
+)
+
+// Behaviours for alerts received outside a destination's active time intervals
+const (
+	OutsideActiveDrop     = "drop"
+	OutsideActiveQueue    = "queue"
+	OutsideActiveRedirect = "redirect"
+)
+
+// Ways a destination uses the result of its transformation
+const (
+	OutputModeBody     = "body"     // the result is the body of one request
+	OutputModeRequests = "requests" // the result is a list of requests, each with its own body
+)
+
+// Behaviours for alerts received while a destination is paused
+const (
+	PauseModeDrop   = "drop"
+	PauseModeBuffer = "buffer"
+	PauseModeReject = "reject"
+)
 
 // Config represents the main configuration structure
 type Config struct {
-	Server       ServerConfig        `yaml:"server"`
-	Destinations []DestinationConfig `yaml:"destinations"`
+	Server        ServerConfig         `yaml:"server"`
+	AlertsAPI     AlertsAPIConfig      `yaml:"alerts_api"`
+	State         StateConfig          `yaml:"state"`
+	History       HistoryConfig        `yaml:"history"`
+	Audit         AuditConfig          `yaml:"audit"`
+	Tracing       TracingConfig        `yaml:"tracing"`
+	Health        HealthConfig         `yaml:"health"`
+	DeadLetter    DeadLetterConfig     `yaml:"dead_letter"`
+	Templates     TemplatesConfig      `yaml:"templates"`
+	TimeIntervals []TimeIntervalConfig `yaml:"time_intervals"`
+	Management    ManagementConfig     `yaml:"management"`
+	Destinations  []DestinationConfig  `yaml:"destinations"`
+
+	// path is the file the configuration was loaded from
+	path string
+}
+
+// Path returns the file the configuration was loaded from, empty if it was built in code
+func (c *Config) Path() string {
+	return c.path
 }
 
 // ServerConfig represents server configuration
@@ -14,6 +59,69 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration `yaml:"read_timeout"`
 	WriteTimeout time.Duration `yaml:"write_timeout"`
 	Auth         AuthConfig    `yaml:"auth"`
+
+	// Header carrying the request id on requests sent to destinations
+	RequestIDHeader string `yaml:"request_id_header"`
+
+	// Deadline for delivering a received webhook or Alertmanager API request
+	WebhookTimeout time.Duration `yaml:"webhook_timeout"`
+
+	// How often template files are checked for changes that trigger a reload
+	WatchInterval time.Duration `yaml:"watch_interval"`
+
+	// Additional listeners serving the same endpoints as Address
+	Listeners []ListenerConfig `yaml:"listeners"`
+
+	// Graceful shutdown: how long readiness fails before the listeners close, and how long
+	// in-flight deliveries may take to finish
+	DrainDelay   time.Duration `yaml:"drain_delay"`
+	DrainTimeout time.Duration `yaml:"drain_timeout"`
+
+	// Optional internal listener for the management API, metrics and pprof
+	AdminAddress string          `yaml:"admin_address"`
+	AdminAuth    AdminAuthConfig `yaml:"admin_auth"`
+}
+
+// Listener types
+const (
+	ListenerTypeTCP     = "tcp"
+	ListenerTypeUnix    = "unix"
+	ListenerTypeSystemd = "systemd"
+)
+
+// ListenerConfig represents an additional listener of the main server
+type ListenerConfig struct {
+	// Type is tcp, unix or systemd
+	Type string `yaml:"type"`
+
+	// Address is host:port for tcp, the socket path for unix and an optional
+	// LISTEN_FDNAMES name for systemd (empty uses every inherited socket)
+	Address string `yaml:"address"`
+
+	// SocketMode is the octal file mode of a unix socket, e.g. "0660"
+	SocketMode string `yaml:"socket_mode"`
+}
+
+// FileMode parses the unix socket file mode
+func (l *ListenerConfig) FileMode() (os.FileMode, error) {
+	mode, err := strconv.ParseUint(l.SocketMode, 8, 32)
+	if err != nil || mode > 0o777 {
+		return 0, fmt.Errorf("invalid socket mode %q", l.SocketMode)
+	}
+
+	return os.FileMode(mode), nil
+}
+
+// AdminAuthConfig represents authentication for the admin listener
+type AdminAuthConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// HasAdminListener reports whether management endpoints are served on a separate listener
+func (c *ServerConfig) HasAdminListener() bool {
+	return c.AdminAddress != ""
 }
 
 // AuthConfig represents authentication configuration
@@ -25,6 +133,25 @@ type AuthConfig struct {
 	APIPassword string `yaml:"api_password"`
 }
 
+// AlertsAPIConfig represents the Alertmanager API v2 compatible ingestion configuration
+type AlertsAPIConfig struct {
+	Enabled      bool     `yaml:"enabled"`
+	Destinations []string `yaml:"destinations"`
+	GroupBy      []string `yaml:"group_by"`
+	Receiver     string   `yaml:"receiver"`
+	ExternalURL  string   `yaml:"external_url"`
+}
+
+// ManagementConfig represents runtime destination management settings
+type ManagementConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	OverlayFile string `yaml:"overlay_file"`
+
+	// Directory the template_file and transform_file of API requests are read from;
+	// unset rejects these fields in API requests
+	SourceDirectory string `yaml:"source_directory"`
+}
+
 // DestinationConfig represents a single destination configuration
 type DestinationConfig struct {
 	Name             string            `yaml:"name"`
@@ -40,6 +167,159 @@ type DestinationConfig struct {
 	BatchSize        int               `yaml:"batch_size"`
 	ParallelRequests int               `yaml:"parallel_requests"`
 	Enabled          bool              `yaml:"enabled"`
+
+	// Go templates rendering the URL, method and headers of each request from the payload
+	// or alert being sent. Rendered URLs must point at the host of URL or an allowed host.
+	URLTemplate     string            `yaml:"url_template"`
+	MethodTemplate  string            `yaml:"method_template"`
+	HeadersTemplate map[string]string `yaml:"headers_template"`
+	AllowedHosts    []string          `yaml:"allowed_hosts"`
+
+	// How the transformation result is sent: one body, or a list of requests
+	OutputMode string `yaml:"output_mode"`
+
+	// Files holding the template or transform, relative to the configuration file. Their
+	// content is loaded into Template and Transform.
+	TemplateFile  string `yaml:"template_file"`
+	TransformFile string `yaml:"transform_file"`
+
+	// Timeouts of each HTTP request and of each template or jq execution
+	Timeout          time.Duration `yaml:"timeout"`
+	TransformTimeout time.Duration `yaml:"transform_timeout"`
+
+	// Alert state tracking (requires state.enabled)
+	SendResolved   *bool               `yaml:"send_resolved"`
+	RepeatInterval time.Duration       `yaml:"repeat_interval"`
+	OnlyOnChange   bool                `yaml:"only_on_change"`
+	FlapDetection  FlapDetectionConfig `yaml:"flap_detection"`
+
+	// Time-window digest delivery
+	Digest DigestConfig `yaml:"digest"`
+
+	// Alert filtering before transformation
+	Filter FilterConfig `yaml:"filter"`
+
+	// Time-based activation
+	ActiveTimeIntervals []string `yaml:"active_time_intervals"`
+	MuteTimeIntervals   []string `yaml:"mute_time_intervals"`
+	OutsideActive       string   `yaml:"outside_active"`
+	FallbackDestination string   `yaml:"fallback_destination"`
+
+	// Behaviour while paused through the API
+	Pause PauseConfig `yaml:"pause"`
+
+	// Optional active reachability check
+	HealthCheck HealthCheckConfig `yaml:"health_check"`
+
+	// Runtime metadata, not read from the configuration file
+	Version   int       `yaml:"-"`
+	CreatedAt time.Time `yaml:"-"`
+	UpdatedAt time.Time `yaml:"-"`
+}
+
+// PauseConfig represents how a paused destination handles incoming alerts
+type PauseConfig struct {
+	Mode       string `yaml:"mode"`
+	BufferSize int    `yaml:"buffer_size"`
+}
+
+// HealthCheckConfig represents an active reachability check of a destination
+type HealthCheckConfig struct {
+	URL      string        `yaml:"url"`
+	Method   string        `yaml:"method"`
+	Interval time.Duration `yaml:"interval"`
+	Timeout  time.Duration `yaml:"timeout"`
+}
+
+// HealthConfig represents the readiness thresholds of the gateway
+type HealthConfig struct {
+	// Payloads held for later delivery above which the gateway is not ready; 0 disables the check
+	MaxQueueBacklog int `yaml:"max_queue_backlog"`
+
+	// Consecutive delivery failures after which a destination counts as failing; 0 disables the check
+	FailureThreshold int `yaml:"failure_threshold"`
+
+	// Whether a failing destination makes the gateway not ready; otherwise it is only reported
+	DestinationReadiness bool `yaml:"destination_readiness"`
+}
+
+// TimeIntervalConfig represents a named set of time intervals
+type TimeIntervalConfig struct {
+	Name          string              `yaml:"name"`
+	TimeIntervals []timeinterval.Spec `yaml:"time_intervals"`
+}
+
+// StateConfig represents the alert lifecycle state store configuration
+type StateConfig struct {
+	Enabled          bool          `yaml:"enabled"`
+	SnapshotFile     string        `yaml:"snapshot_file"`
+	SnapshotInterval time.Duration `yaml:"snapshot_interval"`
+	Retention        time.Duration `yaml:"retention"`
+}
+
+// HistoryConfig represents the delivery history configuration
+type HistoryConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Size    int    `yaml:"size"`
+	File    string `yaml:"file"`
+}
+
+// TemplatesConfig represents Go templates and jq modules shared by all destinations
+type TemplatesConfig struct {
+	Directory string            `yaml:"directory"` // *.tmpl and *.jq files, named after the file
+	Go        map[string]string `yaml:"go"`
+	JQ        map[string]string `yaml:"jq"`
+
+	// Compiled engines shared by destinations and the API; read at startup
+	CacheSize int           `yaml:"cache_size"`
+	CacheTTL  time.Duration `yaml:"cache_ttl"`
+}
+
+// DeadLetterConfig represents the store of payloads left undelivered at shutdown
+type DeadLetterConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	File    string `yaml:"file"`
+}
+
+// AuditConfig represents the audit log configuration
+type AuditConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	File    string `yaml:"file"`
+}
+
+// TracingConfig represents the OpenTelemetry tracing configuration
+type TracingConfig struct {
+	Enabled     bool              `yaml:"enabled"`
+	Exporter    string            `yaml:"exporter"`
+	Endpoint    string            `yaml:"endpoint"`
+	Headers     map[string]string `yaml:"headers"`
+	ServiceName string            `yaml:"service_name"`
+	SampleRatio float64           `yaml:"sample_ratio"`
+}
+
+// FlapDetectionConfig represents flapping alert suppression settings
+type FlapDetectionConfig struct {
+	Transitions int           `yaml:"transitions"`
+	Window      time.Duration `yaml:"window"`
+}
+
+// DigestConfig represents time-window digest delivery settings
+type DigestConfig struct {
+	Window    time.Duration `yaml:"window"`
+	Template  string        `yaml:"template"`
+	MaxAlerts int           `yaml:"max_alerts"`
+}
+
+// FilterConfig represents alert matchers and a CEL expression applied before transformation
+type FilterConfig struct {
+	Include    []string `yaml:"include"`
+	Exclude    []string `yaml:"exclude"`
+	Expression string   `yaml:"expression"`
+}
+
+// SendResolvedEnabled reports whether resolved notifications should be sent (default true)
+func (d *DestinationConfig) SendResolvedEnabled() bool {
+	return d.SendResolved == nil || *d.SendResolved
 }
 
 // GetDestinationByName returns a destination configuration by name (only enabled destinations)
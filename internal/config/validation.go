@@ -3,13 +3,37 @@ package config
 import (
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/vitalvas/alertmanager-gateway/internal/filter"
+	"github.com/vitalvas/alertmanager-gateway/internal/timeinterval"
+	"github.com/vitalvas/alertmanager-gateway/internal/transform"
 )
 
 // Validate validates the configuration
 func (c *Config) Validate() error {
 	// Validate server config
-	if c.Server.Address == "" {
-		return fmt.Errorf("server address is required")
+	if c.Server.Address == "" && len(c.Server.Listeners) == 0 {
+		return fmt.Errorf("server address or listeners are required")
+	}
+
+	for i, listener := range c.Server.Listeners {
+		switch listener.Type {
+		case ListenerTypeTCP, ListenerTypeUnix:
+			if listener.Address == "" {
+				return fmt.Errorf("listener %d: address is required for %s listeners", i, listener.Type)
+			}
+		case ListenerTypeSystemd:
+		default:
+			return fmt.Errorf("listener %d: invalid type %s", i, listener.Type)
+		}
+
+		if listener.Type == ListenerTypeUnix {
+			if _, err := listener.FileMode(); err != nil {
+				return fmt.Errorf("listener %d: %w", i, err)
+			}
+		}
 	}
 
 	if c.Server.Auth.Enabled {
@@ -18,11 +42,48 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	if c.Server.HasAdminListener() {
+		if c.Server.AdminAddress == c.Server.Address {
+			return fmt.Errorf("server admin_address must differ from address")
+		}
+
+		if c.Server.AdminAuth.Enabled && (c.Server.AdminAuth.Username == "" || c.Server.AdminAuth.Password == "") {
+			return fmt.Errorf("admin auth enabled but username or password not provided")
+		}
+	}
+
+	// The server must not cut off the response of a webhook that is still within its deadline
+	if c.Server.WriteTimeout > 0 && c.Server.WebhookTimeout > c.Server.WriteTimeout {
+		return fmt.Errorf("server webhook_timeout (%s) exceeds write_timeout (%s)", c.Server.WebhookTimeout, c.Server.WriteTimeout)
+	}
+
+	adminAuth := c.Server.HasAdminListener() && c.Server.AdminAuth.Enabled
+	if c.Management.Enabled && !c.Server.Auth.Enabled && !adminAuth {
+		return fmt.Errorf("management requires server auth or admin auth to be enabled")
+	}
+
 	// Validate destinations
 	if len(c.Destinations) == 0 {
 		return fmt.Errorf("no destinations configured")
 	}
 
+	intervalNames := make(map[string]bool)
+
+	for i, interval := range c.TimeIntervals {
+		if interval.Name == "" {
+			return fmt.Errorf("time interval %d: name is required", i)
+		}
+
+		if intervalNames[interval.Name] {
+			return fmt.Errorf("duplicate time interval name: %s", interval.Name)
+		}
+		intervalNames[interval.Name] = true
+
+		if _, err := timeinterval.CompileSet(interval.TimeIntervals); err != nil {
+			return fmt.Errorf("time interval %s: %w", interval.Name, err)
+		}
+	}
+
 	destNames := make(map[string]bool)
 
 	for i, dest := range c.Destinations {
@@ -55,12 +116,18 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("destination %s: invalid method %s", dest.Name, dest.Method)
 		}
 
+		for _, host := range dest.AllowedHosts {
+			if host == "" || strings.ContainsAny(host, "/?#@") {
+				return fmt.Errorf("destination %s: invalid allowed host %q (use a host name or *.domain)", dest.Name, host)
+			}
+		}
+
 		validFormats := map[string]bool{"json": true, "form": true, "query": true}
 		if !validFormats[dest.Format] {
 			return fmt.Errorf("destination %s: invalid format %s", dest.Name, dest.Format)
 		}
 
-		validEngines := map[string]bool{"go-template": true, "jq": true}
+		validEngines := map[string]bool{"go-template": true, "jq": true, "cel": true, "starlark": true}
 		if !validEngines[dest.Engine] {
 			return fmt.Errorf("destination %s: invalid engine %s", dest.Name, dest.Engine)
 		}
@@ -73,6 +140,186 @@ func (c *Config) Validate() error {
 		if dest.Engine == "jq" && dest.Transform == "" && dest.Template == "" {
 			return fmt.Errorf("destination %s: transform is required for jq engine", dest.Name)
 		}
+
+		if dest.Engine == "starlark" && dest.Transform == "" {
+			return fmt.Errorf("destination %s: transform is required for starlark engine", dest.Name)
+		}
+
+		if dest.Engine == "cel" {
+			if err := validateCEL(dest); err != nil {
+				return fmt.Errorf("destination %s: %w", dest.Name, err)
+			}
+		}
+
+		if dest.RepeatInterval < 0 {
+			return fmt.Errorf("destination %s: repeat_interval cannot be negative", dest.Name)
+		}
+
+		if dest.FlapDetection.Transitions < 0 || dest.FlapDetection.Window < 0 {
+			return fmt.Errorf("destination %s: flap_detection values cannot be negative", dest.Name)
+		}
+
+		if dest.FlapDetection.Transitions > 0 && dest.FlapDetection.Window == 0 {
+			return fmt.Errorf("destination %s: flap_detection window is required when transitions is set", dest.Name)
+		}
+
+		if dest.Digest.Window < 0 || dest.Digest.MaxAlerts < 0 {
+			return fmt.Errorf("destination %s: digest values cannot be negative", dest.Name)
+		}
+
+		if dest.Digest.Window > 0 && dest.SplitAlerts {
+			return fmt.Errorf("destination %s: digest cannot be combined with split_alerts", dest.Name)
+		}
+
+		if _, err := filter.New(dest.Filter.Include, dest.Filter.Exclude, dest.Filter.Expression); err != nil {
+			return fmt.Errorf("destination %s: invalid filter: %w", dest.Name, err)
+		}
+
+		for _, name := range append(append([]string{}, dest.ActiveTimeIntervals...), dest.MuteTimeIntervals...) {
+			if !intervalNames[name] {
+				return fmt.Errorf("destination %s: unknown time interval %s", dest.Name, name)
+			}
+		}
+
+		switch dest.OutsideActive {
+		case "", OutsideActiveDrop, OutsideActiveQueue:
+		case OutsideActiveRedirect:
+			if dest.FallbackDestination == "" {
+				return fmt.Errorf("destination %s: fallback_destination is required when outside_active is redirect", dest.Name)
+			}
+			if dest.FallbackDestination == dest.Name {
+				return fmt.Errorf("destination %s: fallback_destination cannot reference itself", dest.Name)
+			}
+		default:
+			return fmt.Errorf("destination %s: invalid outside_active %s", dest.Name, dest.OutsideActive)
+		}
+
+		switch dest.OutputMode {
+		case "", OutputModeBody, OutputModeRequests:
+		default:
+			return fmt.Errorf("destination %s: invalid output_mode %s", dest.Name, dest.OutputMode)
+		}
+
+		switch dest.Pause.Mode {
+		case "", PauseModeDrop, PauseModeBuffer, PauseModeReject:
+		default:
+			return fmt.Errorf("destination %s: invalid pause mode %s", dest.Name, dest.Pause.Mode)
+		}
+
+		if dest.Pause.BufferSize < 0 {
+			return fmt.Errorf("destination %s: pause buffer_size cannot be negative", dest.Name)
+		}
+
+		if dest.Timeout < 0 || dest.TransformTimeout < 0 {
+			return fmt.Errorf("destination %s: timeout and transform_timeout cannot be negative", dest.Name)
+		}
+
+		// A single request and its transformation must fit within the webhook deadline. Split
+		// sends and requests output mode make a varying number of requests, which share it.
+		if deadline := c.Server.WebhookTimeout; deadline > 0 && dest.Timeout+dest.TransformTimeout > deadline {
+			return fmt.Errorf("destination %s: timeout (%s) plus transform_timeout (%s) exceeds server webhook_timeout (%s)",
+				dest.Name, dest.Timeout, dest.TransformTimeout, deadline)
+		}
+
+		if dest.HealthCheck.URL != "" {
+			if _, err := url.ParseRequestURI(dest.HealthCheck.URL); err != nil {
+				return fmt.Errorf("destination %s: invalid health_check url: %w", dest.Name, err)
+			}
+			switch dest.HealthCheck.Method {
+			case "", http.MethodHead, http.MethodGet:
+			default:
+				return fmt.Errorf("destination %s: health_check method must be HEAD or GET", dest.Name)
+			}
+			if dest.HealthCheck.Interval < 0 || dest.HealthCheck.Timeout < 0 {
+				return fmt.Errorf("destination %s: health_check interval and timeout cannot be negative", dest.Name)
+			}
+		}
+	}
+
+	for _, dest := range c.Destinations {
+		if dest.FallbackDestination != "" && !destNames[dest.FallbackDestination] {
+			return fmt.Errorf("destination %s: unknown fallback_destination %s", dest.Name, dest.FallbackDestination)
+		}
+	}
+
+	if c.AlertsAPI.Enabled {
+		for _, name := range c.AlertsAPI.Destinations {
+			if !destNames[name] {
+				return fmt.Errorf("alerts_api: unknown destination %s", name)
+			}
+		}
+	}
+
+	if c.History.Size < 0 {
+		return fmt.Errorf("history: size cannot be negative")
+	}
+
+	if c.Tracing.Enabled {
+		switch c.Tracing.Exporter {
+		case "otlp", "stdout":
+		default:
+			return fmt.Errorf("tracing: invalid exporter %s", c.Tracing.Exporter)
+		}
+
+		if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+			return fmt.Errorf("tracing: sample_ratio must be between 0 and 1")
+		}
+	}
+
+	if c.Health.MaxQueueBacklog < 0 || c.Health.FailureThreshold < 0 {
+		return fmt.Errorf("health: max_queue_backlog and failure_threshold cannot be negative")
+	}
+
+	if c.Server.WebhookTimeout < 0 {
+		return fmt.Errorf("server webhook_timeout cannot be negative")
+	}
+
+	if c.Templates.CacheSize < 0 || c.Templates.CacheTTL < 0 {
+		return fmt.Errorf("templates: cache_size and cache_ttl cannot be negative")
+	}
+
+	if c.Server.WatchInterval < 0 {
+		return fmt.Errorf("server watch_interval cannot be negative")
+	}
+
+	if c.Server.DrainDelay < 0 || c.Server.DrainTimeout < 0 {
+		return fmt.Errorf("server drain_delay and drain_timeout cannot be negative")
+	}
+
+	if c.DeadLetter.Enabled && c.DeadLetter.File == "" {
+		return fmt.Errorf("dead_letter: file is required when the dead-letter store is enabled")
+	}
+
+	if c.Audit.Enabled && c.Audit.File == "" {
+		return fmt.Errorf("audit: file is required when audit logging is enabled")
+	}
+
+	return nil
+}
+
+// validateCEL type-checks the CEL expressions of a destination, so that typos in field
+// names fail at config load instead of on delivery
+func validateCEL(dest DestinationConfig) error {
+	if dest.Transform == "" {
+		return fmt.Errorf("transform is required for cel engine")
+	}
+
+	// CEL evaluations are bounded by a cost limit rather than a timeout
+	if dest.TransformTimeout > 0 {
+		return fmt.Errorf("transform_timeout is not supported by the cel engine")
+	}
+
+	if _, err := transform.NewCELEngine(dest.Transform); err != nil {
+		if dest.TransformFile != "" {
+			err = transform.NewSourceError(dest.TransformFile, dest.Transform, err)
+		}
+		return err
+	}
+
+	if dest.Digest.Template != "" {
+		if _, err := transform.NewCELEngine(dest.Digest.Template); err != nil {
+			return fmt.Errorf("digest: %w", err)
+		}
 	}
 
 	return nil
@@ -0,0 +1,97 @@
+package deadletter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+	"github.com/vitalvas/alertmanager-gateway/internal/requestid"
+)
+
+// Reasons a payload was not delivered
+const (
+	ReasonAbandoned = "abandoned" // still being delivered when the drain timeout elapsed
+	ReasonQueued    = "queued"    // held outside the destination's active time intervals
+	ReasonPaused    = "paused"    // buffered while the destination was paused
+	ReasonDigest    = "digest"    // pending in a digest that could not be sent on shutdown
+)
+
+// Entry is an undelivered payload
+type Entry struct {
+	Timestamp   time.Time                    `json:"timestamp"`
+	RequestID   string                       `json:"request_id,omitempty"`
+	Destination string                       `json:"destination"`
+	Reason      string                       `json:"reason"`
+	Payload     *alertmanager.WebhookPayload `json:"payload"`
+}
+
+// Store appends undelivered payloads to a JSON lines file. A nil Store discards entries.
+type Store struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// New opens the dead-letter file for appending
+func New(path string) (*Store, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dead-letter file: %w", err)
+	}
+
+	return &Store{file: file}, nil
+}
+
+// Add writes an entry, filling in the timestamp and the request id of the context
+func (s *Store) Add(ctx context.Context, entry Entry) error {
+	if s == nil {
+		return nil
+	}
+
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now().UTC()
+	}
+	if entry.RequestID == "" {
+		entry.RequestID = requestid.FromContext(ctx)
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead-letter entry: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return fmt.Errorf("dead-letter file is closed")
+	}
+
+	if _, err := s.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write dead-letter entry: %w", err)
+	}
+
+	return nil
+}
+
+// Close closes the dead-letter file
+func (s *Store) Close() error {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+
+	err := s.file.Close()
+	s.file = nil
+
+	return err
+}
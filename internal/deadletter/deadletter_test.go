@@ -0,0 +1,58 @@
+package deadletter
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+	"github.com/vitalvas/alertmanager-gateway/internal/requestid"
+)
+
+func TestStore_Add(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead-letter.jsonl")
+
+	store, err := New(path)
+	require.NoError(t, err)
+
+	payload := &alertmanager.WebhookPayload{GroupKey: "g", Alerts: []alertmanager.Alert{{Fingerprint: "abc"}}}
+
+	ctx := requestid.NewContext(context.Background(), "req-1")
+	require.NoError(t, store.Add(ctx, Entry{Destination: "chat", Reason: ReasonAbandoned, Payload: payload}))
+	require.NoError(t, store.Add(context.Background(), Entry{Destination: "pager", Reason: ReasonQueued, Payload: payload}))
+	require.NoError(t, store.Close())
+
+	assert.Error(t, store.Add(context.Background(), Entry{Destination: "chat"}), "closed store rejects entries")
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry Entry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+
+	require.Len(t, entries, 2)
+	assert.Equal(t, "req-1", entries[0].RequestID)
+	assert.Equal(t, ReasonAbandoned, entries[0].Reason)
+	assert.Equal(t, "abc", entries[0].Payload.Alerts[0].Fingerprint)
+	assert.False(t, entries[0].Timestamp.IsZero())
+	assert.Equal(t, "pager", entries[1].Destination)
+	assert.Empty(t, entries[1].RequestID)
+}
+
+func TestStore_Nil(t *testing.T) {
+	var store *Store
+
+	assert.NoError(t, store.Add(context.Background(), Entry{Destination: "chat"}))
+	assert.NoError(t, store.Close())
+}
@@ -8,6 +8,8 @@ import (
 	"net"
 	"net/http"
 	"time"
+
+	"github.com/vitalvas/alertmanager-gateway/internal/transform"
 )
 
 // HTTPClient is a configured HTTP client with connection pooling
@@ -41,6 +43,19 @@ type HTTPClientConfig struct {
 
 	// Skip TLS verification (not recommended for production)
 	InsecureSkipVerify bool
+
+	// Header set to the request id of the context on outbound requests, empty to disable
+	RequestIDHeader string
+
+	// Collector of per-destination request statistics, nil to disable
+	Stats *Stats
+
+	// Shared templates and jq modules available to transforms, nil for none
+	Library *transform.Library
+
+	// Deadline of deliveries the handler starts itself, such as scheduled digests;
+	// zero uses the default webhook deadline
+	DeliveryTimeout time.Duration
 }
 
 // DefaultHTTPClientConfig returns default HTTP client configuration
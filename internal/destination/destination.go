@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 	"time"
 
@@ -14,7 +15,11 @@ import (
 	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
 	"github.com/vitalvas/alertmanager-gateway/internal/config"
 	"github.com/vitalvas/alertmanager-gateway/internal/formatter"
+	"github.com/vitalvas/alertmanager-gateway/internal/requestid"
+	"github.com/vitalvas/alertmanager-gateway/internal/tracing"
 	"github.com/vitalvas/alertmanager-gateway/internal/transform"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
 // Handler handles sending alerts to a destination
@@ -36,40 +41,76 @@ type HTTPHandler struct {
 	engine   transform.Engine
 	logger   *logrus.Entry
 	splitter *AlertSplitter
+	request  *RequestTemplate
+
+	requestIDHeader string
+	stats           *Stats
+	library         *transform.Library
+	deliveryTimeout time.Duration
 }
 
+// defaultDeliveryTimeout bounds deliveries the handler starts itself when no deadline is configured
+const defaultDeliveryTimeout = 30 * time.Second
+
 // NewHTTPHandler creates a new HTTP destination handler
 func NewHTTPHandler(cfg *config.DestinationConfig, clientConfig *HTTPClientConfig) (*HTTPHandler, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("destination config is required")
 	}
 
+	if clientConfig == nil {
+		clientConfig = DefaultHTTPClientConfig()
+	}
+
 	// Create transform engine based on config
-	var engine transform.Engine
-	var err error
+	var engineType transform.EngineType
+	var source, file string
 
 	switch cfg.Engine {
 	case "go-template":
 		if cfg.Template == "" {
 			return nil, fmt.Errorf("template is required for go-template engine")
 		}
-		engine, err = transform.NewEngine(transform.EngineTypeGoTemplate, cfg.Template)
+		engineType, source, file = transform.EngineTypeGoTemplate, cfg.Template, cfg.TemplateFile
 	case "jq":
 		if cfg.Transform == "" {
 			return nil, fmt.Errorf("transform is required for jq engine")
 		}
-		engine, err = transform.NewEngine(transform.EngineTypeJQ, cfg.Transform)
+		engineType, source, file = transform.EngineTypeJQ, cfg.Transform, cfg.TransformFile
+	case "cel":
+		if cfg.Transform == "" {
+			return nil, fmt.Errorf("transform is required for cel engine")
+		}
+		engineType, source, file = transform.EngineTypeCEL, cfg.Transform, cfg.TransformFile
+	case "starlark":
+		if cfg.Transform == "" {
+			return nil, fmt.Errorf("transform is required for starlark engine")
+		}
+		engineType, source, file = transform.EngineTypeStarlark, cfg.Transform, cfg.TransformFile
 	default:
 		return nil, fmt.Errorf("unknown engine type: %s", cfg.Engine)
 	}
 
+	// Destinations with the same transformation share one compiled engine
+	engine, err := transform.GetTemplateCache().GetEngine(engineType, source, clientConfig.Library, cfg.TransformTimeout)
 	if err != nil {
+		// Point at the line of the file the template or transform was read from
+		if file != "" {
+			err = transform.NewSourceError(file, source, err)
+		}
 		return nil, fmt.Errorf("failed to create transform engine: %w", err)
 	}
 
+	request, err := NewRequestTemplate(cfg, clientConfig.Library)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create HTTP client
-	if clientConfig == nil {
-		clientConfig = DefaultHTTPClientConfig()
+	if cfg.Timeout > 0 {
+		withTimeout := *clientConfig
+		withTimeout.Timeout = cfg.Timeout
+		clientConfig = &withTimeout
 	}
 	client := NewHTTPClient(clientConfig)
 
@@ -82,11 +123,16 @@ func NewHTTPHandler(cfg *config.DestinationConfig, clientConfig *HTTPClientConfi
 	splitter := NewAlertSplitter(cfg, logger)
 
 	return &HTTPHandler{
-		config:   cfg,
-		client:   client,
-		engine:   engine,
-		logger:   logger,
-		splitter: splitter,
+		config:          cfg,
+		client:          client,
+		engine:          engine,
+		logger:          logger,
+		splitter:        splitter,
+		request:         request,
+		requestIDHeader: clientConfig.RequestIDHeader,
+		stats:           clientConfig.Stats,
+		library:         clientConfig.Library,
+		deliveryTimeout: clientConfig.DeliveryTimeout,
 	}, nil
 }
 
@@ -100,37 +146,69 @@ func (h *HTTPHandler) Send(ctx context.Context, payload *alertmanager.WebhookPay
 	}
 
 	// Transform the payload
-	transformed, err := h.engine.Transform(payload)
+	transformed, err := transformPayload(ctx, h.engine, payload)
 	if err != nil {
 		return fmt.Errorf("failed to transform payload: %w", err)
 	}
 
-	// Format the data
-	req, err := formatter.FormatData(formatter.OutputFormat(h.config.Format), transformed)
+	target, err := h.request.Render(nil, payload)
 	if err != nil {
-		return fmt.Errorf("failed to format data: %w", err)
+		return err
 	}
 
-	// Send the request
-	resp, err := h.sendRequest(ctx, req)
+	statusCode, err := h.deliver(ctx, transformed, target)
 	if err != nil {
 		return err
 	}
+
+	fields := logrus.Fields{
+		"duration_ms": time.Since(startTime).Milliseconds(),
+		"alerts_sent": len(payload.Alerts),
+	}
+	if statusCode > 0 {
+		fields["status_code"] = statusCode
+	}
+	h.logger.WithContext(ctx).WithFields(fields).Info("Successfully sent alerts to destination")
+
+	return nil
+}
+
+// deliver sends transformed data as one request or, in requests output mode, as the
+// requests it describes. The status code is only returned for a single request.
+func (h *HTTPHandler) deliver(ctx context.Context, transformed interface{}, target *RequestTarget) (int, error) {
+	if h.config.OutputMode == config.OutputModeRequests {
+		return 0, h.sendRequests(ctx, transformed, target)
+	}
+
+	return h.sendTransformed(ctx, transformed, target)
+}
+
+// sendTransformed formats transformed data, sends it to target and checks the response status
+func (h *HTTPHandler) sendTransformed(ctx context.Context, transformed interface{}, target *RequestTarget) (int, error) {
+	// Format the data
+	req, err := formatData(ctx, h.config.Format, transformed)
+	if err != nil {
+		return 0, fmt.Errorf("failed to format data: %w", err)
+	}
+
+	return h.send(ctx, req, target)
+}
+
+// send sends a formatted request to target and checks the response status
+func (h *HTTPHandler) send(ctx context.Context, req *formatter.Request, target *RequestTarget) (int, error) {
+	resp, err := h.sendRequest(ctx, req, target)
+	if err != nil {
+		return 0, err
+	}
 	defer resp.Body.Close()
 
 	// Check response
 	if !WrapResponse(resp).IsSuccess() {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("destination returned error: %s (body: %s)", resp.Status, string(body))
+		return resp.StatusCode, fmt.Errorf("destination returned error: %s (body: %s)", resp.Status, string(body))
 	}
 
-	h.logger.WithFields(logrus.Fields{
-		"duration_ms": time.Since(startTime).Milliseconds(),
-		"status_code": resp.StatusCode,
-		"alerts_sent": len(payload.Alerts),
-	}).Info("Successfully sent alerts to destination")
-
-	return nil
+	return resp.StatusCode, nil
 }
 
 // sendSplit sends alerts using the splitting logic
@@ -147,30 +225,24 @@ func (h *HTTPHandler) sendSplit(ctx context.Context, payload *alertmanager.Webho
 
 	// Check for errors
 	if result.FailureCount > 0 {
-		var errorMsg string
-		if len(result.Errors) <= 3 {
-			// Show all errors if there are few
-			errorMsgs := make([]string, len(result.Errors))
-			for i, err := range result.Errors {
-				errorMsgs[i] = err.Error()
-			}
-			errorMsg = fmt.Sprintf("failed to process %d/%d alerts: %s",
-				result.FailureCount, result.TotalAlerts, strings.Join(errorMsgs, "; "))
-		} else {
-			// Show summary if there are many errors
-			errorMsg = fmt.Sprintf("failed to process %d/%d alerts (showing first 3): %s; %s; %s",
-				result.FailureCount, result.TotalAlerts,
-				result.Errors[0].Error(),
-				result.Errors[1].Error(),
-				result.Errors[2].Error())
-		}
+		errorMsg := fmt.Sprintf("failed to process %d/%d alerts%s",
+			result.FailureCount, result.TotalAlerts, summarizeErrors(result.Errors))
 
 		// Return error only if all alerts failed
 		if result.SuccessCount == 0 {
 			return fmt.Errorf("%s", errorMsg)
 		}
 
-		// Log partial failures but don't return error
+		// Log partial failures but don't return error; the caller learns which alerts failed
+		failed := make([]int, 0, result.FailureCount)
+		for _, processed := range result.ProcessedData {
+			if !processed.Success {
+				failed = append(failed, processed.Index)
+			}
+		}
+		sort.Ints(failed)
+		recordFailedAlerts(ctx, failed)
+
 		h.logger.WithFields(logrus.Fields{
 			"total_alerts":  result.TotalAlerts,
 			"success_count": result.SuccessCount,
@@ -182,12 +254,27 @@ func (h *HTTPHandler) sendSplit(ctx context.Context, payload *alertmanager.Webho
 	return nil
 }
 
-// sendRequest sends an HTTP request based on the destination configuration
-func (h *HTTPHandler) sendRequest(ctx context.Context, req *formatter.Request) (*http.Response, error) {
-	method := strings.ToUpper(h.config.Method)
+// summarizeErrors lists errors for a failure message, showing at most three
+func summarizeErrors(errs []error) string {
+	if len(errs) <= 3 {
+		// Show all errors if there are few
+		errorMsgs := make([]string, len(errs))
+		for i, err := range errs {
+			errorMsgs[i] = err.Error()
+		}
+		return ": " + strings.Join(errorMsgs, "; ")
+	}
+
+	// Show summary if there are many errors
+	return fmt.Sprintf(" (showing first 3): %s; %s; %s", errs[0], errs[1], errs[2])
+}
+
+// sendRequest sends an HTTP request to a rendered target
+func (h *HTTPHandler) sendRequest(ctx context.Context, req *formatter.Request, target *RequestTarget) (*http.Response, error) {
+	method := target.Method
 
 	// Build URL with query parameters if needed
-	targetURL := h.config.URL
+	targetURL := target.URL
 	if len(req.QueryParams) > 0 {
 		u, err := url.Parse(targetURL)
 		if err != nil {
@@ -208,8 +295,16 @@ func (h *HTTPHandler) sendRequest(ctx context.Context, req *formatter.Request) (
 		body = bytes.NewReader(req.Body)
 	}
 
+	ctx, span := tracing.Start(ctx, "HTTP "+method,
+		attribute.String("destination", h.config.Name),
+		attribute.String("http.request.method", method),
+		attribute.Int("http.request.body.size", len(req.Body)),
+	)
+	defer span.End()
+
 	httpReq, err := http.NewRequestWithContext(ctx, method, targetURL, body)
 	if err != nil {
+		tracing.End(span, err)
 		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
@@ -218,13 +313,53 @@ func (h *HTTPHandler) sendRequest(ctx context.Context, req *formatter.Request) (
 		httpReq.Header[k] = v
 	}
 
-	// Add custom headers from config
-	for k, v := range h.config.Headers {
+	// Propagate the request id for correlation with the gateway logs
+	if h.requestIDHeader != "" {
+		if id := requestid.FromContext(ctx); id != "" {
+			httpReq.Header.Set(h.requestIDHeader, id)
+		}
+	}
+
+	// Add custom headers from config and headers_template
+	for k, v := range target.Headers {
 		httpReq.Header.Set(k, v)
 	}
 
+	// Continue the trace in the destination
+	tracing.Inject(ctx, httpReq.Header)
+
 	// Execute request
-	return h.client.Do(httpReq)
+	start := time.Now()
+	resp, err := h.client.Do(httpReq)
+	elapsed := time.Since(start)
+
+	statusCode := 0
+	statsErr := err
+	if resp != nil {
+		statusCode = resp.StatusCode
+		span.SetAttributes(attribute.Int("http.response.status_code", statusCode))
+		if !WrapResponse(resp).IsSuccess() {
+			span.SetStatus(codes.Error, resp.Status)
+			statsErr = fmt.Errorf("unexpected status: %s", resp.Status)
+		}
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	recordSend(ctx, statusCode, len(req.Body), elapsed)
+	h.stats.Record(h.config.Name, elapsed, statsErr)
+
+	return resp, err
+}
+
+// DeliveryTimeout returns the deadline of deliveries the handler starts itself
+func (h *HTTPHandler) DeliveryTimeout() time.Duration {
+	if h.deliveryTimeout > 0 {
+		return h.deliveryTimeout
+	}
+
+	return defaultDeliveryTimeout
 }
 
 // Name returns the destination name
@@ -14,6 +14,12 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
 	"github.com/vitalvas/alertmanager-gateway/internal/config"
+	"github.com/vitalvas/alertmanager-gateway/internal/tracing"
+	"github.com/vitalvas/alertmanager-gateway/internal/transform"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestNewHTTPHandler(t *testing.T) {
@@ -59,6 +65,49 @@ func TestNewHTTPHandler(t *testing.T) {
 			wantErr: true,
 			errMsg:  "transform is required for jq engine",
 		},
+		{
+			name: "valid cel config",
+			config: &config.DestinationConfig{
+				Name:      "test",
+				URL:       "https://example.com/webhook",
+				Method:    "POST",
+				Format:    "json",
+				Engine:    "cel",
+				Transform: `{"status": payload.status}`,
+			},
+			wantErr: false,
+		},
+		{
+			name: "cel with misspelled field",
+			config: &config.DestinationConfig{
+				Name:      "test",
+				Engine:    "cel",
+				Transform: `{"name": alert.lables.alertname}`,
+			},
+			wantErr: true,
+			errMsg:  "undefined field 'lables'",
+		},
+		{
+			name: "valid starlark config",
+			config: &config.DestinationConfig{
+				Name:      "test",
+				URL:       "https://example.com/webhook",
+				Method:    "POST",
+				Format:    "json",
+				Engine:    "starlark",
+				Transform: "def transform(payload, alert):\n    return {\"status\": payload[\"status\"]}\n",
+			},
+			wantErr: false,
+		},
+		{
+			name: "starlark without transform",
+			config: &config.DestinationConfig{
+				Name:   "test",
+				Engine: "starlark",
+			},
+			wantErr: true,
+			errMsg:  "transform is required for starlark engine",
+		},
 		{
 			name: "unknown engine",
 			config: &config.DestinationConfig{
@@ -276,6 +325,120 @@ func TestHTTPHandler_SendError(t *testing.T) {
 	assert.Contains(t, err.Error(), "500")
 }
 
+func TestHTTPHandler_SendInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	cfg := &config.DestinationConfig{
+		Name:     "test-info",
+		URL:      server.URL,
+		Method:   "POST",
+		Format:   "json",
+		Engine:   "go-template",
+		Template: `{"status": "{{ .Status }}"}`,
+	}
+
+	handler, err := NewHTTPHandler(cfg, nil)
+	require.NoError(t, err)
+	defer handler.Close()
+
+	payload := &alertmanager.WebhookPayload{Version: "4", GroupKey: "test-group", Status: "firing"}
+
+	info := &SendInfo{}
+	require.NoError(t, handler.Send(WithSendInfo(context.Background(), info), payload))
+
+	assert.Equal(t, 1, info.Requests())
+	assert.Equal(t, http.StatusAccepted, info.StatusCode())
+	assert.Equal(t, len(`{"status":"firing"}`), info.BodySize())
+	assert.Positive(t, info.TransformDuration())
+	assert.Positive(t, info.FormatDuration())
+	assert.Positive(t, info.SendDuration())
+
+	// Sending without SendInfo in the context is a no-op
+	require.NoError(t, handler.Send(context.Background(), payload))
+	assert.Equal(t, 1, info.Requests())
+}
+
+func TestHTTPHandler_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	cfg := &config.DestinationConfig{
+		Name:     "test-timeout",
+		URL:      server.URL,
+		Method:   "POST",
+		Format:   "json",
+		Engine:   "go-template",
+		Template: `{"status": "{{ .Status }}"}`,
+		Timeout:  50 * time.Millisecond,
+	}
+
+	handler, err := NewHTTPHandler(cfg, nil)
+	require.NoError(t, err)
+	defer handler.Close()
+
+	start := time.Now()
+	err = handler.Send(context.Background(), &alertmanager.WebhookPayload{Version: "4", Status: "firing"})
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestHTTPHandler_SendTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	tracing.SetupPropagation()
+	defer otel.SetTracerProvider(previous)
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := &config.DestinationConfig{
+		Name:     "test-tracing",
+		URL:      server.URL,
+		Method:   "POST",
+		Format:   "json",
+		Engine:   "go-template",
+		Template: `{"status": "{{ .Status }}"}`,
+	}
+
+	handler, err := NewHTTPHandler(cfg, nil)
+	require.NoError(t, err)
+	defer handler.Close()
+
+	ctx, parent := tracing.Start(context.Background(), "deliver")
+	payload := &alertmanager.WebhookPayload{Version: "4", GroupKey: "test-group", Status: "firing"}
+	require.NoError(t, handler.Send(ctx, payload))
+	parent.End()
+
+	names := make([]string, 0)
+	var httpSpan sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		names = append(names, span.Name())
+		assert.Equal(t, parent.SpanContext().TraceID(), span.SpanContext().TraceID())
+		if span.Name() == "HTTP POST" {
+			httpSpan = span
+		}
+	}
+
+	assert.Equal(t, []string{"transform", "format", "HTTP POST", "deliver"}, names)
+	require.NotNil(t, httpSpan)
+	assert.Contains(t, httpSpan.Attributes(), attribute.Int("http.response.status_code", http.StatusOK))
+	assert.Contains(t, traceparent, httpSpan.SpanContext().SpanID().String())
+}
+
 func TestHTTPHandler_SendWithQueryParams(t *testing.T) {
 	// Create test server
 	var receivedURL string
@@ -338,3 +501,23 @@ func TestHTTPHandler_TransformError(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to transform payload")
 }
+
+func TestNewHTTPHandler_SourceFileError(t *testing.T) {
+	cfg := &config.DestinationConfig{
+		Name:          "pager",
+		URL:           "https://pager.example.com",
+		Method:        "POST",
+		Format:        "json",
+		Engine:        "jq",
+		Transform:     "{\n  status: .status,\n  text: )\n}",
+		TransformFile: "/etc/gateway/pager.jq",
+	}
+
+	_, err := NewHTTPHandler(cfg, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "/etc/gateway/pager.jq:3: ")
+
+	var sourceErr *transform.SourceError
+	require.ErrorAs(t, err, &sourceErr)
+	assert.Equal(t, 3, sourceErr.Line)
+}
@@ -0,0 +1,294 @@
+package destination
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+	"github.com/vitalvas/alertmanager-gateway/internal/transform"
+)
+
+// Flusher is implemented by handlers that buffer alerts before sending them
+type Flusher interface {
+	// Flush sends all buffered alerts and returns how many were sent
+	Flush(ctx context.Context) (int, error)
+
+	// Pending returns the number of buffered alerts
+	Pending() int
+
+	// TakePending removes the buffered alerts and returns them as one payload, nil if there are none
+	TakePending() *alertmanager.WebhookPayload
+}
+
+// DigestHandler accumulates alerts across webhooks and sends them as one digest per window
+type DigestHandler struct {
+	handler *HTTPHandler
+	engine  transform.Engine
+	window  time.Duration
+	logger  *logrus.Entry
+
+	mu          sync.Mutex
+	alerts      map[string]alertmanager.Alert
+	receiver    string
+	externalURL string
+	windowStart time.Time
+
+	sendMu    sync.Mutex
+	reset     chan struct{}
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// NewDigestHandler wraps an HTTP handler with time-window digest delivery
+func NewDigestHandler(handler *HTTPHandler) (*DigestHandler, error) {
+	cfg := handler.config
+	if cfg.Digest.Window <= 0 {
+		return nil, fmt.Errorf("digest window must be positive")
+	}
+
+	engine := handler.engine
+	if cfg.Digest.Template != "" {
+		var err error
+		engine, err = transform.GetTemplateCache().GetEngine(transform.EngineType(cfg.Engine), cfg.Digest.Template, handler.library, cfg.TransformTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create digest engine: %w", err)
+		}
+	}
+
+	return &DigestHandler{
+		handler:     handler,
+		engine:      engine,
+		window:      cfg.Digest.Window,
+		logger:      handler.logger.WithField("mode", "digest"),
+		alerts:      make(map[string]alertmanager.Alert),
+		windowStart: time.Now(),
+		reset:       make(chan struct{}, 1),
+		done:        make(chan struct{}),
+	}, nil
+}
+
+// Start begins the digest flush scheduler, which sends the digest at the end of every window
+func (d *DigestHandler) Start() {
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+
+		for {
+			timer := time.NewTimer(time.Until(d.windowEnd()))
+
+			select {
+			case <-timer.C:
+				ctx, cancel := context.WithTimeout(context.Background(), d.handler.DeliveryTimeout())
+				if _, err := d.Flush(ctx); err != nil {
+					d.logger.WithError(err).Error("Failed to send scheduled digest")
+				}
+				cancel()
+			case <-d.reset:
+				timer.Stop()
+			case <-d.done:
+				timer.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// windowEnd returns when the current digest window ends
+func (d *DigestHandler) windowEnd() time.Time {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.windowStart.Add(d.window)
+}
+
+// Adopt takes over the pending alerts and the window of the digest handler this one replaces,
+// so a reload or an update does not send the digest early. The previous handler stops its
+// scheduler and is left empty.
+func (d *DigestHandler) Adopt(previous *DigestHandler) {
+	previous.stop()
+
+	// Wait for a digest being sent, which keeps its alerts if the send fails
+	previous.sendMu.Lock()
+	previous.mu.Lock()
+	alerts := previous.alerts
+	receiver, externalURL := previous.receiver, previous.externalURL
+	windowStart := previous.windowStart
+	previous.alerts = make(map[string]alertmanager.Alert)
+	previous.mu.Unlock()
+	previous.sendMu.Unlock()
+
+	d.mu.Lock()
+	for fingerprint, alert := range alerts {
+		if _, exists := d.alerts[fingerprint]; !exists {
+			d.alerts[fingerprint] = alert
+		}
+	}
+	if d.receiver == "" {
+		d.receiver, d.externalURL = receiver, externalURL
+	}
+	d.windowStart = windowStart
+	d.mu.Unlock()
+
+	// Make the scheduler wait for the end of the adopted window
+	select {
+	case d.reset <- struct{}{}:
+	default:
+	}
+}
+
+// stop stops the digest flush scheduler
+func (d *DigestHandler) stop() {
+	d.closeOnce.Do(func() {
+		close(d.done)
+	})
+	d.wg.Wait()
+}
+
+// Send adds the payload alerts to the current digest window
+func (d *DigestHandler) Send(ctx context.Context, payload *alertmanager.WebhookPayload) error {
+	d.mu.Lock()
+	for _, alert := range payload.Alerts {
+		// Keep the latest status of every alert
+		d.alerts[alert.Fingerprint] = alert
+	}
+	d.receiver = payload.Receiver
+	d.externalURL = payload.ExternalURL
+	pending := len(d.alerts)
+	d.mu.Unlock()
+
+	d.logger.WithFields(logrus.Fields{
+		"alerts_added": len(payload.Alerts),
+		"pending":      pending,
+	}).Debug("Added alerts to digest")
+
+	if maxAlerts := d.handler.config.Digest.MaxAlerts; maxAlerts > 0 && pending >= maxAlerts {
+		if _, err := d.Flush(ctx); err != nil {
+			return fmt.Errorf("failed to send digest: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Flush renders all accumulated alerts with the digest template and sends them
+func (d *DigestHandler) Flush(ctx context.Context) (int, error) {
+	d.sendMu.Lock()
+	defer d.sendMu.Unlock()
+
+	d.mu.Lock()
+	if len(d.alerts) == 0 {
+		d.windowStart = time.Now()
+		d.mu.Unlock()
+		return 0, nil
+	}
+
+	alerts := d.alerts
+	payload := d.buildPayload(alerts)
+	d.alerts = make(map[string]alertmanager.Alert)
+	d.windowStart = time.Now()
+	d.mu.Unlock()
+
+	startTime := time.Now()
+
+	transformed, err := transformPayload(ctx, d.engine, payload)
+	var target *RequestTarget
+	if err == nil {
+		target, err = d.handler.request.Render(nil, payload)
+	}
+	if err == nil {
+		_, err = d.handler.deliver(ctx, transformed, target)
+	}
+
+	if err != nil {
+		d.restore(alerts)
+		return 0, err
+	}
+
+	d.logger.WithFields(logrus.Fields{
+		"duration_ms": time.Since(startTime).Milliseconds(),
+		"alerts_sent": len(payload.Alerts),
+	}).Info("Successfully sent digest to destination")
+
+	return len(payload.Alerts), nil
+}
+
+// Pending returns the number of alerts waiting for the next digest
+func (d *DigestHandler) Pending() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return len(d.alerts)
+}
+
+// TakePending removes the alerts waiting for the next digest and returns them as a digest payload
+func (d *DigestHandler) TakePending() *alertmanager.WebhookPayload {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.alerts) == 0 {
+		return nil
+	}
+
+	payload := d.buildPayload(d.alerts)
+	d.alerts = make(map[string]alertmanager.Alert)
+
+	return payload
+}
+
+// Name returns the destination name
+func (d *DigestHandler) Name() string {
+	return d.handler.Name()
+}
+
+// Close stops the scheduler, sends the remaining digest and closes the underlying handler.
+// Alerts of a digest that fails to send stay pending, so the caller can take them.
+func (d *DigestHandler) Close() error {
+	d.stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), d.handler.DeliveryTimeout())
+	defer cancel()
+
+	if _, err := d.Flush(ctx); err != nil {
+		d.logger.WithError(err).WithField("pending", d.Pending()).Error("Failed to send digest on shutdown")
+	}
+
+	return d.handler.Close()
+}
+
+// buildPayload creates the digest payload from accumulated alerts
+func (d *DigestHandler) buildPayload(alerts map[string]alertmanager.Alert) *alertmanager.WebhookPayload {
+	list := make([]alertmanager.Alert, 0, len(alerts))
+	for _, alert := range alerts {
+		list = append(list, alert)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].StartsAt.Equal(list[j].StartsAt) {
+			return list[i].Fingerprint < list[j].Fingerprint
+		}
+		return list[i].StartsAt.Before(list[j].StartsAt)
+	})
+
+	payloads := alertmanager.GroupAlerts(list, nil, d.receiver, d.externalURL)
+	payload := payloads[0]
+	payload.GroupKey = fmt.Sprintf("digest:%s:%d", d.handler.Name(), d.windowStart.Unix())
+
+	return payload
+}
+
+// restore puts alerts back into the buffer after a failed flush without overwriting newer ones
+func (d *DigestHandler) restore(alerts map[string]alertmanager.Alert) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for fingerprint, alert := range alerts {
+		if _, exists := d.alerts[fingerprint]; !exists {
+			d.alerts[fingerprint] = alert
+		}
+	}
+}
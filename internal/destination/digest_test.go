@@ -0,0 +1,262 @@
+package destination
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+	"github.com/vitalvas/alertmanager-gateway/internal/config"
+)
+
+func newTestDigestHandler(t *testing.T, url string, digest config.DigestConfig) *DigestHandler {
+	t.Helper()
+
+	handler, err := NewHTTPHandler(&config.DestinationConfig{
+		Name:     "digest-dest",
+		URL:      url,
+		Method:   "POST",
+		Format:   "json",
+		Engine:   "go-template",
+		Template: `{"status": "{{ .Status }}", "count": {{ len .Alerts }}}`,
+		Digest:   digest,
+	}, nil)
+	require.NoError(t, err)
+
+	digestHandler, err := NewDigestHandler(handler)
+	require.NoError(t, err)
+
+	return digestHandler
+}
+
+func digestPayload(alerts ...alertmanager.Alert) *alertmanager.WebhookPayload {
+	return &alertmanager.WebhookPayload{
+		Version:  "4",
+		GroupKey: "group",
+		Status:   "firing",
+		Receiver: "test",
+		Alerts:   alerts,
+	}
+}
+
+func digestAlert(fingerprint, status string, startsAt time.Time) alertmanager.Alert {
+	return alertmanager.Alert{
+		Status:      status,
+		Fingerprint: fingerprint,
+		Labels:      map[string]string{"alertname": fingerprint},
+		StartsAt:    startsAt,
+	}
+}
+
+func TestNewDigestHandler(t *testing.T) {
+	handler, err := NewHTTPHandler(&config.DestinationConfig{
+		Name:     "test",
+		URL:      "https://example.com",
+		Method:   "POST",
+		Format:   "json",
+		Engine:   "go-template",
+		Template: `{}`,
+	}, nil)
+	require.NoError(t, err)
+
+	_, err = NewDigestHandler(handler)
+	assert.Error(t, err)
+
+	handler.config.Digest = config.DigestConfig{Window: time.Minute, Template: "{{ .Invalid"}
+	_, err = NewDigestHandler(handler)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to create digest engine")
+}
+
+func TestDigestHandler_Flush(t *testing.T) {
+	var requests int32
+	var received map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	digest := newTestDigestHandler(t, server.URL, config.DigestConfig{Window: time.Hour})
+	now := time.Now()
+
+	require.NoError(t, digest.Send(context.Background(), digestPayload(digestAlert("a", "firing", now))))
+	require.NoError(t, digest.Send(context.Background(), digestPayload(digestAlert("b", "firing", now))))
+	require.NoError(t, digest.Send(context.Background(), digestPayload(digestAlert("a", "resolved", now))))
+
+	assert.Equal(t, 2, digest.Pending())
+	assert.Equal(t, int32(0), atomic.LoadInt32(&requests))
+
+	sent, err := digest.Flush(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, sent)
+	assert.Equal(t, 0, digest.Pending())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	assert.Equal(t, "firing", received["status"])
+	assert.Equal(t, float64(2), received["count"])
+
+	// Empty flush sends nothing
+	sent, err = digest.Flush(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestDigestHandler_Template(t *testing.T) {
+	var received map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	digest := newTestDigestHandler(t, server.URL, config.DigestConfig{
+		Window:   time.Hour,
+		Template: `{"names": "{{ range .Alerts }}{{ .Labels.alertname }} {{ end }}"}`,
+	})
+	now := time.Now()
+
+	require.NoError(t, digest.Send(context.Background(), digestPayload(
+		digestAlert("second", "firing", now.Add(time.Minute)),
+		digestAlert("first", "firing", now),
+	)))
+
+	_, err := digest.Flush(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "first second ", received["names"])
+}
+
+func TestDigestHandler_MaxAlerts(t *testing.T) {
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	digest := newTestDigestHandler(t, server.URL, config.DigestConfig{Window: time.Hour, MaxAlerts: 2})
+	now := time.Now()
+
+	require.NoError(t, digest.Send(context.Background(), digestPayload(digestAlert("a", "firing", now))))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&requests))
+
+	require.NoError(t, digest.Send(context.Background(), digestPayload(digestAlert("b", "firing", now))))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	assert.Equal(t, 0, digest.Pending())
+}
+
+func TestDigestHandler_FlushFailureKeepsAlerts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	digest := newTestDigestHandler(t, server.URL, config.DigestConfig{Window: time.Hour})
+
+	require.NoError(t, digest.Send(context.Background(), digestPayload(digestAlert("a", "firing", time.Now()))))
+
+	_, err := digest.Flush(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, 1, digest.Pending())
+}
+
+func TestDigestHandler_Scheduler(t *testing.T) {
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	digest := newTestDigestHandler(t, server.URL, config.DigestConfig{Window: 20 * time.Millisecond})
+	digest.Start()
+
+	require.NoError(t, digest.Send(context.Background(), digestPayload(digestAlert("a", "firing", time.Now()))))
+
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&requests) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, digest.Send(context.Background(), digestPayload(digestAlert("b", "firing", time.Now()))))
+	require.NoError(t, digest.Close())
+	assert.Equal(t, 0, digest.Pending())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestDigestHandler_Adopt(t *testing.T) {
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	previous := newTestDigestHandler(t, server.URL, config.DigestConfig{Window: time.Hour})
+	previous.Start()
+	require.NoError(t, previous.Send(context.Background(), digestPayload(digestAlert("a", "firing", time.Now()))))
+	windowEnd := previous.windowEnd()
+
+	next := newTestDigestHandler(t, server.URL, config.DigestConfig{Window: time.Hour})
+	next.Start()
+	next.Adopt(previous)
+
+	// The replaced handler has nothing left to send when it is closed
+	require.NoError(t, previous.Close())
+	assert.Equal(t, int32(0), atomic.LoadInt32(&requests))
+
+	assert.Equal(t, 1, next.Pending())
+	assert.Equal(t, windowEnd, next.windowEnd())
+
+	require.NoError(t, next.Close())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestDigestHandler_DeliveryTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	clientConfig := DefaultHTTPClientConfig()
+	clientConfig.DeliveryTimeout = 50 * time.Millisecond
+
+	handler, err := NewHTTPHandler(&config.DestinationConfig{
+		Name:     "digest-dest",
+		URL:      server.URL,
+		Method:   "POST",
+		Format:   "json",
+		Engine:   "go-template",
+		Template: `{"count": {{ len .Alerts }}}`,
+		Digest:   config.DigestConfig{Window: time.Hour},
+	}, clientConfig)
+	require.NoError(t, err)
+	assert.Equal(t, 50*time.Millisecond, handler.DeliveryTimeout())
+
+	digest, err := NewDigestHandler(handler)
+	require.NoError(t, err)
+	require.NoError(t, digest.Send(context.Background(), digestPayload(digestAlert("a", "firing", time.Now()))))
+
+	// The shutdown flush gives up after the delivery deadline and keeps the alert
+	start := time.Now()
+	require.NoError(t, digest.Close())
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.Equal(t, 1, digest.Pending())
+}
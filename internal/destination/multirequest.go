@@ -0,0 +1,87 @@
+package destination
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vitalvas/alertmanager-gateway/internal/formatter"
+)
+
+// RequestFailure is a request of a requests output that failed or was skipped
+type RequestFailure struct {
+	Index int
+	Err   error
+}
+
+// RequestsError reports every request of a requests output that was not sent successfully
+type RequestsError struct {
+	Total    int
+	Failures []RequestFailure
+}
+
+func (e *RequestsError) Error() string {
+	errs := make([]error, len(e.Failures))
+	for i, failure := range e.Failures {
+		errs[i] = fmt.Errorf("request %d: %w", failure.Index, failure.Err)
+	}
+
+	return fmt.Sprintf("failed to send %d/%d requests%s", len(e.Failures), e.Total, summarizeErrors(errs))
+}
+
+// Unwrap returns the errors of the failed requests
+func (e *RequestsError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, failure := range e.Failures {
+		errs[i] = failure.Err
+	}
+
+	return errs
+}
+
+// sendRequests sends the requests described by transformed data in requests output mode.
+// All request objects are checked before the first one is sent. Any request that failed
+// or was skipped fails the delivery with a *RequestsError listing each of them.
+func (h *HTTPHandler) sendRequests(ctx context.Context, transformed interface{}, target *RequestTarget) error {
+	requests, err := h.request.Requests(transformed, target)
+	if err != nil {
+		return fmt.Errorf("invalid requests output: %w", err)
+	}
+
+	if len(requests) == 0 {
+		return nil
+	}
+
+	errs := h.splitter.Each(ctx, len(requests), func(ctx context.Context, i int) error {
+		req := &formatter.Request{}
+		if requests[i].Body != nil {
+			var err error
+			if req, err = formatData(ctx, h.config.Format, requests[i].Body); err != nil {
+				return fmt.Errorf("failed to format data: %w", err)
+			}
+		}
+
+		_, err := h.send(ctx, req, requests[i].Target)
+		return err
+	})
+
+	var failures []RequestFailure
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		failures = append(failures, RequestFailure{Index: i, Err: err})
+		h.logger.WithContext(ctx).WithFields(logrus.Fields{
+			"request": i,
+			"method":  requests[i].Target.Method,
+			"error":   err,
+		}).Warn("Request of requests output failed")
+	}
+
+	if len(failures) == 0 {
+		return nil
+	}
+
+	return &RequestsError{Total: len(requests), Failures: failures}
+}
@@ -0,0 +1,350 @@
+package destination
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+	"github.com/vitalvas/alertmanager-gateway/internal/config"
+	"github.com/vitalvas/alertmanager-gateway/internal/transform"
+)
+
+func TestRequestTemplate_Requests(t *testing.T) {
+	request, err := NewRequestTemplate(&config.DestinationConfig{
+		URL:          "https://jira.example.com/rest/api/2/issue",
+		Method:       "POST",
+		AllowedHosts: []string{"*.example.net"},
+	}, nil)
+	require.NoError(t, err)
+
+	target := &RequestTarget{
+		URL:     "https://jira.example.com/rest/api/2/issue",
+		Method:  http.MethodPost,
+		Headers: map[string]string{"Authorization": "Bearer token"},
+	}
+
+	tests := []struct {
+		name        string
+		transformed interface{}
+		expected    []OutgoingRequest
+		errMsg      string
+	}{
+		{
+			name: "list of requests",
+			transformed: []interface{}{
+				map[string]interface{}{"body": map[string]interface{}{"summary": "HighCPU"}},
+				map[string]interface{}{
+					"url":     "https://hooks.example.net/comment",
+					"method":  "put",
+					"headers": map[string]interface{}{"X-Team": "db"},
+				},
+			},
+			expected: []OutgoingRequest{
+				{
+					Target: &RequestTarget{
+						URL:     "https://jira.example.com/rest/api/2/issue",
+						Method:  http.MethodPost,
+						Headers: map[string]string{"Authorization": "Bearer token"},
+					},
+					Body: map[string]interface{}{"summary": "HighCPU"},
+				},
+				{
+					Target: &RequestTarget{
+						URL:     "https://hooks.example.net/comment",
+						Method:  http.MethodPut,
+						Headers: map[string]string{"Authorization": "Bearer token", "X-Team": "db"},
+					},
+				},
+			},
+		},
+		{
+			name:        "single object",
+			transformed: map[string]interface{}{"method": "DELETE"},
+			expected: []OutgoingRequest{
+				{
+					Target: &RequestTarget{
+						URL:     "https://jira.example.com/rest/api/2/issue",
+						Method:  http.MethodDelete,
+						Headers: map[string]string{"Authorization": "Bearer token"},
+					},
+				},
+			},
+		},
+		{
+			name:        "empty list",
+			transformed: []interface{}{},
+			expected:    []OutgoingRequest{},
+		},
+		{
+			name:        "not a list",
+			transformed: "text",
+			errMsg:      "expected a list of request objects, got string",
+		},
+		{
+			name:        "not an object",
+			transformed: []interface{}{map[string]interface{}{}, "text"},
+			errMsg:      "request 1: expected a request object, got string",
+		},
+		{
+			name:        "unknown field",
+			transformed: []interface{}{map[string]interface{}{"uri": "https://jira.example.com"}},
+			errMsg:      "request 0: unknown field uri",
+		},
+		{
+			name:        "host not allowed",
+			transformed: []interface{}{map[string]interface{}{"url": "http://169.254.169.254/latest"}},
+			errMsg:      "request 0: url rendered host 169.254.169.254, which is not in allowed_hosts",
+		},
+		{
+			name:        "invalid method",
+			transformed: []interface{}{map[string]interface{}{"method": "TRACE"}},
+			errMsg:      "request 0: invalid method TRACE",
+		},
+		{
+			name:        "header with line break",
+			transformed: []interface{}{map[string]interface{}{"headers": map[string]interface{}{"X-Team": "db\r\nX-Injected: 1"}}},
+			errMsg:      "request 0: header X-Team contains a line break",
+		},
+		{
+			name:        "header that is not a string",
+			transformed: []interface{}{map[string]interface{}{"headers": map[string]interface{}{"X-Count": 1.0}}},
+			errMsg:      "request 0: header X-Count must be a string",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests, err := request.Requests(tt.transformed, target)
+			if tt.errMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, requests)
+		})
+	}
+}
+
+// requestRecorder records the requests received by a test server
+type requestRecorder struct {
+	mu       sync.Mutex
+	received []string
+}
+
+func (r *requestRecorder) handler(fail string) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		r.mu.Lock()
+		r.received = append(r.received, req.Method+" "+req.URL.Path+" "+string(body))
+		r.mu.Unlock()
+
+		if req.URL.Path == fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func newRequestsHandler(t *testing.T, url string, parallel int) *HTTPHandler {
+	cfg := &config.DestinationConfig{
+		Name:       "jira",
+		URL:        url + "/issue",
+		Method:     "POST",
+		Format:     "json",
+		Engine:     "jq",
+		OutputMode: config.OutputModeRequests,
+		Transform: `[
+			{body: {summary: .groupLabels.alertname}},
+			{url: "` + url + `/comment", method: "put", body: {text: .status}},
+			{url: "` + url + `/transition", body: {state: "open"}}
+		]`,
+		ParallelRequests: parallel,
+	}
+	cfg.SetDefaults()
+
+	handler, err := NewHTTPHandler(cfg, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { handler.Close() })
+
+	return handler
+}
+
+func TestHTTPHandler_SendRequests(t *testing.T) {
+	payload := &alertmanager.WebhookPayload{
+		Status:      "firing",
+		GroupLabels: map[string]string{"alertname": "HighCPU"},
+		Alerts:      []alertmanager.Alert{{Labels: map[string]string{"alertname": "HighCPU"}}},
+	}
+
+	t.Run("sequential", func(t *testing.T) {
+		recorder := &requestRecorder{}
+		server := httptest.NewServer(recorder.handler(""))
+		defer server.Close()
+
+		handler := newRequestsHandler(t, server.URL, 1)
+		require.NoError(t, handler.Send(context.Background(), payload))
+
+		assert.Equal(t, []string{
+			`POST /issue {"summary":"HighCPU"}`,
+			`PUT /comment {"text":"firing"}`,
+			`POST /transition {"state":"open"}`,
+		}, recorder.received)
+	})
+
+	t.Run("sequential stops at the first failure", func(t *testing.T) {
+		recorder := &requestRecorder{}
+		server := httptest.NewServer(recorder.handler("/issue"))
+		defer server.Close()
+
+		handler := newRequestsHandler(t, server.URL, 1)
+		err := handler.Send(context.Background(), payload)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to send 3/3 requests")
+		assert.Contains(t, err.Error(), "request 0: destination returned error: 500 Internal Server Error")
+		assert.Contains(t, err.Error(), "request 2: skipped after an earlier request failed")
+
+		var requestsErr *RequestsError
+		require.ErrorAs(t, err, &requestsErr)
+		assert.Len(t, requestsErr.Failures, 3)
+		assert.ErrorIs(t, err, errSkipped)
+
+		assert.Len(t, recorder.received, 1)
+	})
+
+	t.Run("parallel reports partial failures", func(t *testing.T) {
+		recorder := &requestRecorder{}
+		server := httptest.NewServer(recorder.handler("/comment"))
+		defer server.Close()
+
+		handler := newRequestsHandler(t, server.URL, 3)
+		err := handler.Send(context.Background(), payload)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to send 1/3 requests: request 1: destination returned error: 500 Internal Server Error")
+
+		var requestsErr *RequestsError
+		require.ErrorAs(t, err, &requestsErr)
+		assert.Equal(t, 3, requestsErr.Total)
+		require.Len(t, requestsErr.Failures, 1)
+		assert.Equal(t, 1, requestsErr.Failures[0].Index)
+
+		sort.Strings(recorder.received)
+		assert.Equal(t, []string{
+			`POST /issue {"summary":"HighCPU"}`,
+			`POST /transition {"state":"open"}`,
+			`PUT /comment {"text":"firing"}`,
+		}, recorder.received)
+	})
+
+	t.Run("invalid output sends nothing", func(t *testing.T) {
+		recorder := &requestRecorder{}
+		server := httptest.NewServer(recorder.handler(""))
+		defer server.Close()
+
+		handler := newRequestsHandler(t, server.URL, 1)
+		engine, err := transform.NewJQEngine(`[{body: {}}, {url: "https://elsewhere.example.org/"}]`)
+		require.NoError(t, err)
+		handler.engine = engine
+
+		err = handler.Send(context.Background(), payload)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid requests output: request 1: url rendered host elsewhere.example.org")
+		assert.Empty(t, recorder.received)
+	})
+}
+
+func TestHTTPHandler_SendRequestsSplit(t *testing.T) {
+	recorder := &requestRecorder{}
+	server := httptest.NewServer(recorder.handler(""))
+	defer server.Close()
+
+	cfg := &config.DestinationConfig{
+		Name:        "jira",
+		URL:         server.URL,
+		Method:      "POST",
+		Format:      "json",
+		Engine:      "jq",
+		OutputMode:  config.OutputModeRequests,
+		SplitAlerts: true,
+		BatchSize:   1,
+		Transform:   `[{url: "` + server.URL + `/issue/\(.alert.fingerprint)", body: {summary: .alert.labels.alertname}}]`,
+	}
+	cfg.SetDefaults()
+
+	handler, err := NewHTTPHandler(cfg, nil)
+	require.NoError(t, err)
+	defer handler.Close()
+
+	require.NoError(t, handler.Send(context.Background(), &alertmanager.WebhookPayload{
+		Status: "firing",
+		Alerts: []alertmanager.Alert{
+			{Fingerprint: "a", Labels: map[string]string{"alertname": "DiskFull"}},
+			{Fingerprint: "b", Labels: map[string]string{"alertname": "SlowQuery"}},
+		},
+	}))
+
+	sort.Strings(recorder.received)
+	assert.Equal(t, []string{
+		`POST /issue/a {"summary":"DiskFull"}`,
+		`POST /issue/b {"summary":"SlowQuery"}`,
+	}, recorder.received)
+}
+
+func TestHTTPHandler_SendRequestsSplitConcurrency(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			peak := maxInFlight.Load()
+			if current <= peak || maxInFlight.CompareAndSwap(peak, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := &config.DestinationConfig{
+		Name:             "jira",
+		URL:              server.URL,
+		Method:           "POST",
+		Format:           "json",
+		Engine:           "jq",
+		OutputMode:       config.OutputModeRequests,
+		SplitAlerts:      true,
+		BatchSize:        1,
+		ParallelRequests: 3,
+		Transform:        `[range(4) | {body: {n: .}}]`,
+	}
+	cfg.SetDefaults()
+
+	handler, err := NewHTTPHandler(cfg, nil)
+	require.NoError(t, err)
+	defer handler.Close()
+
+	alerts := make([]alertmanager.Alert, 4)
+	for i := range alerts {
+		alerts[i] = alertmanager.Alert{Fingerprint: strings.Repeat("a", i+1)}
+	}
+
+	require.NoError(t, handler.Send(context.Background(), &alertmanager.WebhookPayload{Status: "firing", Alerts: alerts}))
+
+	// Requests of all alerts share the parallel request limit
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(3))
+	assert.Greater(t, maxInFlight.Load(), int32(1))
+}
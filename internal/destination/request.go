@@ -0,0 +1,265 @@
+package destination
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+	"github.com/vitalvas/alertmanager-gateway/internal/config"
+	"github.com/vitalvas/alertmanager-gateway/internal/transform"
+)
+
+// RequestTarget is the URL, method and headers a request is sent with
+type RequestTarget struct {
+	URL     string
+	Method  string
+	Headers map[string]string
+}
+
+// RequestTemplate renders the target of each request from the url_template,
+// method_template and headers_template of a destination
+type RequestTemplate struct {
+	config       *config.DestinationConfig
+	url          *transform.GoTemplateEngine
+	method       *transform.GoTemplateEngine
+	headers      map[string]*transform.GoTemplateEngine
+	allowedHosts []string
+}
+
+// NewRequestTemplate compiles the request templates of a destination
+func NewRequestTemplate(cfg *config.DestinationConfig, lib *transform.Library) (*RequestTemplate, error) {
+	t := &RequestTemplate{
+		config:  cfg,
+		headers: make(map[string]*transform.GoTemplateEngine, len(cfg.HeadersTemplate)),
+	}
+
+	var err error
+	if t.url, err = compileRequestTemplate(cfg, cfg.URLTemplate, lib); err != nil {
+		return nil, fmt.Errorf("invalid url_template: %w", err)
+	}
+	if t.method, err = compileRequestTemplate(cfg, cfg.MethodTemplate, lib); err != nil {
+		return nil, fmt.Errorf("invalid method_template: %w", err)
+	}
+	for name, source := range cfg.HeadersTemplate {
+		if t.headers[name], err = compileRequestTemplate(cfg, source, lib); err != nil {
+			return nil, fmt.Errorf("invalid headers_template %s: %w", name, err)
+		}
+	}
+
+	// The host of the static URL is always allowed
+	if u, err := url.Parse(cfg.URL); err == nil && u.Hostname() != "" {
+		t.allowedHosts = append(t.allowedHosts, u.Hostname())
+	}
+	t.allowedHosts = append(t.allowedHosts, cfg.AllowedHosts...)
+
+	return t, nil
+}
+
+// compileRequestTemplate returns the shared engine of a template, or nil when it is empty
+func compileRequestTemplate(cfg *config.DestinationConfig, source string, lib *transform.Library) (*transform.GoTemplateEngine, error) {
+	if source == "" {
+		return nil, nil
+	}
+
+	engine, err := transform.GetTemplateCache().GetEngine(transform.EngineTypeGoTemplate, source, lib, cfg.TransformTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return engine.(*transform.GoTemplateEngine), nil
+}
+
+// Render returns the target of a request for an alert in split mode, or for the whole
+// payload when alert is nil
+func (t *RequestTemplate) Render(alert *alertmanager.Alert, payload *alertmanager.WebhookPayload) (*RequestTarget, error) {
+	target := &RequestTarget{
+		URL:     t.config.URL,
+		Method:  strings.ToUpper(t.config.Method),
+		Headers: make(map[string]string, len(t.config.Headers)+len(t.headers)),
+	}
+	for k, v := range t.config.Headers {
+		target.Headers[k] = v
+	}
+
+	render := func(engine *transform.GoTemplateEngine) (string, error) {
+		if alert != nil {
+			return engine.RenderAlert(alert, payload)
+		}
+		return engine.Render(payload)
+	}
+
+	if t.url != nil {
+		rendered, err := render(t.url)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render url_template: %w", err)
+		}
+		if err := t.checkURL("url_template", rendered); err != nil {
+			return nil, err
+		}
+		target.URL = rendered
+	}
+
+	if t.method != nil {
+		rendered, err := render(t.method)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render method_template: %w", err)
+		}
+		// An empty result keeps the configured method
+		if rendered != "" {
+			method := strings.ToUpper(rendered)
+			if !validMethod(method) {
+				return nil, fmt.Errorf("method_template rendered invalid method %q", rendered)
+			}
+			target.Method = method
+		}
+	}
+
+	for name, engine := range t.headers {
+		rendered, err := render(engine)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render headers_template %s: %w", name, err)
+		}
+		if strings.ContainsAny(rendered, "\r\n") {
+			return nil, fmt.Errorf("headers_template %s rendered a value with a line break", name)
+		}
+		// An empty result leaves the header out
+		if rendered == "" {
+			delete(target.Headers, name)
+			continue
+		}
+		target.Headers[name] = rendered
+	}
+
+	return target, nil
+}
+
+// validMethod reports whether a request method can be used by destinations
+func validMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// checkURL verifies that a URL rendered by source is absolute and points at an allowed host
+func (t *RequestTemplate) checkURL(source, rendered string) error {
+	// The rendered URL is left out of errors, as it may carry credentials
+	u, err := url.Parse(rendered)
+	if err != nil {
+		return fmt.Errorf("%s rendered an invalid URL", source)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s did not render an absolute http or https URL", source)
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range t.allowedHosts {
+		allowed = strings.ToLower(allowed)
+		if host == allowed {
+			return nil
+		}
+		if strings.HasPrefix(allowed, "*.") && strings.HasSuffix(host, allowed[1:]) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s rendered host %s, which is not in allowed_hosts", source, u.Hostname())
+}
+
+// OutgoingRequest is one request of a transformation result in requests output mode
+type OutgoingRequest struct {
+	Target *RequestTarget
+	Body   interface{}
+}
+
+// Requests reads the request objects of a transformation result in requests output mode.
+// Each object has optional url, method, headers and body fields; url and method default
+// to target and headers are added to those of target. A single object is one request.
+func (t *RequestTemplate) Requests(transformed interface{}, target *RequestTarget) ([]OutgoingRequest, error) {
+	var objects []interface{}
+	switch v := transformed.(type) {
+	case []interface{}:
+		objects = v
+	case map[string]interface{}:
+		objects = []interface{}{v}
+	case nil:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("expected a list of request objects, got %T", transformed)
+	}
+
+	requests := make([]OutgoingRequest, len(objects))
+	for i, object := range objects {
+		request, err := t.request(object, target)
+		if err != nil {
+			return nil, fmt.Errorf("request %d: %w", i, err)
+		}
+		requests[i] = request
+	}
+
+	return requests, nil
+}
+
+// request reads a single request object
+func (t *RequestTemplate) request(object interface{}, target *RequestTarget) (OutgoingRequest, error) {
+	fields, ok := object.(map[string]interface{})
+	if !ok {
+		return OutgoingRequest{}, fmt.Errorf("expected a request object, got %T", object)
+	}
+
+	request := OutgoingRequest{
+		Target: &RequestTarget{
+			URL:     target.URL,
+			Method:  target.Method,
+			Headers: make(map[string]string, len(target.Headers)),
+		},
+	}
+	for k, v := range target.Headers {
+		request.Target.Headers[k] = v
+	}
+
+	for name, value := range fields {
+		switch name {
+		case "url":
+			rawURL, ok := value.(string)
+			if !ok {
+				return OutgoingRequest{}, fmt.Errorf("url must be a string")
+			}
+			if err := t.checkURL("url", rawURL); err != nil {
+				return OutgoingRequest{}, err
+			}
+			request.Target.URL = rawURL
+		case "method":
+			method, ok := value.(string)
+			if !ok || !validMethod(strings.ToUpper(method)) {
+				return OutgoingRequest{}, fmt.Errorf("invalid method %v", value)
+			}
+			request.Target.Method = strings.ToUpper(method)
+		case "headers":
+			headers, ok := value.(map[string]interface{})
+			if !ok {
+				return OutgoingRequest{}, fmt.Errorf("headers must be an object of strings")
+			}
+			for k, v := range headers {
+				header, ok := v.(string)
+				if !ok {
+					return OutgoingRequest{}, fmt.Errorf("header %s must be a string", k)
+				}
+				if strings.ContainsAny(header, "\r\n") {
+					return OutgoingRequest{}, fmt.Errorf("header %s contains a line break", k)
+				}
+				request.Target.Headers[k] = header
+			}
+		case "body":
+			request.Body = value
+		default:
+			return OutgoingRequest{}, fmt.Errorf("unknown field %s", name)
+		}
+	}
+
+	return request, nil
+}
@@ -0,0 +1,225 @@
+package destination
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+	"github.com/vitalvas/alertmanager-gateway/internal/config"
+)
+
+func TestRequestTemplate_Render(t *testing.T) {
+	payload := &alertmanager.WebhookPayload{
+		Status:       "firing",
+		CommonLabels: map[string]string{"chat_id": "-100123", "severity": "critical", "team": "db"},
+		Alerts: []alertmanager.Alert{
+			{Labels: map[string]string{"alertname": "HighCPU", "severity": "critical", "project": "OPS"}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		cfg      config.DestinationConfig
+		alert    *alertmanager.Alert
+		expected *RequestTarget
+		errMsg   string
+	}{
+		{
+			name: "static target",
+			cfg: config.DestinationConfig{
+				URL:     "https://chat.example.com/hook",
+				Method:  "post",
+				Headers: map[string]string{"X-Token": "secret"},
+			},
+			expected: &RequestTarget{
+				URL:     "https://chat.example.com/hook",
+				Method:  http.MethodPost,
+				Headers: map[string]string{"X-Token": "secret"},
+			},
+		},
+		{
+			name: "grouped payload",
+			cfg: config.DestinationConfig{
+				URL:         "https://api.telegram.org/bot123/sendMessage",
+				Method:      "POST",
+				URLTemplate: `https://api.telegram.org/bot123/sendMessage?chat_id={{ urlquery .CommonLabels.chat_id }}`,
+				HeadersTemplate: map[string]string{
+					"X-Priority": `{{ if eq .CommonLabels.severity "critical" }}1{{ else }}3{{ end }}`,
+				},
+			},
+			expected: &RequestTarget{
+				URL:     "https://api.telegram.org/bot123/sendMessage?chat_id=-100123",
+				Method:  http.MethodPost,
+				Headers: map[string]string{"X-Priority": "1"},
+			},
+		},
+		{
+			name: "split alert",
+			cfg: config.DestinationConfig{
+				URL:            "https://jira.example.com",
+				Method:         "POST",
+				URLTemplate:    `https://jira.example.com/rest/api/2/project/{{ .Labels.project }}/issue`,
+				MethodTemplate: `{{ if eq .Alert.Status "resolved" }}PUT{{ end }}`,
+				Headers:        map[string]string{"X-Team": "default", "X-Static": "yes"},
+				HeadersTemplate: map[string]string{
+					"X-Team":  `{{ .CommonLabels.team }}`,
+					"X-Alert": `{{ .Labels.alertname }}`,
+				},
+			},
+			alert: &payload.Alerts[0],
+			expected: &RequestTarget{
+				URL:     "https://jira.example.com/rest/api/2/project/OPS/issue",
+				Method:  http.MethodPost,
+				Headers: map[string]string{"X-Team": "db", "X-Static": "yes", "X-Alert": "HighCPU"},
+			},
+		},
+		{
+			name: "allowed host wildcard",
+			cfg: config.DestinationConfig{
+				URL:          "https://chat.example.com",
+				Method:       "POST",
+				URLTemplate:  `https://{{ .CommonLabels.team }}.hooks.example.net/alert`,
+				AllowedHosts: []string{"*.hooks.example.net"},
+			},
+			expected: &RequestTarget{
+				URL:     "https://db.hooks.example.net/alert",
+				Method:  http.MethodPost,
+				Headers: map[string]string{},
+			},
+		},
+		{
+			name: "empty header is left out",
+			cfg: config.DestinationConfig{
+				URL:             "https://chat.example.com",
+				Method:          "POST",
+				Headers:         map[string]string{"X-Priority": "3"},
+				HeadersTemplate: map[string]string{"X-Priority": `{{ with .CommonLabels.priority }}{{ . }}{{ end }}`},
+			},
+			expected: &RequestTarget{
+				URL:     "https://chat.example.com",
+				Method:  http.MethodPost,
+				Headers: map[string]string{},
+			},
+		},
+		{
+			name: "host not allowed",
+			cfg: config.DestinationConfig{
+				URL:         "https://chat.example.com",
+				Method:      "POST",
+				URLTemplate: `https://{{ .CommonLabels.team }}.internal/alert`,
+			},
+			errMsg: "url_template rendered host db.internal, which is not in allowed_hosts",
+		},
+		{
+			name: "relative url",
+			cfg: config.DestinationConfig{
+				URL:         "https://chat.example.com",
+				Method:      "POST",
+				URLTemplate: `/{{ .CommonLabels.team }}`,
+			},
+			errMsg: "url_template did not render an absolute http or https URL",
+		},
+		{
+			name: "invalid method",
+			cfg: config.DestinationConfig{
+				URL:            "https://chat.example.com",
+				Method:         "POST",
+				MethodTemplate: `{{ .Status }}`,
+			},
+			errMsg: `method_template rendered invalid method "firing"`,
+		},
+		{
+			name: "header with line break",
+			cfg: config.DestinationConfig{
+				URL:             "https://chat.example.com",
+				Method:          "POST",
+				HeadersTemplate: map[string]string{"X-Team": "{{ .CommonLabels.team }}\nX-Injected: 1"},
+			},
+			errMsg: "headers_template X-Team rendered a value with a line break",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := NewRequestTemplate(&tt.cfg, nil)
+			require.NoError(t, err)
+
+			target, err := request.Render(tt.alert, payload)
+			if tt.errMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, target)
+		})
+	}
+}
+
+func TestNewRequestTemplate_InvalidTemplate(t *testing.T) {
+	_, err := NewRequestTemplate(&config.DestinationConfig{
+		URL:             "https://chat.example.com",
+		HeadersTemplate: map[string]string{"X-Team": "{{ .CommonLabels.team "},
+	}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid headers_template X-Team")
+}
+
+func TestHTTPHandler_SendWithRequestTemplates(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received []string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		received = append(received, r.Method+" "+r.URL.Path+" "+r.Header.Get("X-Priority"))
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := &config.DestinationConfig{
+		Name:        "tickets",
+		URL:         server.URL,
+		Method:      "POST",
+		Format:      "json",
+		Engine:      "jq",
+		Transform:   `{summary: .alert.labels.alertname}`,
+		SplitAlerts: true,
+		BatchSize:   1,
+		URLTemplate: server.URL + `/projects/{{ .Labels.team }}/issues`,
+		HeadersTemplate: map[string]string{
+			"X-Priority": `{{ if eq .Labels.severity "critical" }}P1{{ else }}P3{{ end }}`,
+		},
+	}
+	cfg.SetDefaults()
+
+	handler, err := NewHTTPHandler(cfg, nil)
+	require.NoError(t, err)
+	defer handler.Close()
+
+	err = handler.Send(context.Background(), &alertmanager.WebhookPayload{
+		Status: "firing",
+		Alerts: []alertmanager.Alert{
+			{Fingerprint: "a", Labels: map[string]string{"alertname": "DiskFull", "team": "storage", "severity": "critical"}},
+			{Fingerprint: "b", Labels: map[string]string{"alertname": "SlowQuery", "team": "db", "severity": "warning"}},
+		},
+	})
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	sort.Strings(received)
+	assert.Equal(t, []string{
+		"POST /projects/db/issues P3",
+		"POST /projects/storage/issues P1",
+	}, received)
+}
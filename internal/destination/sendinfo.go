@@ -0,0 +1,140 @@
+package destination
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type sendInfoKey struct{}
+
+// SendInfo collects details of the HTTP requests made while sending a payload
+type SendInfo struct {
+	mu         sync.Mutex
+	requests   int
+	statusCode int
+	bodySize   int
+
+	transformDuration time.Duration
+	formatDuration    time.Duration
+	sendDuration      time.Duration
+
+	failedAlerts []int
+}
+
+// WithSendInfo returns a context that records sent requests into info
+func WithSendInfo(ctx context.Context, info *SendInfo) context.Context {
+	return context.WithValue(ctx, sendInfoKey{}, info)
+}
+
+// Requests returns the number of HTTP requests made
+func (s *SendInfo) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests
+}
+
+// StatusCode returns the status code of the last response, or 0 if none was received
+func (s *SendInfo) StatusCode() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.statusCode
+}
+
+// BodySize returns the total size of the rendered request bodies
+func (s *SendInfo) BodySize() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.bodySize
+}
+
+// TransformDuration returns the time spent running the transform engine
+func (s *SendInfo) TransformDuration() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.transformDuration
+}
+
+// FormatDuration returns the time spent encoding request bodies
+func (s *SendInfo) FormatDuration() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.formatDuration
+}
+
+// SendDuration returns the time spent waiting for the destination to respond
+func (s *SendInfo) SendDuration() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sendDuration
+}
+
+// FailedAlerts returns the indices of the payload alerts that were not delivered by a split send
+// that partially failed. The send reports no error in that case.
+func (s *SendInfo) FailedAlerts() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]int(nil), s.failedAlerts...)
+}
+
+// recordTransform adds transform time to the SendInfo of the context, if any
+func recordTransform(ctx context.Context, d time.Duration) {
+	info, ok := ctx.Value(sendInfoKey{}).(*SendInfo)
+	if !ok {
+		return
+	}
+
+	info.mu.Lock()
+	defer info.mu.Unlock()
+
+	info.transformDuration += d
+}
+
+// recordFormat adds format time to the SendInfo of the context, if any
+func recordFormat(ctx context.Context, d time.Duration) {
+	info, ok := ctx.Value(sendInfoKey{}).(*SendInfo)
+	if !ok {
+		return
+	}
+
+	info.mu.Lock()
+	defer info.mu.Unlock()
+
+	info.formatDuration += d
+}
+
+// recordSend adds a request to the SendInfo of the context, if any
+func recordSend(ctx context.Context, statusCode, bodySize int, d time.Duration) {
+	info, ok := ctx.Value(sendInfoKey{}).(*SendInfo)
+	if !ok {
+		return
+	}
+
+	info.mu.Lock()
+	defer info.mu.Unlock()
+
+	info.requests++
+	info.statusCode = statusCode
+	info.bodySize += bodySize
+	info.sendDuration += d
+}
+
+// recordFailedAlerts records the payload alerts a split send could not deliver in the SendInfo of the context, if any
+func recordFailedAlerts(ctx context.Context, indices []int) {
+	info, ok := ctx.Value(sendInfoKey{}).(*SendInfo)
+	if !ok {
+		return
+	}
+
+	info.mu.Lock()
+	defer info.mu.Unlock()
+
+	info.failedAlerts = append(info.failedAlerts, indices...)
+}
@@ -2,6 +2,7 @@ package destination
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -9,8 +10,9 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
 	"github.com/vitalvas/alertmanager-gateway/internal/config"
-	"github.com/vitalvas/alertmanager-gateway/internal/formatter"
+	"github.com/vitalvas/alertmanager-gateway/internal/tracing"
 	"github.com/vitalvas/alertmanager-gateway/internal/transform"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // SplitStrategy defines how alerts should be split and processed
@@ -121,18 +123,29 @@ func NewHTTPAlertProcessor(handler *HTTPHandler) *HTTPAlertProcessor {
 }
 
 // ProcessAlert processes a single alert
-func (p *HTTPAlertProcessor) ProcessAlert(ctx context.Context, alert *alertmanager.Alert, payload *alertmanager.WebhookPayload) error {
+func (p *HTTPAlertProcessor) ProcessAlert(ctx context.Context, alert *alertmanager.Alert, payload *alertmanager.WebhookPayload) (err error) {
+	ctx, span := tracing.Start(ctx, "split.alert", attribute.String("alert.fingerprint", alert.Fingerprint))
+	defer func() { tracing.End(span, err) }()
+
 	// All engines now support alert-specific transformation
-	transformed, err := p.engine.TransformAlert(alert, payload)
+	transformed, err := transformAlert(ctx, p.engine, alert, payload)
 	if err != nil {
 		return fmt.Errorf("failed to transform alert: %w", err)
 	}
 
-	return p.sendTransformed(ctx, transformed)
+	target, err := p.handler.request.Render(alert, payload)
+	if err != nil {
+		return err
+	}
+
+	return p.sendTransformed(ctx, transformed, target)
 }
 
 // ProcessBatch processes a batch of alerts
-func (p *HTTPAlertProcessor) ProcessBatch(ctx context.Context, alerts []alertmanager.Alert, payload *alertmanager.WebhookPayload) error {
+func (p *HTTPAlertProcessor) ProcessBatch(ctx context.Context, alerts []alertmanager.Alert, payload *alertmanager.WebhookPayload) (err error) {
+	ctx, span := tracing.Start(ctx, "split.batch", attribute.Int("alerts.count", len(alerts)))
+	defer func() { tracing.End(span, err) }()
+
 	// Create batch payload
 	batchPayload := &alertmanager.WebhookPayload{
 		Version:           payload.Version,
@@ -147,24 +160,33 @@ func (p *HTTPAlertProcessor) ProcessBatch(ctx context.Context, alerts []alertman
 		Alerts:            alerts,
 	}
 
-	transformed, err := p.engine.Transform(batchPayload)
+	transformed, err := transformPayload(ctx, p.engine, batchPayload)
 	if err != nil {
 		return fmt.Errorf("failed to transform batch payload: %w", err)
 	}
 
-	return p.sendTransformed(ctx, transformed)
+	target, err := p.handler.request.Render(nil, batchPayload)
+	if err != nil {
+		return err
+	}
+
+	return p.sendTransformed(ctx, transformed, target)
 }
 
-// sendTransformed sends the transformed data
-func (p *HTTPAlertProcessor) sendTransformed(ctx context.Context, transformed interface{}) error {
+// sendTransformed sends the transformed data to target
+func (p *HTTPAlertProcessor) sendTransformed(ctx context.Context, transformed interface{}, target *RequestTarget) error {
+	if p.config.OutputMode == config.OutputModeRequests {
+		return p.handler.sendRequests(ctx, transformed, target)
+	}
+
 	// Format the data
-	req, err := formatter.FormatData(formatter.OutputFormat(p.config.Format), transformed)
+	req, err := formatData(ctx, p.config.Format, transformed)
 	if err != nil {
 		return fmt.Errorf("failed to format data: %w", err)
 	}
 
 	// Send the request
-	resp, err := p.handler.sendRequest(ctx, req)
+	resp, err := p.handler.sendRequest(ctx, req, target)
 	if err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
 	}
@@ -191,6 +213,12 @@ func (s *AlertSplitter) Split(ctx context.Context, payload *alertmanager.Webhook
 		return result
 	}
 
+	ctx, span := tracing.Start(ctx, "split",
+		attribute.String("split.strategy", s.getStrategyName()),
+		attribute.Int("alerts.count", len(payload.Alerts)),
+	)
+	defer span.End()
+
 	switch s.config.Strategy {
 	case SplitStrategySequential:
 		s.processSequential(ctx, payload, processor, result)
@@ -217,6 +245,11 @@ func (s *AlertSplitter) Split(ctx context.Context, payload *alertmanager.Webhook
 	result.SuccessCount = successCount
 	result.FailureCount = failureCount
 
+	span.SetAttributes(
+		attribute.Int("split.success_count", successCount),
+		attribute.Int("split.failure_count", failureCount),
+	)
+
 	s.logger.WithFields(logrus.Fields{
 		"strategy":      s.getStrategyName(),
 		"total_alerts":  result.TotalAlerts,
@@ -252,9 +285,19 @@ func (s *AlertSplitter) processSequential(ctx context.Context, payload *alertman
 	}
 }
 
+// slotsKey is the context key of the semaphore shared by the tasks of a split
+type slotsKey struct{}
+
+// withSlots returns a context carrying the semaphore of a split, so Each called by its
+// tasks stays within the same parallel request limit
+func withSlots(ctx context.Context, slots chan struct{}) context.Context {
+	return context.WithValue(ctx, slotsKey{}, slots)
+}
+
 // processParallel processes alerts concurrently
 func (s *AlertSplitter) processParallel(ctx context.Context, payload *alertmanager.WebhookPayload, processor AlertProcessor, result *SplitResult) {
 	semaphore := make(chan struct{}, s.config.ParallelRequests)
+	ctx = withSlots(ctx, semaphore)
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 
@@ -327,6 +370,7 @@ func (s *AlertSplitter) processBatch(ctx context.Context, payload *alertmanager.
 func (s *AlertSplitter) processBatchParallel(ctx context.Context, payload *alertmanager.WebhookPayload, processor AlertProcessor, result *SplitResult) {
 	alerts := payload.Alerts
 	semaphore := make(chan struct{}, s.config.ParallelRequests)
+	ctx = withSlots(ctx, semaphore)
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 
@@ -373,6 +417,60 @@ func (s *AlertSplitter) processBatchParallel(ctx context.Context, payload *alert
 	wg.Wait()
 }
 
+// errSkipped marks the tasks Each didn't run after an earlier task failed
+var errSkipped = errors.New("skipped after an earlier request failed")
+
+// Each runs fn for the indexes 0 to n-1 with the parallel request limit of the destination
+// and returns the error of each. Without parallel requests, tasks run in order and stop
+// at the first failure, so later tasks can depend on earlier ones.
+//
+// With parallel requests, the caller runs tasks itself and hands them to other goroutines
+// while free slots remain. Called from a split task, the slots are those of the split, so
+// the limit covers all requests of the split.
+func (s *AlertSplitter) Each(ctx context.Context, n int, fn func(ctx context.Context, i int) error) []error {
+	errs := make([]error, n)
+
+	if s.config.ParallelRequests <= 1 {
+		for i := range n {
+			if errs[i] = fn(ctx, i); errs[i] != nil {
+				for j := i + 1; j < n; j++ {
+					errs[j] = errSkipped
+				}
+				break
+			}
+		}
+		return errs
+	}
+
+	// Outside a split the caller takes one of the slots itself
+	slots, ok := ctx.Value(slotsKey{}).(chan struct{})
+	if !ok {
+		slots = make(chan struct{}, s.config.ParallelRequests-1)
+	}
+
+	var wg sync.WaitGroup
+
+	for i := range n {
+		select {
+		case slots <- struct{}{}:
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-slots }()
+
+				errs[i] = fn(ctx, i)
+			}()
+		default:
+			// No free slot, so the task runs in the slot of the caller
+			errs[i] = fn(ctx, i)
+		}
+	}
+
+	wg.Wait()
+
+	return errs
+}
+
 // getStrategyName returns a human-readable strategy name
 func (s *AlertSplitter) getStrategyName() string {
 	switch s.config.Strategy {
@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
 	"github.com/vitalvas/alertmanager-gateway/internal/config"
+	"github.com/vitalvas/alertmanager-gateway/internal/transform"
 )
 
 // MockAlertProcessor implements AlertProcessor for testing
@@ -345,6 +346,48 @@ func TestAlertSplitter_MaxConcurrency(t *testing.T) {
 	assert.Equal(t, 10, splitter.config.MaxConcurrency)
 }
 
+func TestAlertSplitter_Each(t *testing.T) {
+	logger := logrus.NewEntry(logrus.New())
+	failing := fmt.Errorf("boom")
+
+	t.Run("sequential stops at the first failure", func(t *testing.T) {
+		splitter := NewAlertSplitter(&config.DestinationConfig{ParallelRequests: 1}, logger)
+
+		var calls []int
+		errs := splitter.Each(context.Background(), 4, func(_ context.Context, i int) error {
+			calls = append(calls, i)
+			if i == 1 {
+				return failing
+			}
+			return nil
+		})
+
+		assert.Equal(t, []int{0, 1}, calls)
+		assert.Equal(t, []error{nil, failing, errSkipped, errSkipped}, errs)
+	})
+
+	t.Run("parallel runs every task", func(t *testing.T) {
+		splitter := NewAlertSplitter(&config.DestinationConfig{ParallelRequests: 3}, logger)
+
+		var (
+			mu    sync.Mutex
+			calls int
+		)
+		errs := splitter.Each(context.Background(), 5, func(_ context.Context, i int) error {
+			mu.Lock()
+			calls++
+			mu.Unlock()
+			if i == 1 {
+				return failing
+			}
+			return nil
+		})
+
+		assert.Equal(t, 5, calls)
+		assert.Equal(t, []error{nil, failing, nil, nil, nil}, errs)
+	})
+}
+
 func TestAlertSplitter_StrategyNames(t *testing.T) {
 	logger := logrus.NewEntry(logrus.New())
 
@@ -475,3 +518,72 @@ func createTestPayload(alertCount int) *alertmanager.WebhookPayload {
 		Alerts:      alerts,
 	}
 }
+
+// transformingProcessor transforms and formats alerts without sending them
+type transformingProcessor struct {
+	engine transform.Engine
+}
+
+func (p *transformingProcessor) ProcessAlert(ctx context.Context, alert *alertmanager.Alert, payload *alertmanager.WebhookPayload) error {
+	transformed, err := transformAlert(ctx, p.engine, alert, payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = formatData(ctx, "json", transformed)
+	return err
+}
+
+func (p *transformingProcessor) ProcessBatch(ctx context.Context, alerts []alertmanager.Alert, payload *alertmanager.WebhookPayload) error {
+	batch := *payload
+	batch.Alerts = alerts
+
+	transformed, err := transformPayload(ctx, p.engine, &batch)
+	if err != nil {
+		return err
+	}
+
+	_, err = formatData(ctx, "json", transformed)
+	return err
+}
+
+func BenchmarkAlertSplitter_ParallelJQ(b *testing.B) {
+	engine, err := transform.NewJQEngine(`{
+		routing_key: "key",
+		event_action: (if .alert.status == "firing" then "trigger" else "resolve" end),
+		dedup_key: .alert.fingerprint,
+		payload: {summary: .alert.annotations.summary, source: .alert.labels.instance, severity: .payload.commonLabels.severity}
+	}`)
+	require.NoError(b, err)
+
+	payload := &alertmanager.WebhookPayload{
+		Version:      "4",
+		GroupKey:     "test-group",
+		Status:       "firing",
+		CommonLabels: map[string]string{"alertname": "HighCPU", "severity": "critical"},
+		Alerts:       make([]alertmanager.Alert, 500),
+	}
+	for i := range payload.Alerts {
+		payload.Alerts[i] = alertmanager.Alert{
+			Status:      "firing",
+			Labels:      map[string]string{"alertname": "HighCPU", "instance": fmt.Sprintf("server-%d", i), "severity": "critical"},
+			Annotations: map[string]string{"summary": fmt.Sprintf("CPU usage is high on server-%d", i)},
+			StartsAt:    time.Now(),
+			Fingerprint: fmt.Sprintf("fp-%d", i),
+		}
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+	splitter := NewAlertSplitter(&config.DestinationConfig{SplitAlerts: true, ParallelRequests: 10}, logger.WithField("test", "bench"))
+	processor := &transformingProcessor{engine: engine}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		result := splitter.Split(context.Background(), payload, processor)
+		if result.FailureCount > 0 {
+			b.Fatal(result.Errors[0])
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package destination
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// LatencyBucketsMS are the upper bounds of the request latency histogram, in milliseconds
+var LatencyBucketsMS = []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// Stats keeps live request counters and latency histograms per destination
+type Stats struct {
+	mu           sync.Mutex
+	destinations map[string]*destinationStats
+	now          func() time.Time
+}
+
+// destinationStats are the counters of a single destination
+type destinationStats struct {
+	requests     int64
+	successes    int64
+	errors       int64
+	totalLatency time.Duration
+	buckets      []int64
+	lastRequest  time.Time
+	lastError    string
+	lastErrorAt  time.Time
+	since        time.Time
+}
+
+// LatencyBucket is a cumulative histogram bucket: the number of requests that took at most LEMS milliseconds
+type LatencyBucket struct {
+	LEMS  float64 `json:"le_ms"`
+	Count int64   `json:"count"`
+}
+
+// StatsSnapshot is a point-in-time copy of the statistics of a destination
+type StatsSnapshot struct {
+	Name            string
+	Requests        int64
+	Successes       int64
+	Errors          int64
+	AverageLatency  time.Duration
+	LatencyBuckets  []LatencyBucket
+	LastRequestTime time.Time
+	LastError       string
+	LastErrorTime   time.Time
+	Since           time.Time
+}
+
+// NewStats creates an empty statistics collector
+func NewStats() *Stats {
+	return &Stats{
+		destinations: make(map[string]*destinationStats),
+		now:          time.Now,
+	}
+}
+
+// Record adds an HTTP request made to a destination. All methods are nil-safe so
+// handlers created without a collector skip recording.
+func (s *Stats) Record(name string, latency time.Duration, err error) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	stats := s.get(name, now)
+
+	stats.requests++
+	stats.totalLatency += latency
+	stats.lastRequest = now
+
+	if err != nil {
+		stats.errors++
+		stats.lastError = err.Error()
+		stats.lastErrorAt = now
+	} else {
+		stats.successes++
+	}
+
+	ms := float64(latency) / float64(time.Millisecond)
+	for i, bound := range LatencyBucketsMS {
+		if ms <= bound {
+			stats.buckets[i]++
+		}
+	}
+}
+
+// Snapshot returns the statistics of a destination. Destinations without requests
+// since the last reset report zero counters.
+func (s *Stats) Snapshot(name string) StatsSnapshot {
+	if s == nil {
+		return StatsSnapshot{Name: name, LatencyBuckets: emptyBuckets()}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats, ok := s.destinations[name]
+	if !ok {
+		return StatsSnapshot{Name: name, LatencyBuckets: emptyBuckets()}
+	}
+
+	return stats.snapshot(name)
+}
+
+// Snapshots returns the statistics of every destination with recorded requests, sorted by name
+func (s *Stats) Snapshots() []StatsSnapshot {
+	if s == nil {
+		return []StatsSnapshot{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshots := make([]StatsSnapshot, 0, len(s.destinations))
+	for name, stats := range s.destinations {
+		snapshots = append(snapshots, stats.snapshot(name))
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Name < snapshots[j].Name
+	})
+
+	return snapshots
+}
+
+// Reset clears the statistics of a destination
+func (s *Stats) Reset(name string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.destinations, name)
+}
+
+// ResetAll clears the statistics of every destination
+func (s *Stats) ResetAll() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.destinations = make(map[string]*destinationStats)
+}
+
+// get returns the counters of a destination, creating them on first use
+func (s *Stats) get(name string, now time.Time) *destinationStats {
+	stats, ok := s.destinations[name]
+	if !ok {
+		stats = &destinationStats{
+			buckets: make([]int64, len(LatencyBucketsMS)),
+			since:   now,
+		}
+		s.destinations[name] = stats
+	}
+
+	return stats
+}
+
+// snapshot copies the counters of a destination
+func (d *destinationStats) snapshot(name string) StatsSnapshot {
+	snapshot := StatsSnapshot{
+		Name:            name,
+		Requests:        d.requests,
+		Successes:       d.successes,
+		Errors:          d.errors,
+		LatencyBuckets:  make([]LatencyBucket, len(LatencyBucketsMS)),
+		LastRequestTime: d.lastRequest,
+		LastError:       d.lastError,
+		LastErrorTime:   d.lastErrorAt,
+		Since:           d.since,
+	}
+
+	if d.requests > 0 {
+		snapshot.AverageLatency = d.totalLatency / time.Duration(d.requests)
+	}
+
+	for i, bound := range LatencyBucketsMS {
+		snapshot.LatencyBuckets[i] = LatencyBucket{LEMS: bound, Count: d.buckets[i]}
+	}
+
+	return snapshot
+}
+
+// emptyBuckets returns a histogram without requests
+func emptyBuckets() []LatencyBucket {
+	buckets := make([]LatencyBucket, len(LatencyBucketsMS))
+	for i, bound := range LatencyBucketsMS {
+		buckets[i] = LatencyBucket{LEMS: bound}
+	}
+
+	return buckets
+}
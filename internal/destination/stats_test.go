@@ -0,0 +1,121 @@
+package destination
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+	"github.com/vitalvas/alertmanager-gateway/internal/config"
+)
+
+func TestStats_Record(t *testing.T) {
+	stats := NewStats()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	stats.now = func() time.Time { return now }
+
+	stats.Record("chat", 20*time.Millisecond, nil)
+	stats.Record("chat", 40*time.Millisecond, errors.New("unexpected status: 500 Internal Server Error"))
+	stats.Record("chat", 30*time.Second, nil)
+	stats.Record("pager", time.Millisecond, nil)
+
+	snapshot := stats.Snapshot("chat")
+	assert.Equal(t, "chat", snapshot.Name)
+	assert.Equal(t, int64(3), snapshot.Requests)
+	assert.Equal(t, int64(2), snapshot.Successes)
+	assert.Equal(t, int64(1), snapshot.Errors)
+	assert.Equal(t, (20*time.Millisecond+40*time.Millisecond+30*time.Second)/3, snapshot.AverageLatency)
+	assert.Equal(t, "unexpected status: 500 Internal Server Error", snapshot.LastError)
+	assert.Equal(t, now, snapshot.LastErrorTime)
+	assert.Equal(t, now, snapshot.LastRequestTime)
+	assert.Equal(t, now, snapshot.Since)
+
+	counts := make(map[float64]int64)
+	for _, bucket := range snapshot.LatencyBuckets {
+		counts[bucket.LEMS] = bucket.Count
+	}
+	assert.Equal(t, int64(0), counts[10])
+	assert.Equal(t, int64(1), counts[25])
+	assert.Equal(t, int64(2), counts[50])
+	assert.Equal(t, int64(2), counts[10000])
+
+	snapshots := stats.Snapshots()
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, "chat", snapshots[0].Name)
+	assert.Equal(t, "pager", snapshots[1].Name)
+}
+
+func TestStats_Reset(t *testing.T) {
+	stats := NewStats()
+	stats.Record("chat", time.Millisecond, nil)
+	stats.Record("pager", time.Millisecond, nil)
+
+	stats.Reset("chat")
+	assert.Equal(t, int64(0), stats.Snapshot("chat").Requests)
+	assert.Len(t, stats.Snapshot("chat").LatencyBuckets, len(LatencyBucketsMS))
+	assert.Equal(t, int64(1), stats.Snapshot("pager").Requests)
+
+	stats.ResetAll()
+	assert.Empty(t, stats.Snapshots())
+}
+
+func TestStats_Nil(t *testing.T) {
+	var stats *Stats
+
+	assert.NotPanics(t, func() {
+		stats.Record("chat", time.Millisecond, nil)
+		stats.Reset("chat")
+		stats.ResetAll()
+	})
+	assert.Equal(t, int64(0), stats.Snapshot("chat").Requests)
+	assert.Empty(t, stats.Snapshots())
+}
+
+func TestHTTPHandler_Stats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	stats := NewStats()
+	clientConfig := DefaultHTTPClientConfig()
+	clientConfig.Stats = stats
+
+	newHandler := func(name, url string) *HTTPHandler {
+		handler, err := NewHTTPHandler(&config.DestinationConfig{
+			Name:     name,
+			URL:      url,
+			Method:   "POST",
+			Format:   "json",
+			Engine:   "go-template",
+			Template: `{"status": "{{ .Status }}"}`,
+		}, clientConfig)
+		require.NoError(t, err)
+		return handler
+	}
+
+	payload := &alertmanager.WebhookPayload{Version: "4", Status: "firing"}
+
+	ok := newHandler("ok", server.URL)
+	defer ok.Close()
+	require.NoError(t, ok.Send(context.Background(), payload))
+
+	failing := newHandler("failing", server.URL+"/fail")
+	defer failing.Close()
+	require.Error(t, failing.Send(context.Background(), payload))
+
+	assert.Equal(t, int64(1), stats.Snapshot("ok").Successes)
+
+	snapshot := stats.Snapshot("failing")
+	assert.Equal(t, int64(1), snapshot.Errors)
+	assert.Contains(t, snapshot.LastError, "502")
+}
@@ -0,0 +1,57 @@
+package destination
+
+import (
+	"context"
+	"time"
+
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+	"github.com/vitalvas/alertmanager-gateway/internal/formatter"
+	"github.com/vitalvas/alertmanager-gateway/internal/tracing"
+	"github.com/vitalvas/alertmanager-gateway/internal/transform"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// transformPayload runs the engine on a payload within a span
+func transformPayload(ctx context.Context, engine transform.Engine, payload *alertmanager.WebhookPayload) (interface{}, error) {
+	_, span := tracing.Start(ctx, "transform",
+		attribute.String("transform.engine", engine.Name()),
+		attribute.Int("alerts.count", len(payload.Alerts)),
+	)
+
+	start := time.Now()
+	transformed, err := engine.Transform(payload)
+	recordTransform(ctx, time.Since(start))
+	tracing.End(span, err)
+
+	return transformed, err
+}
+
+// transformAlert runs the engine on a single alert within a span
+func transformAlert(ctx context.Context, engine transform.Engine, alert *alertmanager.Alert, payload *alertmanager.WebhookPayload) (interface{}, error) {
+	_, span := tracing.Start(ctx, "transform_alert",
+		attribute.String("transform.engine", engine.Name()),
+		attribute.String("alert.fingerprint", alert.Fingerprint),
+	)
+
+	start := time.Now()
+	transformed, err := engine.TransformAlert(alert, payload)
+	recordTransform(ctx, time.Since(start))
+	tracing.End(span, err)
+
+	return transformed, err
+}
+
+// formatData formats transformed data into a request within a span
+func formatData(ctx context.Context, format string, data interface{}) (*formatter.Request, error) {
+	_, span := tracing.Start(ctx, "format", attribute.String("format", format))
+
+	start := time.Now()
+	req, err := formatter.FormatData(formatter.OutputFormat(format), data)
+	recordFormat(ctx, time.Since(start))
+	if err == nil {
+		span.SetAttributes(attribute.Int("body.size", len(req.Body)))
+	}
+	tracing.End(span, err)
+
+	return req, err
+}
@@ -0,0 +1,264 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+	"github.com/vitalvas/alertmanager-gateway/internal/transform"
+)
+
+// MatchType is the comparison operator of a matcher
+type MatchType string
+
+const (
+	MatchEqual     MatchType = "="
+	MatchNotEqual  MatchType = "!="
+	MatchRegexp    MatchType = "=~"
+	MatchNotRegexp MatchType = "!~"
+)
+
+// Field prefixes for selecting alert data other than labels
+const (
+	labelsPrefix      = "labels."
+	annotationsPrefix = "annotations."
+)
+
+// Matcher compares one alert field against a value using Alertmanager matcher syntax
+type Matcher struct {
+	Field string
+	Type  MatchType
+	Value string
+
+	re *regexp.Regexp
+}
+
+// ParseMatcher parses a matcher such as severity="critical" or annotations.runbook=~".+"
+func ParseMatcher(s string) (*Matcher, error) {
+	s = strings.TrimSpace(s)
+
+	idx := strings.IndexAny(s, "=!")
+	if idx == -1 {
+		return nil, fmt.Errorf("matcher %q: missing operator (=, !=, =~, !~)", s)
+	}
+
+	field := strings.TrimSpace(s[:idx])
+	if field == "" {
+		return nil, fmt.Errorf("matcher %q: field name is required", s)
+	}
+
+	var matchType MatchType
+	switch rest := s[idx:]; {
+	case strings.HasPrefix(rest, string(MatchNotRegexp)):
+		matchType = MatchNotRegexp
+	case strings.HasPrefix(rest, string(MatchRegexp)):
+		matchType = MatchRegexp
+	case strings.HasPrefix(rest, string(MatchNotEqual)):
+		matchType = MatchNotEqual
+	case strings.HasPrefix(rest, string(MatchEqual)):
+		matchType = MatchEqual
+	default:
+		return nil, fmt.Errorf("matcher %q: missing operator (=, !=, =~, !~)", s)
+	}
+
+	value, err := unquote(strings.TrimSpace(s[idx+len(matchType):]))
+	if err != nil {
+		return nil, fmt.Errorf("matcher %q: %w", s, err)
+	}
+
+	return NewMatcher(field, matchType, value)
+}
+
+// NewMatcher creates a matcher, compiling the value for regular expression operators
+func NewMatcher(field string, matchType MatchType, value string) (*Matcher, error) {
+	m := &Matcher{
+		Field: field,
+		Type:  matchType,
+		Value: value,
+	}
+
+	switch matchType {
+	case MatchEqual, MatchNotEqual:
+	case MatchRegexp, MatchNotRegexp:
+		// Anchor the expression like Alertmanager does
+		re, err := regexp.Compile("^(?:" + value + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("matcher %s: invalid regular expression: %w", field, err)
+		}
+		m.re = re
+	default:
+		return nil, fmt.Errorf("matcher %s: unknown match type %q", field, matchType)
+	}
+
+	return m, nil
+}
+
+// Matches reports whether the alert satisfies the matcher
+func (m *Matcher) Matches(alert *alertmanager.Alert) bool {
+	value := fieldValue(alert, m.Field)
+
+	switch m.Type {
+	case MatchEqual:
+		return value == m.Value
+	case MatchNotEqual:
+		return value != m.Value
+	case MatchRegexp:
+		return m.re.MatchString(value)
+	case MatchNotRegexp:
+		return !m.re.MatchString(value)
+	}
+
+	return false
+}
+
+// String returns the matcher in Alertmanager syntax
+func (m *Matcher) String() string {
+	return fmt.Sprintf("%s%s%q", m.Field, m.Type, m.Value)
+}
+
+// Filter selects alerts using include and exclude matchers and a CEL expression.
+// An alert is kept when it matches all include matchers, none of the exclude matchers
+// and the expression evaluates to true.
+type Filter struct {
+	include    []*Matcher
+	exclude    []*Matcher
+	expression cel.Program
+}
+
+// New creates a filter from include and exclude matcher expressions and an optional CEL expression
+func New(include, exclude []string, expression string) (*Filter, error) {
+	f := &Filter{}
+
+	for _, s := range include {
+		m, err := ParseMatcher(s)
+		if err != nil {
+			return nil, fmt.Errorf("include: %w", err)
+		}
+		f.include = append(f.include, m)
+	}
+
+	for _, s := range exclude {
+		m, err := ParseMatcher(s)
+		if err != nil {
+			return nil, fmt.Errorf("exclude: %w", err)
+		}
+		f.exclude = append(f.exclude, m)
+	}
+
+	if expression != "" {
+		program, err := compileExpression(expression)
+		if err != nil {
+			return nil, fmt.Errorf("expression: %w", err)
+		}
+		f.expression = program
+	}
+
+	return f, nil
+}
+
+// compileExpression type-checks a CEL expression against the alert and payload variables
+// of the cel engine and requires a bool result
+func compileExpression(expression string) (cel.Program, error) {
+	env, err := transform.CELEnvironment()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cel environment: %w", err)
+	}
+
+	ast, issues := env.Compile(expression)
+	if issues.Err() != nil {
+		return nil, issues.Err()
+	}
+
+	if !ast.OutputType().IsExactType(cel.BoolType) {
+		return nil, fmt.Errorf("must return a bool, got %s", ast.OutputType())
+	}
+
+	return env.Program(ast, cel.CostLimit(transform.CELCostLimit))
+}
+
+// Empty reports whether the filter has no matchers and no expression
+func (f *Filter) Empty() bool {
+	return len(f.include) == 0 && len(f.exclude) == 0 && f.expression == nil
+}
+
+// Match reports whether the alert of payload passes the filter. When the expression
+// fails to evaluate, the alert is kept and the error returned.
+func (f *Filter) Match(alert *alertmanager.Alert, payload *alertmanager.WebhookPayload) (bool, error) {
+	for _, m := range f.include {
+		if !m.Matches(alert) {
+			return false, nil
+		}
+	}
+
+	for _, m := range f.exclude {
+		if m.Matches(alert) {
+			return false, nil
+		}
+	}
+
+	if f.expression == nil {
+		return true, nil
+	}
+
+	val, _, err := f.expression.Eval(map[string]interface{}{
+		"payload": payload,
+		"alert":   alert,
+	})
+	if err != nil {
+		return true, fmt.Errorf("alert %s: %w", alert.Fingerprint, err)
+	}
+
+	return val == types.True, nil
+}
+
+// Apply returns the alerts of payload that pass the filter. Alerts whose expression fails
+// to evaluate are kept rather than dropped, and the errors are returned with them.
+func (f *Filter) Apply(payload *alertmanager.WebhookPayload) ([]alertmanager.Alert, error) {
+	kept := make([]alertmanager.Alert, 0, len(payload.Alerts))
+	var errs []error
+
+	for i := range payload.Alerts {
+		match, err := f.Match(&payload.Alerts[i], payload)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if match {
+			kept = append(kept, payload.Alerts[i])
+		}
+	}
+
+	return kept, errors.Join(errs...)
+}
+
+// fieldValue resolves a matcher field against an alert.
+// Supported fields are status, labels.<name>, annotations.<name> and bare label names.
+func fieldValue(alert *alertmanager.Alert, field string) string {
+	switch {
+	case field == "status":
+		return alert.Status
+	case strings.HasPrefix(field, labelsPrefix):
+		return alert.GetLabelValue(strings.TrimPrefix(field, labelsPrefix))
+	case strings.HasPrefix(field, annotationsPrefix):
+		return alert.GetAnnotationValue(strings.TrimPrefix(field, annotationsPrefix))
+	default:
+		return alert.GetLabelValue(field)
+	}
+}
+
+// unquote removes surrounding double quotes from a matcher value
+func unquote(value string) (string, error) {
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid quoted value: %w", err)
+		}
+		return unquoted, nil
+	}
+
+	return value, nil
+}
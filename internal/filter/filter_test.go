@@ -0,0 +1,190 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+)
+
+func testAlert() *alertmanager.Alert {
+	return &alertmanager.Alert{
+		Status: "firing",
+		Labels: map[string]string{
+			"alertname": "HighCPU",
+			"severity":  "critical",
+			"team":      "db",
+		},
+		Annotations: map[string]string{
+			"summary": "CPU usage is high",
+		},
+	}
+}
+
+func TestParseMatcher(t *testing.T) {
+	tests := []struct {
+		input     string
+		field     string
+		matchType MatchType
+		value     string
+		wantErr   bool
+	}{
+		{input: `severity="critical"`, field: "severity", matchType: MatchEqual, value: "critical"},
+		{input: `severity = critical`, field: "severity", matchType: MatchEqual, value: "critical"},
+		{input: `status!="resolved"`, field: "status", matchType: MatchNotEqual, value: "resolved"},
+		{input: `labels.team=~"db|web"`, field: "labels.team", matchType: MatchRegexp, value: "db|web"},
+		{input: `annotations.runbook!~".+"`, field: "annotations.runbook", matchType: MatchNotRegexp, value: ".+"},
+		{input: `summary="a=~b"`, field: "summary", matchType: MatchEqual, value: "a=~b"},
+		{input: `severity`, wantErr: true},
+		{input: `="critical"`, wantErr: true},
+		{input: `team=~"("`, wantErr: true},
+		{input: `team="unterminated\"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			m, err := ParseMatcher(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.field, m.Field)
+			assert.Equal(t, tt.matchType, m.Type)
+			assert.Equal(t, tt.value, m.Value)
+		})
+	}
+}
+
+func TestMatcher_Matches(t *testing.T) {
+	tests := []struct {
+		matcher string
+		want    bool
+	}{
+		{`severity="critical"`, true},
+		{`labels.severity="warning"`, false},
+		{`status="firing"`, true},
+		{`status!="firing"`, false},
+		{`team=~"d.*"`, true},
+		{`team=~"d"`, false}, // regular expressions are anchored
+		{`team!~"web|api"`, true},
+		{`annotations.summary=~".*CPU.*"`, true},
+		{`annotations.runbook=""`, true},
+		{`missing!=""`, false},
+	}
+
+	alert := testAlert()
+	for _, tt := range tests {
+		t.Run(tt.matcher, func(t *testing.T) {
+			m, err := ParseMatcher(tt.matcher)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, m.Matches(alert))
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		want    bool
+	}{
+		{name: "empty filter keeps alert", want: true},
+		{name: "all include matchers match", include: []string{`severity="critical"`, `team="db"`}, want: true},
+		{name: "one include matcher fails", include: []string{`severity="critical"`, `team="web"`}, want: false},
+		{name: "exclude matcher matches", exclude: []string{`team="db"`}, want: false},
+		{name: "any exclude matcher drops", exclude: []string{`team="web"`, `status="firing"`}, want: false},
+		{name: "include and no exclude match", include: []string{`severity=~"critical|warning"`}, exclude: []string{`team="web"`}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := New(tt.include, tt.exclude, "")
+			require.NoError(t, err)
+
+			match, err := f.Match(testAlert(), &alertmanager.WebhookPayload{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, match)
+		})
+	}
+}
+
+func TestFilter_Apply(t *testing.T) {
+	f, err := New(nil, []string{`severity="info"`}, "")
+	require.NoError(t, err)
+	assert.False(t, f.Empty())
+
+	info := *testAlert()
+	info.Labels = map[string]string{"alertname": "Info", "severity": "info"}
+
+	kept, err := f.Apply(&alertmanager.WebhookPayload{Alerts: []alertmanager.Alert{*testAlert(), info}})
+	require.NoError(t, err)
+	require.Len(t, kept, 1)
+	assert.Equal(t, "HighCPU", kept[0].GetAlertName())
+}
+
+func TestFilter_Expression(t *testing.T) {
+	payload := &alertmanager.WebhookPayload{Receiver: "pager", Alerts: []alertmanager.Alert{*testAlert()}}
+
+	tests := []struct {
+		name       string
+		include    []string
+		expression string
+		want       bool
+	}{
+		{name: "label comparison", expression: `alert.labels["severity"] == "critical"`, want: true},
+		{name: "annotation check", expression: `alert.annotations["summary"].contains("disk")`, want: false},
+		{name: "payload field", expression: `payload.receiver == "pager" && alert.status == "firing"`, want: true},
+		{name: "optional label", expression: `alert.labels[?"env"].orValue("prod") == "prod"`, want: true},
+		{name: "matchers apply first", include: []string{`team="web"`}, expression: `true`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := New(tt.include, nil, tt.expression)
+			require.NoError(t, err)
+			assert.False(t, f.Empty())
+
+			match, err := f.Match(&payload.Alerts[0], payload)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, match)
+		})
+	}
+}
+
+func TestFilter_ExpressionErrorKeepsAlert(t *testing.T) {
+	f, err := New(nil, nil, `alert.labels["env"] == "staging"`)
+	require.NoError(t, err)
+
+	payload := &alertmanager.WebhookPayload{Alerts: []alertmanager.Alert{*testAlert()}}
+
+	kept, err := f.Apply(payload)
+	assert.Error(t, err)
+	assert.Len(t, kept, 1)
+}
+
+func TestNew_InvalidMatcher(t *testing.T) {
+	_, err := New([]string{"severity"}, nil, "")
+	assert.ErrorContains(t, err, "include")
+
+	_, err = New(nil, []string{`team=~"["`}, "")
+	assert.ErrorContains(t, err, "exclude")
+}
+
+func TestNew_InvalidExpression(t *testing.T) {
+	tests := map[string]string{
+		"syntax error":  `alert.labels[`,
+		"unknown field": `alert.lables["severity"] == "critical"`,
+		"non-bool":      `alert.labels["severity"]`,
+	}
+
+	for name, expression := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := New(nil, nil, expression)
+			assert.ErrorContains(t, err, "expression")
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vitalvas/alertmanager-gateway/internal/config"
+)
+
+// probeTick is how often the prober looks for destinations due for a check
+var probeTick = time.Second
+
+// Result is the outcome of the latest reachability check of a destination
+type Result struct {
+	URL        string    `json:"url"`
+	Reachable  bool      `json:"reachable"`
+	StatusCode int       `json:"status_code,omitempty"`
+	LatencyMS  int64     `json:"latency_ms"`
+	Error      string    `json:"error,omitempty"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// Prober periodically checks that destinations with a health_check are reachable
+type Prober struct {
+	targets func() []config.DestinationConfig
+	client  *http.Client
+	logger  *logrus.Entry
+
+	mu      sync.RWMutex
+	results map[string]Result
+	running map[string]bool
+
+	done     chan struct{}
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+}
+
+// NewProber creates a prober for the destinations returned by targets
+func NewProber(targets func() []config.DestinationConfig, logger *logrus.Logger) *Prober {
+	return &Prober{
+		targets: targets,
+		client:  &http.Client{},
+		logger:  logger.WithField("component", "health"),
+		results: make(map[string]Result),
+		running: make(map[string]bool),
+		done:    make(chan struct{}),
+	}
+}
+
+// Start runs checks in the background until Stop is called
+func (p *Prober) Start() {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(probeTick)
+		defer ticker.Stop()
+
+		p.checkDue(time.Now())
+
+		for {
+			select {
+			case <-p.done:
+				return
+			case now := <-ticker.C:
+				p.checkDue(now)
+			}
+		}
+	}()
+}
+
+// Stop stops the background checks and waits for running checks to finish
+func (p *Prober) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.done)
+	})
+	p.wg.Wait()
+}
+
+// Result returns the latest check result of a destination
+func (p *Prober) Result(name string) (Result, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	result, ok := p.results[name]
+	return result, ok
+}
+
+// Initialized reports whether every probed destination has been checked at least once
+func (p *Prober) Initialized() bool {
+	targets := p.targets()
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for _, dest := range targets {
+		if !probed(&dest) {
+			continue
+		}
+		if _, ok := p.results[dest.Name]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkDue starts checks of destinations whose interval elapsed and forgets removed destinations
+func (p *Prober) checkDue(now time.Time) {
+	targets := p.targets()
+	present := make(map[string]bool, len(targets))
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i := range targets {
+		dest := targets[i]
+		if !probed(&dest) {
+			continue
+		}
+		present[dest.Name] = true
+
+		if p.running[dest.Name] {
+			continue
+		}
+		if last, ok := p.results[dest.Name]; ok && last.URL == dest.HealthCheck.URL && now.Sub(last.CheckedAt) < dest.HealthCheck.Interval {
+			continue
+		}
+
+		p.running[dest.Name] = true
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+
+			result := p.check(dest.HealthCheck)
+
+			p.mu.Lock()
+			p.results[dest.Name] = result
+			delete(p.running, dest.Name)
+			p.mu.Unlock()
+
+			if !result.Reachable {
+				p.logger.WithFields(logrus.Fields{
+					"destination": dest.Name,
+					"url":         result.URL,
+					"error":       result.Error,
+				}).Warn("Destination health check failed")
+			}
+		}()
+	}
+
+	for name := range p.results {
+		if !present[name] {
+			delete(p.results, name)
+		}
+	}
+}
+
+// check sends a single probe request. Any response below 500 counts as reachable, since
+// webhook endpoints often reject HEAD or GET requests without being unavailable.
+func (p *Prober) check(cfg config.HealthCheckConfig) (result Result) {
+	result.URL = cfg.URL
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	defer func() {
+		result.LatencyMS = time.Since(start).Milliseconds()
+		result.CheckedAt = time.Now().UTC()
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, cfg.Method, cfg.URL, nil)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to create request: %v", err)
+		return result
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	result.StatusCode = resp.StatusCode
+	result.Reachable = resp.StatusCode < http.StatusInternalServerError
+	if !result.Reachable {
+		result.Error = fmt.Sprintf("unexpected status: %s", resp.Status)
+	}
+
+	return result
+}
+
+// probed reports whether a destination has an active check
+func probed(dest *config.DestinationConfig) bool {
+	return dest.Enabled && dest.HealthCheck.URL != ""
+}
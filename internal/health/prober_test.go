@@ -0,0 +1,163 @@
+package health
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/alertmanager-gateway/internal/config"
+)
+
+func TestProber_Check(t *testing.T) {
+	var mu sync.Mutex
+	methods := make([]string, 0)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		methods = append(methods, r.Method)
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusOK)
+		case "/not-allowed":
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name          string
+		url           string
+		wantReachable bool
+		wantStatus    int
+		wantError     string
+	}{
+		{name: "success", url: server.URL + "/ok", wantReachable: true, wantStatus: http.StatusOK},
+		{name: "client error is reachable", url: server.URL + "/not-allowed", wantReachable: true, wantStatus: http.StatusMethodNotAllowed},
+		{name: "server error", url: server.URL + "/down", wantStatus: http.StatusServiceUnavailable, wantError: "unexpected status"},
+		{name: "connection refused", url: "http://127.0.0.1:1/", wantError: "connection refused"},
+	}
+
+	prober := NewProber(func() []config.DestinationConfig { return nil }, logrus.New())
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := prober.check(config.HealthCheckConfig{URL: tt.url, Method: http.MethodHead, Timeout: time.Second})
+
+			assert.Equal(t, tt.wantReachable, result.Reachable)
+			assert.Equal(t, tt.wantStatus, result.StatusCode)
+			assert.Equal(t, tt.url, result.URL)
+			assert.False(t, result.CheckedAt.IsZero())
+			if tt.wantError != "" {
+				assert.Contains(t, result.Error, tt.wantError)
+			} else {
+				assert.Empty(t, result.Error)
+			}
+		})
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, method := range methods {
+		assert.Equal(t, http.MethodHead, method)
+	}
+}
+
+func TestProber_CheckDue(t *testing.T) {
+	var mu sync.Mutex
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	check := config.HealthCheckConfig{URL: server.URL, Method: http.MethodGet, Interval: time.Minute, Timeout: time.Second}
+	destinations := []config.DestinationConfig{
+		{Name: "probed", Enabled: true, HealthCheck: check},
+		{Name: "disabled", Enabled: false, HealthCheck: check},
+		{Name: "unprobed", Enabled: true},
+	}
+
+	var targetsMu sync.Mutex
+	targets := func() []config.DestinationConfig {
+		targetsMu.Lock()
+		defer targetsMu.Unlock()
+		return append([]config.DestinationConfig(nil), destinations...)
+	}
+
+	prober := NewProber(targets, logrus.New())
+	assert.False(t, prober.Initialized())
+
+	now := time.Now()
+	prober.checkDue(now)
+	prober.wg.Wait()
+
+	assert.True(t, prober.Initialized())
+
+	result, ok := prober.Result("probed")
+	require.True(t, ok)
+	assert.True(t, result.Reachable)
+
+	_, ok = prober.Result("disabled")
+	assert.False(t, ok)
+	_, ok = prober.Result("unprobed")
+	assert.False(t, ok)
+
+	// Not due again within the interval
+	prober.checkDue(now.Add(time.Second))
+	prober.wg.Wait()
+	mu.Lock()
+	assert.Equal(t, 1, requests)
+	mu.Unlock()
+
+	// Due once the interval elapsed
+	prober.checkDue(time.Now().Add(2 * time.Minute))
+	prober.wg.Wait()
+	mu.Lock()
+	assert.Equal(t, 2, requests)
+	mu.Unlock()
+
+	// Results of removed destinations are forgotten
+	targetsMu.Lock()
+	destinations = destinations[1:]
+	targetsMu.Unlock()
+
+	prober.checkDue(time.Now())
+	_, ok = prober.Result("probed")
+	assert.False(t, ok)
+}
+
+func TestProber_StartStop(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	prober := NewProber(func() []config.DestinationConfig {
+		return []config.DestinationConfig{{
+			Name:        "probed",
+			Enabled:     true,
+			HealthCheck: config.HealthCheckConfig{URL: server.URL, Method: http.MethodHead, Interval: time.Minute, Timeout: time.Second},
+		}}
+	}, logrus.New())
+
+	prober.Start()
+	prober.Stop()
+	prober.Stop()
+
+	// The first round runs as soon as the prober starts
+	_, ok := prober.Result("probed")
+	assert.True(t, ok)
+}
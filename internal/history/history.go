@@ -0,0 +1,304 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Delivery record statuses
+const (
+	StatusSuccess = "success"
+	StatusError   = "error"
+	StatusQueued  = "queued"
+)
+
+// Record describes one delivery attempt to a destination
+type Record struct {
+	ID           string    `json:"id"`
+	RequestID    string    `json:"request_id,omitempty"`
+	Destination  string    `json:"destination"`
+	GroupKey     string    `json:"group_key"`
+	Fingerprints []string  `json:"fingerprints"`
+	Status       string    `json:"status"`
+	StatusCode   int       `json:"status_code,omitempty"`
+	Requests     int       `json:"requests"`
+	LatencyMS    int64     `json:"latency_ms"`
+	BodySize     int       `json:"body_size"`
+	Error        string    `json:"error,omitempty"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// Query selects records; empty fields match any record
+type Query struct {
+	Destination string
+	Fingerprint string
+	Since       time.Time
+	Limit       int
+}
+
+// Store is a bounded ring buffer of delivery records, optionally appended to a JSON lines file
+type Store struct {
+	mu      sync.RWMutex
+	records []Record
+	next    int
+	full    bool
+	file    *os.File
+	path    string
+	written int
+	logger  *logrus.Entry
+}
+
+// NewStore creates a store holding up to size records and restores the history file if present
+func NewStore(size int, path string, logger *logrus.Logger) (*Store, error) {
+	if size <= 0 {
+		size = 1000
+	}
+
+	s := &Store{
+		records: make([]Record, size),
+		path:    path,
+		logger:  logger.WithField("component", "history"),
+	}
+
+	if path != "" {
+		if err := s.load(); err != nil {
+			return nil, err
+		}
+
+		// Rewrite the file so it only holds the retained records
+		if err := s.compact(); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// Add stores a record, assigning its ID and timestamp if unset, and returns the ID
+func (s *Store) Add(record Record) string {
+	if record.ID == "" {
+		record.ID = uuid.New().String()
+	}
+	if record.Timestamp.IsZero() {
+		record.Timestamp = time.Now().UTC()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.push(record)
+
+	if s.file != nil {
+		if err := s.append(record); err != nil {
+			s.logger.WithError(err).Error("Failed to persist delivery record")
+		}
+	}
+
+	return record.ID
+}
+
+// Get returns a record by ID
+func (s *Store) Get(id string) (Record, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, record := range s.ordered() {
+		if record.ID == id {
+			return record, true
+		}
+	}
+
+	return Record{}, false
+}
+
+// Query returns matching records, newest first
+func (s *Store) Query(q Query) []Record {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ordered := s.ordered()
+	result := make([]Record, 0)
+
+	for i := len(ordered) - 1; i >= 0; i-- {
+		record := ordered[i]
+
+		if q.Destination != "" && record.Destination != q.Destination {
+			continue
+		}
+		if !q.Since.IsZero() && record.Timestamp.Before(q.Since) {
+			continue
+		}
+		if q.Fingerprint != "" && !containsString(record.Fingerprints, q.Fingerprint) {
+			continue
+		}
+
+		result = append(result, record)
+		if q.Limit > 0 && len(result) >= q.Limit {
+			break
+		}
+	}
+
+	return result
+}
+
+// Len returns the number of stored records
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.size()
+}
+
+// Close closes the history file
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+
+	err := s.file.Close()
+	s.file = nil
+
+	return err
+}
+
+func (s *Store) push(record Record) {
+	s.records[s.next] = record
+	s.next = (s.next + 1) % len(s.records)
+	if s.next == 0 {
+		s.full = true
+	}
+}
+
+// ordered returns the stored records, oldest first
+func (s *Store) ordered() []Record {
+	if !s.full {
+		return s.records[:s.next]
+	}
+
+	return append(append([]Record(nil), s.records[s.next:]...), s.records[:s.next]...)
+}
+
+// append writes a record to the history file, compacting it once it holds twice the retained records
+func (s *Store) append(record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal delivery record: %w", err)
+	}
+
+	if _, err := s.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write delivery record: %w", err)
+	}
+
+	s.written++
+	if s.written >= 2*len(s.records) {
+		return s.compact()
+	}
+
+	return nil
+}
+
+// compact rewrites the history file with the retained records and reopens it for appending
+func (s *Store) compact() error {
+	if s.file != nil {
+		s.file.Close()
+		s.file = nil
+	}
+
+	tmp := s.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create history file: %w", err)
+	}
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, record := range s.ordered() {
+		if err := enc.Encode(record); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to write history file: %w", err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to replace history file: %w", err)
+	}
+
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+
+	s.file = file
+	s.written = s.size()
+
+	return nil
+}
+
+// size returns the number of stored records; callers hold the lock
+func (s *Store) size() int {
+	if s.full {
+		return len(s.records)
+	}
+
+	return s.next
+}
+
+// load restores records from the history file
+func (s *Store) load() error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read history file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			// Skip lines truncated by a crash
+			continue
+		}
+		s.push(record)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	s.logger.WithField("records", s.size()).Info("Restored delivery history")
+
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,107 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T, size int, path string) *Store {
+	t.Helper()
+
+	store, err := NewStore(size, path, logrus.New())
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	return store
+}
+
+func TestStore_RingBuffer(t *testing.T) {
+	store := newTestStore(t, 3, "")
+
+	var ids []string
+	for i := 0; i < 5; i++ {
+		ids = append(ids, store.Add(Record{Destination: fmt.Sprintf("dest-%d", i)}))
+	}
+
+	assert.Equal(t, 3, store.Len())
+
+	_, exists := store.Get(ids[0])
+	assert.False(t, exists, "oldest record should be evicted")
+
+	record, exists := store.Get(ids[4])
+	require.True(t, exists)
+	assert.Equal(t, "dest-4", record.Destination)
+	assert.False(t, record.Timestamp.IsZero())
+
+	records := store.Query(Query{})
+	require.Len(t, records, 3)
+	assert.Equal(t, "dest-4", records[0].Destination, "newest record first")
+	assert.Equal(t, "dest-2", records[2].Destination)
+}
+
+func TestStore_Query(t *testing.T) {
+	store := newTestStore(t, 10, "")
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	store.Add(Record{Destination: "pagerduty", Fingerprints: []string{"a", "b"}, Timestamp: base})
+	store.Add(Record{Destination: "slack", Fingerprints: []string{"a"}, Timestamp: base.Add(time.Minute)})
+	store.Add(Record{Destination: "pagerduty", Fingerprints: []string{"c"}, Timestamp: base.Add(2 * time.Minute)})
+
+	tests := []struct {
+		name  string
+		query Query
+		want  int
+	}{
+		{name: "all", query: Query{}, want: 3},
+		{name: "by destination", query: Query{Destination: "pagerduty"}, want: 2},
+		{name: "by fingerprint", query: Query{Fingerprint: "a"}, want: 2},
+		{name: "by destination and fingerprint", query: Query{Destination: "pagerduty", Fingerprint: "a"}, want: 1},
+		{name: "since", query: Query{Since: base.Add(time.Minute)}, want: 2},
+		{name: "limit", query: Query{Limit: 1}, want: 1},
+		{name: "no match", query: Query{Fingerprint: "z"}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Len(t, store.Query(tt.query), tt.want)
+		})
+	}
+}
+
+func TestStore_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deliveries.jsonl")
+
+	store, err := NewStore(2, path, logrus.New())
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		store.Add(Record{Destination: fmt.Sprintf("dest-%d", i), Status: StatusSuccess})
+	}
+	require.NoError(t, store.Close())
+
+	// Append a truncated line as left by a crash
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"id":"broken`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	restored := newTestStore(t, 2, path)
+	records := restored.Query(Query{})
+	require.Len(t, records, 2)
+	assert.Equal(t, "dest-2", records[0].Destination)
+	assert.Equal(t, "dest-1", records[1].Destination)
+
+	// The file is compacted to the retained records on load
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(data), "\n"))
+}
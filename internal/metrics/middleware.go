@@ -76,6 +76,8 @@ func sanitizePath(path string) string {
 		return "/api/v1/destinations/{name}"
 	case strings.HasPrefix(path, "/api/v1/test/"):
 		return "/api/v1/test/{destination}"
+	case path == "/api/v2/alerts":
+		return "/api/v2/alerts"
 	case path == "/health":
 		return "/health"
 	case path == "/metrics":
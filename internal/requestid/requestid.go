@@ -0,0 +1,85 @@
+package requestid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Header is the HTTP header carrying the request id of inbound requests
+const Header = "X-Request-ID"
+
+// maxLength limits accepted inbound request ids
+const maxLength = 128
+
+type contextKey struct{}
+
+// New generates a new request id
+func New() string {
+	return uuid.New().String()
+}
+
+// NewContext returns a context carrying the request id
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request id of the context, or an empty string
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Middleware keeps a valid inbound request id or generates one, stores it in the request
+// context and echoes it in the response header
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(Header)
+		if !valid(id) {
+			id = New()
+		}
+
+		w.Header().Set(Header, id)
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), id)))
+	})
+}
+
+// valid reports whether an inbound request id is safe to log and propagate
+func valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+
+	for _, r := range id {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
+
+// LogHook adds the request id of the entry context to log entries
+type LogHook struct{}
+
+// Levels returns the levels the hook fires for
+func (LogHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire adds the request_id field when the entry has a context with a request id
+func (LogHook) Fire(entry *logrus.Entry) error {
+	if id := FromContext(entry.Context); id != "" {
+		if _, exists := entry.Data["request_id"]; !exists {
+			entry.Data["request_id"] = id
+		}
+	}
+
+	return nil
+}
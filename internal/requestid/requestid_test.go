@@ -0,0 +1,69 @@
+package requestid
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		inbound  string
+		keep     bool
+		generate bool
+	}{
+		{name: "keeps inbound id", inbound: "abc-123", keep: true},
+		{name: "generates missing id", inbound: "", generate: true},
+		{name: "replaces id with spaces", inbound: "abc 123", generate: true},
+		{name: "replaces overlong id", inbound: strings.Repeat("a", maxLength+1), generate: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			handler := Middleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				seen = FromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.inbound != "" {
+				req.Header.Set(Header, tt.inbound)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			assert.NotEmpty(t, seen)
+			assert.Equal(t, seen, w.Header().Get(Header))
+			if tt.keep {
+				assert.Equal(t, tt.inbound, seen)
+			}
+			if tt.generate {
+				assert.NotEqual(t, tt.inbound, seen)
+			}
+		})
+	}
+}
+
+func TestLogHook(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.AddHook(LogHook{})
+
+	logger.WithContext(NewContext(context.Background(), "req-42")).Info("with id")
+	assert.Contains(t, buf.String(), `"request_id":"req-42"`)
+
+	buf.Reset()
+	logger.Info("without id")
+	assert.NotContains(t, buf.String(), "request_id")
+
+	assert.Empty(t, FromContext(context.Background()))
+}
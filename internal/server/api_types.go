@@ -4,6 +4,9 @@ import (
 	"time"
 
 	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+	"github.com/vitalvas/alertmanager-gateway/internal/destination"
+	"github.com/vitalvas/alertmanager-gateway/internal/health"
+	"github.com/vitalvas/alertmanager-gateway/internal/history"
 )
 
 // Common response types
@@ -26,6 +29,7 @@ type DestinationSummary struct {
 	Enabled     bool   `json:"enabled"`
 	SplitAlerts bool   `json:"split_alerts"`
 	AuthEnabled bool   `json:"auth_enabled"`
+	Paused      bool   `json:"paused"`
 	Description string `json:"description"`
 }
 
@@ -51,8 +55,45 @@ type DestinationDetails struct {
 	TransformSize    int               `json:"transform_size,omitempty"`
 	HasTemplate      bool              `json:"has_template"`
 	HasTransform     bool              `json:"has_transform"`
+	Version          int               `json:"version"`
 	CreatedAt        time.Time         `json:"created_at"`
 	UpdatedAt        time.Time         `json:"updated_at"`
+	Pause            *PauseInfo        `json:"pause,omitempty"`
+}
+
+type DigestFlushResponse struct {
+	Success     bool      `json:"success"`
+	Destination string    `json:"destination"`
+	AlertsSent  int       `json:"alerts_sent"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+type PauseRequest struct {
+	TTL    string `json:"ttl,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type PauseInfo struct {
+	Since    time.Time  `json:"since"`
+	Until    *time.Time `json:"until,omitempty"`
+	Reason   string     `json:"reason,omitempty"`
+	Mode     string     `json:"mode"`
+	Buffered int        `json:"buffered"`
+}
+
+type PauseResponse struct {
+	Success     bool       `json:"success"`
+	Destination string     `json:"destination"`
+	Paused      bool       `json:"paused"`
+	Pause       *PauseInfo `json:"pause,omitempty"`
+	Delivered   int        `json:"delivered,omitempty"`
+	Timestamp   time.Time  `json:"timestamp"`
+}
+
+type DeliveriesResponse struct {
+	Deliveries []history.Record `json:"deliveries"`
+	Total      int              `json:"total"`
+	Timestamp  time.Time        `json:"timestamp"`
 }
 
 // Test and emulation types
@@ -160,6 +201,21 @@ type HealthResponse struct {
 	Checks              []HealthCheck `json:"checks"`
 }
 
+// ProbeResponse is returned by the liveness, readiness and startup probes
+type ProbeResponse struct {
+	Status       string                          `json:"status"` // "ok" or "failing"
+	Timestamp    time.Time                       `json:"timestamp"`
+	Checks       []HealthCheck                   `json:"checks,omitempty"`
+	Destinations map[string]DestinationReadiness `json:"destinations,omitempty"`
+}
+
+// DestinationReadiness reports the delivery health of a destination
+type DestinationReadiness struct {
+	Ready               bool           `json:"ready"`
+	ConsecutiveFailures int            `json:"consecutive_failures"`
+	Probe               *health.Result `json:"probe,omitempty"`
+}
+
 // Configuration validation types
 
 type ConfigValidation struct {
@@ -172,13 +228,16 @@ type ConfigValidation struct {
 // Statistics types
 
 type DestinationStats struct {
-	Name            string        `json:"name"`
-	RequestCount    int64         `json:"request_count"`
-	SuccessCount    int64         `json:"success_count"`
-	ErrorCount      int64         `json:"error_count"`
-	AverageLatency  time.Duration `json:"average_latency"`
-	LastRequestTime time.Time     `json:"last_request_time"`
-	LastError       string        `json:"last_error,omitempty"`
+	Name             string                      `json:"name"`
+	RequestCount     int64                       `json:"request_count"`
+	SuccessCount     int64                       `json:"success_count"`
+	ErrorCount       int64                       `json:"error_count"`
+	AverageLatencyMS float64                     `json:"average_latency_ms"`
+	LatencyHistogram []destination.LatencyBucket `json:"latency_histogram"`
+	LastRequestTime  *time.Time                  `json:"last_request_time,omitempty"`
+	LastError        string                      `json:"last_error,omitempty"`
+	LastErrorTime    *time.Time                  `json:"last_error_time,omitempty"`
+	Since            *time.Time                  `json:"since,omitempty"`
 }
 
 type SystemStats struct {
@@ -190,9 +249,47 @@ type SystemStats struct {
 	MemoryUsage         uint64             `json:"memory_usage"`
 	GoroutineCount      int                `json:"goroutine_count"`
 	LastConfigReload    time.Time          `json:"last_config_reload"`
+	TemplateCache       TemplateCacheStats `json:"template_cache"`
 	CollectionTimestamp time.Time          `json:"collection_timestamp"`
 }
 
+type TemplateCacheStats struct {
+	Size            int                       `json:"size"`
+	MaxSize         int                       `json:"max_size"`
+	TTLSeconds      float64                   `json:"ttl_seconds"`
+	Hits            int64                     `json:"hits"`
+	Misses          int64                     `json:"misses"`
+	Evictions       int64                     `json:"evictions"`
+	Renders         int64                     `json:"renders"`
+	AverageRenderMS float64                   `json:"average_render_ms"`
+	Entries         []TemplateCacheEntryStats `json:"entries,omitempty"`
+}
+
+type TemplateCacheEntryStats struct {
+	Key             string    `json:"key"`
+	Engine          string    `json:"engine"`
+	Hits            int64     `json:"hits"`
+	Renders         int64     `json:"renders"`
+	AverageRenderMS float64   `json:"average_render_ms"`
+	CompiledAt      time.Time `json:"compiled_at"`
+	LastUsed        time.Time `json:"last_used"`
+}
+
+type ConfigReloadResponse struct {
+	Success      bool      `json:"success"`
+	Destinations int       `json:"destinations"`
+	Templates    []string  `json:"templates"`
+	Modules      []string  `json:"modules"`
+	Timestamp    time.Time `json:"timestamp"`
+	RequestID    string    `json:"request_id"`
+}
+
+type StatsResetResponse struct {
+	Success     bool      `json:"success"`
+	Destination string    `json:"destination,omitempty"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
 // Webhook simulation types
 
 type WebhookSimulation struct {
@@ -208,17 +305,20 @@ type SimulationRequest struct {
 	Destination string            `json:"destination"`
 	Simulation  WebhookSimulation `json:"simulation"`
 	Options     SimulationOptions `json:"options,omitempty"`
+	DryRun      bool              `json:"dry_run"`
 }
 
 type SimulationOptions struct {
-	GenerateMultiple bool   `json:"generate_multiple"`
-	Count            int    `json:"count,omitempty"`
-	Interval         string `json:"interval,omitempty"`
+	GenerateMultiple bool     `json:"generate_multiple"`
+	Count            int      `json:"count,omitempty"`
+	Interval         string   `json:"interval,omitempty"`
+	Sequence         []string `json:"sequence,omitempty"` // Statuses of successive webhooks, the last one repeats
 }
 
 type SimulationResponse struct {
 	Success           bool                           `json:"success"`
 	Destination       string                         `json:"destination"`
+	DryRun            bool                           `json:"dry_run"`
 	GeneratedWebhooks []*alertmanager.WebhookPayload `json:"generated_webhooks"`
 	Results           []*EmulationResult             `json:"results"`
 	SimulationTime    time.Duration                  `json:"simulation_time"`
@@ -4,19 +4,24 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
 	"github.com/vitalvas/alertmanager-gateway/internal/config"
 	"github.com/vitalvas/alertmanager-gateway/internal/destination"
 	"github.com/vitalvas/alertmanager-gateway/internal/formatter"
+	"github.com/vitalvas/alertmanager-gateway/internal/history"
+	"github.com/vitalvas/alertmanager-gateway/internal/requestid"
 	"github.com/vitalvas/alertmanager-gateway/internal/transform"
+	"github.com/vitalvas/alertmanager-gateway/internal/webhook"
 )
 
 const version = "1.0.0" // Application version
@@ -29,12 +34,20 @@ func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
 		"version":            version,
 		"uptime_seconds":     time.Since(startTime).Seconds(),
 		"config_loaded":      true,
-		"destinations_count": len(s.config.Destinations),
+		"destinations_count": len(s.webhookHandler.Destinations()),
+		"paused_count":       len(s.webhookHandler.PausedDestinations()),
 	}
 
 	s.sendJSON(w, http.StatusOK, health)
 }
 
+// handleLiveness is the minimal health check served on the public listener when an admin listener is configured
+func (s *Server) handleLiveness(w http.ResponseWriter, _ *http.Request) {
+	s.sendJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "healthy",
+	})
+}
+
 // Metrics handler (placeholder)
 
 func (s *Server) handleMetrics(w http.ResponseWriter, _ *http.Request) {
@@ -43,6 +56,25 @@ func (s *Server) handleMetrics(w http.ResponseWriter, _ *http.Request) {
 	fmt.Fprintf(w, "# HELP alertmanager_gateway_info Gateway information\n")
 	fmt.Fprintf(w, "# TYPE alertmanager_gateway_info gauge\n")
 	fmt.Fprintf(w, "alertmanager_gateway_info{version=\"%s\"} 1\n", version)
+
+	destinations := s.webhookHandler.Destinations()
+
+	fmt.Fprintf(w, "# HELP alertmanager_gateway_destination_paused Whether a destination is paused\n")
+	fmt.Fprintf(w, "# TYPE alertmanager_gateway_destination_paused gauge\n")
+	for _, dest := range destinations {
+		paused := 0
+		if _, ok := s.webhookHandler.PauseStatus(dest.Name); ok {
+			paused = 1
+		}
+		fmt.Fprintf(w, "alertmanager_gateway_destination_paused{destination=\"%s\"} %d\n", dest.Name, paused)
+	}
+
+	fmt.Fprintf(w, "# HELP alertmanager_gateway_destination_paused_buffered Payloads buffered for a paused destination\n")
+	fmt.Fprintf(w, "# TYPE alertmanager_gateway_destination_paused_buffered gauge\n")
+	for _, dest := range destinations {
+		status, _ := s.webhookHandler.PauseStatus(dest.Name)
+		fmt.Fprintf(w, "alertmanager_gateway_destination_paused_buffered{destination=\"%s\"} %d\n", dest.Name, status.Buffered)
+	}
 }
 
 // Legacy API handlers (now delegated to API package)
@@ -69,14 +101,19 @@ func (s *Server) sendError(w http.ResponseWriter, status int, message string) {
 		"status":     "error",
 		"error":      message,
 		"timestamp":  time.Now().UTC().Format(time.RFC3339),
-		"request_id": generateRequestID(),
+		"request_id": responseRequestID(w),
 	}
 
 	s.sendJSON(w, status, response)
 }
 
-func generateRequestID() string {
-	return uuid.New().String()
+// responseRequestID returns the request id set by the request id middleware, or a new one
+func responseRequestID(w http.ResponseWriter) string {
+	if id := w.Header().Get(requestid.Header); id != "" {
+		return id
+	}
+
+	return requestid.New()
 }
 
 var startTime = time.Now()
@@ -88,10 +125,25 @@ func (s *Server) RegisterAPIRoutes(router *mux.Router) {
 	// Destination management endpoints
 	router.HandleFunc("/destinations", s.handleListDestinations).Methods(http.MethodGet)
 	router.HandleFunc("/destinations/{name}", s.handleGetDestination).Methods(http.MethodGet)
+	router.HandleFunc("/destinations/{name}/digest/flush", s.handleFlushDigest).Methods(http.MethodPost)
+	router.HandleFunc("/destinations/{name}/pause", s.handlePauseDestination).Methods(http.MethodPost)
+	router.HandleFunc("/destinations/{name}/resume", s.handleResumeDestination).Methods(http.MethodPost)
+	router.HandleFunc("/destinations/{name}/stats", s.handleDestinationStats).Methods(http.MethodGet)
+	router.HandleFunc("/destinations/{name}/stats/reset", s.handleResetDestinationStats).Methods(http.MethodPost)
+
+	// Statistics endpoints
+	router.HandleFunc("/stats", s.handleSystemStats).Methods(http.MethodGet)
+	router.HandleFunc("/stats/reset", s.handleResetStats).Methods(http.MethodPost)
+	router.HandleFunc("/stats/templates", s.handleTemplateCacheStats).Methods(http.MethodGet)
+
+	// Delivery history endpoints
+	router.HandleFunc("/deliveries", s.handleListDeliveries).Methods(http.MethodGet)
+	router.HandleFunc("/deliveries/{id}", s.handleGetDelivery).Methods(http.MethodGet)
 
 	// Test and emulation endpoints
 	router.HandleFunc("/test/{destination}", s.handleTestDestination).Methods(http.MethodPost)
 	router.HandleFunc("/emulate/{destination}", s.handleEmulateDestination).Methods(http.MethodPost)
+	router.HandleFunc("/simulate", s.handleSimulate).Methods(http.MethodPost)
 
 	// System information endpoints
 	router.HandleFunc("/info", s.handleSystemInfo).Methods(http.MethodGet)
@@ -99,6 +151,7 @@ func (s *Server) RegisterAPIRoutes(router *mux.Router) {
 
 	// Configuration endpoints
 	router.HandleFunc("/config/validate", s.handleValidateConfig).Methods(http.MethodPost)
+	router.HandleFunc("/config/reload", s.handleReloadConfig).Methods(http.MethodPost)
 }
 
 // Destination management handlers
@@ -107,9 +160,10 @@ func (s *Server) handleListDestinations(w http.ResponseWriter, r *http.Request)
 	// Check if we should include disabled destinations
 	includeDisabled := r.URL.Query().Get("include_disabled") == "true"
 
-	destinations := make([]DestinationSummary, 0, len(s.config.Destinations))
+	configured := s.webhookHandler.Destinations()
+	destinations := make([]DestinationSummary, 0, len(configured))
 
-	for _, dest := range s.config.Destinations {
+	for _, dest := range configured {
 		if dest.Enabled || includeDisabled {
 			summary := DestinationSummary{
 				Name:        dest.Name,
@@ -120,6 +174,7 @@ func (s *Server) handleListDestinations(w http.ResponseWriter, r *http.Request)
 				Enabled:     dest.Enabled,
 				SplitAlerts: dest.SplitAlerts,
 				AuthEnabled: len(dest.Headers) > 0, // Simplified check
+				Paused:      s.pauseInfo(dest.Name) != nil,
 				Description: generateDestinationDescription(&dest),
 			}
 			destinations = append(destinations, summary)
@@ -139,40 +194,207 @@ func (s *Server) handleGetDestination(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	name := vars["name"]
 
-	dest := s.config.GetDestinationByNameAny(name)
-	if dest == nil {
+	dest, exists := s.webhookHandler.Destination(name)
+	if !exists {
 		s.sendAPIError(w, http.StatusNotFound, "Destination not found")
 		return
 	}
 
-	details := DestinationDetails{
-		Name:        dest.Name,
-		WebhookURL:  fmt.Sprintf("/webhook/%s", dest.Name),
-		Method:      dest.Method,
-		TargetURL:   maskSensitiveURL(dest.URL),
-		Format:      dest.Format,
-		Engine:      dest.Engine,
-		Enabled:     dest.Enabled,
-		SplitAlerts: dest.SplitAlerts,
-		Headers:     maskSensitiveHeaders(dest.Headers),
-		CreatedAt:   time.Now().UTC(), // TODO: Store actual creation time
-		UpdatedAt:   time.Now().UTC(), // TODO: Store actual update time
+	details := newDestinationDetails(&dest)
+	details.Pause = s.pauseInfo(name)
+
+	s.sendJSON(w, http.StatusOK, details)
+}
+
+func (s *Server) handleFlushDigest(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name := vars["name"]
+
+	if dest, exists := s.webhookHandler.Destination(name); !exists || !dest.Enabled {
+		s.sendAPIError(w, http.StatusNotFound, "Destination not found")
+		return
 	}
 
-	if dest.Engine == "go-template" {
-		details.TemplateSize = len(dest.Template)
-		details.HasTemplate = dest.Template != ""
-	} else {
-		details.TransformSize = len(dest.Transform)
-		details.HasTransform = dest.Transform != ""
+	alertsSent, err := s.webhookHandler.FlushDigest(r.Context(), name)
+	if err != nil {
+		if errors.Is(err, webhook.ErrNotDigestDestination) {
+			s.sendAPIError(w, http.StatusBadRequest, "Destination does not use digest delivery")
+			return
+		}
+
+		s.sendAPIError(w, http.StatusInternalServerError, fmt.Sprintf("Digest flush failed: %v", err))
+		return
 	}
 
-	if dest.SplitAlerts {
-		details.BatchSize = dest.BatchSize
-		details.ParallelRequests = dest.ParallelRequests
+	response := DigestFlushResponse{
+		Success:     true,
+		Destination: name,
+		AlertsSent:  alertsSent,
+		Timestamp:   time.Now().UTC(),
 	}
 
-	s.sendJSON(w, http.StatusOK, details)
+	s.sendJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handlePauseDestination(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name := vars["name"]
+
+	var req PauseRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.sendAPIError(w, http.StatusBadRequest, "Invalid JSON payload")
+			return
+		}
+	}
+
+	var ttl time.Duration
+	if req.TTL != "" {
+		var err error
+		ttl, err = time.ParseDuration(req.TTL)
+		if err != nil || ttl < 0 {
+			s.sendAPIError(w, http.StatusBadRequest, fmt.Sprintf("Invalid ttl: %s", req.TTL))
+			return
+		}
+	}
+
+	if _, err := s.webhookHandler.Pause(r.Context(), name, ttl, req.Reason); err != nil {
+		if errors.Is(err, webhook.ErrDestinationNotFound) {
+			s.sendAPIError(w, http.StatusNotFound, "Destination not found")
+			return
+		}
+
+		s.sendAPIError(w, http.StatusInternalServerError, fmt.Sprintf("Pause failed: %v", err))
+		return
+	}
+
+	response := PauseResponse{
+		Success:     true,
+		Destination: name,
+		Paused:      true,
+		Pause:       s.pauseInfo(name),
+		Timestamp:   time.Now().UTC(),
+	}
+
+	s.sendJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleResumeDestination(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name := vars["name"]
+
+	delivered, err := s.webhookHandler.Resume(r.Context(), name)
+	if err != nil {
+		if errors.Is(err, webhook.ErrDestinationNotFound) {
+			s.sendAPIError(w, http.StatusNotFound, "Destination not found")
+			return
+		}
+
+		s.sendAPIError(w, http.StatusInternalServerError, fmt.Sprintf("Resume failed: %v", err))
+		return
+	}
+
+	response := PauseResponse{
+		Success:     true,
+		Destination: name,
+		Paused:      false,
+		Delivered:   delivered,
+		Timestamp:   time.Now().UTC(),
+	}
+
+	s.sendJSON(w, http.StatusOK, response)
+}
+
+// pauseInfo returns the pause state of a destination, or nil if it is not paused
+func (s *Server) pauseInfo(name string) *PauseInfo {
+	status, paused := s.webhookHandler.PauseStatus(name)
+	if !paused {
+		return nil
+	}
+
+	info := &PauseInfo{
+		Since:    status.Since,
+		Reason:   status.Reason,
+		Mode:     status.Mode,
+		Buffered: status.Buffered,
+	}
+
+	if !status.Until.IsZero() {
+		until := status.Until
+		info.Until = &until
+	}
+
+	return info
+}
+
+// Delivery history handlers
+
+func (s *Server) handleListDeliveries(w http.ResponseWriter, r *http.Request) {
+	if !s.webhookHandler.HistoryEnabled() {
+		s.sendAPIError(w, http.StatusNotFound, "Delivery history is not enabled")
+		return
+	}
+
+	params := r.URL.Query()
+	query := history.Query{
+		Destination: params.Get("destination"),
+		Fingerprint: params.Get("fingerprint"),
+		Limit:       100,
+	}
+
+	if since := params.Get("since"); since != "" {
+		parsed, err := parseSince(since, time.Now())
+		if err != nil {
+			s.sendAPIError(w, http.StatusBadRequest, fmt.Sprintf("Invalid since: %s", since))
+			return
+		}
+		query.Since = parsed
+	}
+
+	if limit := params.Get("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed <= 0 {
+			s.sendAPIError(w, http.StatusBadRequest, fmt.Sprintf("Invalid limit: %s", limit))
+			return
+		}
+		query.Limit = parsed
+	}
+
+	deliveries := s.webhookHandler.Deliveries(query)
+
+	response := DeliveriesResponse{
+		Deliveries: deliveries,
+		Total:      len(deliveries),
+		Timestamp:  time.Now().UTC(),
+	}
+
+	s.sendJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleGetDelivery(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	record, exists := s.webhookHandler.Delivery(vars["id"])
+	if !exists {
+		s.sendAPIError(w, http.StatusNotFound, "Delivery not found")
+		return
+	}
+
+	s.sendJSON(w, http.StatusOK, record)
+}
+
+// parseSince parses an RFC 3339 timestamp or a duration relative to now
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return now.Add(-d), nil
 }
 
 // Test and emulation handlers
@@ -181,8 +403,8 @@ func (s *Server) handleTestDestination(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	destinationName := vars["destination"]
 
-	dest := s.config.GetDestinationByNameAny(destinationName)
-	if dest == nil {
+	dest, exists := s.webhookHandler.Destination(destinationName)
+	if !exists {
 		s.sendAPIError(w, http.StatusNotFound, "Destination not found")
 		return
 	}
@@ -206,7 +428,7 @@ func (s *Server) handleTestDestination(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Test the transformation and formatting
-	result, err := s.testDestinationTransformation(dest, webhookData)
+	result, err := s.testDestinationTransformation(&dest, webhookData)
 	if err != nil {
 		s.sendAPIError(w, http.StatusInternalServerError, fmt.Sprintf("Test failed: %v", err))
 		return
@@ -217,7 +439,7 @@ func (s *Server) handleTestDestination(w http.ResponseWriter, r *http.Request) {
 		Destination:   destinationName,
 		Result:        result,
 		TestTimestamp: time.Now().UTC(),
-		RequestID:     generateRequestID(),
+		RequestID:     responseRequestID(w),
 	}
 
 	s.sendJSON(w, http.StatusOK, response)
@@ -227,8 +449,8 @@ func (s *Server) handleEmulateDestination(w http.ResponseWriter, r *http.Request
 	vars := mux.Vars(r)
 	destinationName := vars["destination"]
 
-	dest := s.config.GetDestinationByNameAny(destinationName)
-	if dest == nil {
+	dest, exists := s.webhookHandler.Destination(destinationName)
+	if !exists {
 		s.sendAPIError(w, http.StatusNotFound, "Destination not found")
 		return
 	}
@@ -247,7 +469,7 @@ func (s *Server) handleEmulateDestination(w http.ResponseWriter, r *http.Request
 	}
 
 	// Perform full emulation including HTTP request
-	result, err := s.emulateDestinationRequest(dest, webhookData, emulateReq.DryRun)
+	result, err := s.emulateDestinationRequest(&dest, webhookData, emulateReq.DryRun)
 	if err != nil {
 		s.sendAPIError(w, http.StatusInternalServerError, fmt.Sprintf("Emulation failed: %v", err))
 		return
@@ -259,7 +481,7 @@ func (s *Server) handleEmulateDestination(w http.ResponseWriter, r *http.Request
 		DryRun:             emulateReq.DryRun,
 		Result:             result,
 		EmulationTimestamp: time.Now().UTC(),
-		RequestID:          generateRequestID(),
+		RequestID:          responseRequestID(w),
 	}
 
 	s.sendJSON(w, http.StatusOK, response)
@@ -283,7 +505,7 @@ func (s *Server) handleSystemInfo(w http.ResponseWriter, _ *http.Request) {
 		NumGC:            memStats.NumGC,
 		Uptime:           time.Since(startTime),
 		Config: ConfigInfo{
-			DestinationsCount:        len(s.config.Destinations),
+			DestinationsCount:        len(s.webhookHandler.Destinations()),
 			EnabledDestinationsCount: s.countEnabledDestinations(),
 			AuthEnabled:              s.config.Server.Auth.Enabled,
 			ServerAddress:            s.config.Server.Address,
@@ -295,18 +517,20 @@ func (s *Server) handleSystemInfo(w http.ResponseWriter, _ *http.Request) {
 }
 
 func (s *Server) handleAPIHealth(w http.ResponseWriter, _ *http.Request) {
+	destinationsCount := len(s.webhookHandler.Destinations())
+
 	health := HealthResponse{
 		Status:              "healthy",
 		Timestamp:           time.Now().UTC(),
 		UptimeSeconds:       time.Since(startTime).Seconds(),
 		ConfigLoaded:        true,
-		DestinationsCount:   len(s.config.Destinations),
+		DestinationsCount:   destinationsCount,
 		EnabledDestinations: s.countEnabledDestinations(),
 		Checks: []HealthCheck{
 			{
 				Name:    "destinations",
 				Status:  "healthy",
-				Message: fmt.Sprintf("%d destinations configured", len(s.config.Destinations)),
+				Message: fmt.Sprintf("%d destinations configured", destinationsCount),
 			},
 			{
 				Name:    "memory",
@@ -316,6 +540,16 @@ func (s *Server) handleAPIHealth(w http.ResponseWriter, _ *http.Request) {
 		},
 	}
 
+	// Add warning if any destination is paused
+	if paused := s.webhookHandler.PausedDestinations(); len(paused) > 0 {
+		sort.Strings(paused)
+		health.Checks = append(health.Checks, HealthCheck{
+			Name:    "paused_destinations",
+			Status:  "warning",
+			Message: fmt.Sprintf("%d destinations paused: %s", len(paused), strings.Join(paused, ", ")),
+		})
+	}
+
 	// Add warning if no destinations are enabled
 	if s.countEnabledDestinations() == 0 {
 		health.Checks = append(health.Checks, HealthCheck{
@@ -362,11 +596,11 @@ func (s *Server) testDestinationTransformation(dest *config.DestinationConfig, w
 		engineType = transform.EngineTypeGoTemplate
 	} else {
 		content = dest.Transform
-		engineType = transform.EngineTypeJQ
+		engineType = transform.EngineType(dest.Engine)
 	}
 
-	// Create transformation engine
-	engine, err := transform.NewEngine(engineType, content)
+	// Share the compiled engine with deliveries to the destination
+	engine, err := transform.GetTemplateCache().GetEngine(engineType, content, s.webhookHandler.Library(), dest.TransformTimeout)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create transformation engine: %w", err)
 	}
@@ -410,11 +644,38 @@ func (s *Server) emulateDestinationRequest(dest *config.DestinationConfig, webho
 		return nil, err
 	}
 
+	// Render the URL, method and headers the way deliveries do
+	request, err := destination.NewRequestTemplate(dest, s.webhookHandler.Library())
+	if err != nil {
+		return nil, err
+	}
+
+	var alert *alertmanager.Alert
+	if dest.SplitAlerts && len(webhookData.Alerts) > 0 {
+		alert = &webhookData.Alerts[0]
+	}
+
+	target, err := request.Render(alert, webhookData)
+	if err != nil {
+		return nil, err
+	}
+
+	// In requests output mode the result describes several requests, which are checked
+	// like deliveries check them but only emulated as a dry run
+	if dest.OutputMode == config.OutputModeRequests {
+		if _, err := request.Requests(transformResult.TransformedData, target); err != nil {
+			return nil, fmt.Errorf("invalid requests output: %w", err)
+		}
+		if !dryRun {
+			return nil, fmt.Errorf("output_mode requests can only be emulated as a dry run")
+		}
+	}
+
 	result := &EmulationResult{
 		TransformationResult: *transformResult,
-		HTTPMethod:           dest.Method,
-		TargetURL:            maskSensitiveURL(dest.URL),
-		Headers:              maskSensitiveHeaders(dest.Headers),
+		HTTPMethod:           target.Method,
+		TargetURL:            maskSensitiveURL(target.URL),
+		Headers:              maskSensitiveHeaders(target.Headers),
 		RequestSize:          len(transformResult.FormattedOutput),
 		EmulationTime:        time.Since(start),
 	}
@@ -426,8 +687,8 @@ func (s *Server) emulateDestinationRequest(dest *config.DestinationConfig, webho
 		// Create request
 		req, err := http.NewRequestWithContext(
 			context.Background(),
-			dest.Method,
-			dest.URL,
+			target.Method,
+			target.URL,
 			bytes.NewReader([]byte(transformResult.FormattedOutput)),
 		)
 		if err != nil {
@@ -435,7 +696,7 @@ func (s *Server) emulateDestinationRequest(dest *config.DestinationConfig, webho
 		}
 
 		// Add headers
-		for key, value := range dest.Headers {
+		for key, value := range target.Headers {
 			req.Header.Set(key, value)
 		}
 
@@ -473,7 +734,7 @@ func (s *Server) emulateDestinationRequest(dest *config.DestinationConfig, webho
 
 func (s *Server) countEnabledDestinations() int {
 	count := 0
-	for _, dest := range s.config.Destinations {
+	for _, dest := range s.webhookHandler.Destinations() {
 		if dest.Enabled {
 			count++
 		}
@@ -486,7 +747,7 @@ func (s *Server) sendAPIError(w http.ResponseWriter, status int, message string)
 		Status:    "error",
 		Error:     message,
 		Timestamp: time.Now().UTC(),
-		RequestID: generateRequestID(),
+		RequestID: responseRequestID(w),
 	}
 
 	s.sendJSON(w, status, response)
@@ -494,9 +755,46 @@ func (s *Server) sendAPIError(w http.ResponseWriter, status int, message string)
 
 // Utility functions
 
-func generateDestinationDescription(dest *config.DestinationConfig) string {
+func newDestinationDetails(dest *config.DestinationConfig) DestinationDetails {
+	details := DestinationDetails{
+		Name:        dest.Name,
+		WebhookURL:  fmt.Sprintf("/webhook/%s", dest.Name),
+		Method:      dest.Method,
+		TargetURL:   maskSensitiveURL(dest.URL),
+		Format:      dest.Format,
+		Engine:      dest.Engine,
+		Enabled:     dest.Enabled,
+		SplitAlerts: dest.SplitAlerts,
+		Headers:     maskSensitiveHeaders(dest.Headers),
+		Version:     dest.Version,
+		CreatedAt:   dest.CreatedAt,
+		UpdatedAt:   dest.UpdatedAt,
+	}
+
 	if dest.Engine == "go-template" {
+		details.TemplateSize = len(dest.Template)
+		details.HasTemplate = dest.Template != ""
+	} else {
+		details.TransformSize = len(dest.Transform)
+		details.HasTransform = dest.Transform != ""
+	}
+
+	if dest.SplitAlerts {
+		details.BatchSize = dest.BatchSize
+		details.ParallelRequests = dest.ParallelRequests
+	}
+
+	return details
+}
+
+func generateDestinationDescription(dest *config.DestinationConfig) string {
+	switch dest.Engine {
+	case "go-template":
 		return fmt.Sprintf("%s destination using Go templates", dest.Format)
+	case "cel":
+		return fmt.Sprintf("%s destination using CEL expressions", dest.Format)
+	case "starlark":
+		return fmt.Sprintf("%s destination using Starlark scripts", dest.Format)
 	}
 	return fmt.Sprintf("%s destination using jq transformations", dest.Format)
 }
@@ -5,7 +5,10 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
@@ -13,6 +16,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
 	"github.com/vitalvas/alertmanager-gateway/internal/config"
+	"github.com/vitalvas/alertmanager-gateway/internal/history"
+	"github.com/vitalvas/alertmanager-gateway/internal/webhook"
 )
 
 func TestRegisterAPIRoutes(t *testing.T) {
@@ -31,6 +36,11 @@ func TestRegisterAPIRoutes(t *testing.T) {
 	}{
 		{"GET", "/destinations"},
 		{"GET", "/destinations/test"},
+		{"POST", "/destinations/test/digest/flush"},
+		{"POST", "/destinations/test/pause"},
+		{"POST", "/destinations/test/resume"},
+		{"GET", "/deliveries"},
+		{"GET", "/deliveries/abc"},
 		{"POST", "/test/test"},
 		{"POST", "/emulate/test"},
 		{"GET", "/info"},
@@ -215,6 +225,283 @@ func TestHandleGetDestination(t *testing.T) {
 	})
 }
 
+func TestHandleFlushDigest(t *testing.T) {
+	var requests int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	cfg := &config.Config{
+		Destinations: []config.DestinationConfig{
+			{
+				Name:     "digest-dest",
+				Method:   "POST",
+				URL:      target.URL,
+				Format:   "json",
+				Engine:   "go-template",
+				Template: `{"count": {{ len .Alerts }}}`,
+				Enabled:  true,
+				Digest:   config.DigestConfig{Window: time.Hour},
+			},
+			{
+				Name:     "plain-dest",
+				Method:   "POST",
+				URL:      target.URL,
+				Format:   "json",
+				Engine:   "go-template",
+				Template: `{}`,
+				Enabled:  true,
+			},
+		},
+	}
+
+	server, err := New(cfg, logrus.New())
+	require.NoError(t, err)
+	defer server.webhookHandler.Close()
+
+	flush := func(name string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/destinations/"+name+"/digest/flush", nil)
+		req = mux.SetURLVars(req, map[string]string{"name": name})
+		w := httptest.NewRecorder()
+		server.handleFlushDigest(w, req)
+		return w
+	}
+
+	t.Run("flush empty digest", func(t *testing.T) {
+		w := flush("digest-dest")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response DigestFlushResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.True(t, response.Success)
+		assert.Equal(t, 0, response.AlertsSent)
+		assert.Equal(t, int32(0), atomic.LoadInt32(&requests))
+	})
+
+	t.Run("flush pending digest", func(t *testing.T) {
+		body, err := json.Marshal(getSampleWebhookData())
+		require.NoError(t, err)
+
+		req := httptest.NewRequest("POST", "/webhook/digest-dest", bytes.NewReader(body))
+		req = mux.SetURLVars(req, map[string]string{"destination": "digest-dest"})
+		server.webhookHandler.HandleWebhook(httptest.NewRecorder(), req)
+
+		w := flush("digest-dest")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response DigestFlushResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.Equal(t, len(getSampleWebhookData().Alerts), response.AlertsSent)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	})
+
+	t.Run("destination without digest", func(t *testing.T) {
+		w := flush("plain-dest")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("unknown destination", func(t *testing.T) {
+		w := flush("missing")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestHandlePauseDestination(t *testing.T) {
+	cfg := &config.Config{
+		Destinations: []config.DestinationConfig{
+			{
+				Name:     "test-dest",
+				Method:   "POST",
+				URL:      "https://example.com/webhook",
+				Format:   "json",
+				Engine:   "go-template",
+				Template: `{}`,
+				Enabled:  true,
+				Pause:    config.PauseConfig{Mode: config.PauseModeReject},
+			},
+		},
+	}
+
+	server, err := New(cfg, logrus.New())
+	require.NoError(t, err)
+	defer server.webhookHandler.Close()
+
+	call := func(handler http.HandlerFunc, name, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/destinations/"+name, strings.NewReader(body))
+		req = mux.SetURLVars(req, map[string]string{"name": name})
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	t.Run("pause with ttl", func(t *testing.T) {
+		w := call(server.handlePauseDestination, "test-dest", `{"ttl":"30m","reason":"maintenance"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response PauseResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.True(t, response.Paused)
+		require.NotNil(t, response.Pause)
+		assert.Equal(t, "maintenance", response.Pause.Reason)
+		assert.Equal(t, config.PauseModeReject, response.Pause.Mode)
+		assert.NotNil(t, response.Pause.Until)
+	})
+
+	t.Run("paused state is reported", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/destinations/test-dest", nil)
+		req = mux.SetURLVars(req, map[string]string{"name": "test-dest"})
+		w := httptest.NewRecorder()
+		server.handleGetDestination(w, req)
+
+		var details DestinationDetails
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&details))
+		assert.NotNil(t, details.Pause)
+
+		w = httptest.NewRecorder()
+		server.handleListDestinations(w, httptest.NewRequest("GET", "/destinations", nil))
+
+		var list ListDestinationsResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&list))
+		require.Len(t, list.Destinations, 1)
+		assert.True(t, list.Destinations[0].Paused)
+
+		w = httptest.NewRecorder()
+		server.handleAPIHealth(w, httptest.NewRequest("GET", "/health", nil))
+		assert.Contains(t, w.Body.String(), "paused_destinations")
+
+		w = httptest.NewRecorder()
+		server.handleMetrics(w, httptest.NewRequest("GET", "/metrics", nil))
+		assert.Contains(t, w.Body.String(), `alertmanager_gateway_destination_paused{destination="test-dest"} 1`)
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		w := call(server.handleResumeDestination, "test-dest", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response PauseResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.False(t, response.Paused)
+
+		_, paused := server.webhookHandler.PauseStatus("test-dest")
+		assert.False(t, paused)
+	})
+
+	t.Run("invalid ttl", func(t *testing.T) {
+		w := call(server.handlePauseDestination, "test-dest", `{"ttl":"soon"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("unknown destination", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, call(server.handlePauseDestination, "missing", "").Code)
+		assert.Equal(t, http.StatusNotFound, call(server.handleResumeDestination, "missing", "").Code)
+	})
+}
+
+func TestHandleDeliveries(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	cfg := &config.Config{
+		History: config.HistoryConfig{Enabled: true, Size: 10},
+		Destinations: []config.DestinationConfig{
+			{
+				Name:     "test-dest",
+				Method:   "POST",
+				URL:      target.URL,
+				Format:   "json",
+				Engine:   "go-template",
+				Template: `{}`,
+				Enabled:  true,
+			},
+		},
+	}
+
+	server, err := New(cfg, logrus.New())
+	require.NoError(t, err)
+	defer server.webhookHandler.Close()
+
+	sample := getSampleWebhookData()
+	body, err := json.Marshal(sample)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("POST", "/webhook/test-dest", bytes.NewReader(body))
+	req = mux.SetURLVars(req, map[string]string{"destination": "test-dest"})
+	w := httptest.NewRecorder()
+	server.webhookHandler.HandleWebhook(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var webhookResponse webhook.Response
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&webhookResponse))
+	require.NotEmpty(t, webhookResponse.DeliveryID)
+
+	list := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		server.handleListDeliveries(w, httptest.NewRequest("GET", "/deliveries"+query, nil))
+		return w
+	}
+
+	t.Run("query", func(t *testing.T) {
+		tests := []struct {
+			query string
+			want  int
+		}{
+			{query: "", want: 1},
+			{query: "?destination=test-dest&since=1h", want: 1},
+			{query: "?fingerprint=" + sample.Alerts[0].Fingerprint, want: 1},
+			{query: "?destination=other", want: 0},
+			{query: "?since=2999-01-01T00:00:00Z", want: 0},
+		}
+
+		for _, tt := range tests {
+			w := list(tt.query)
+			require.Equal(t, http.StatusOK, w.Code, tt.query)
+
+			var response DeliveriesResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+			assert.Equal(t, tt.want, response.Total, tt.query)
+		}
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, list("?since=yesterday").Code)
+		assert.Equal(t, http.StatusBadRequest, list("?limit=0").Code)
+	})
+
+	t.Run("get by id", func(t *testing.T) {
+		get := func(id string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("GET", "/deliveries/"+id, nil)
+			req = mux.SetURLVars(req, map[string]string{"id": id})
+			w := httptest.NewRecorder()
+			server.handleGetDelivery(w, req)
+			return w
+		}
+
+		w := get(webhookResponse.DeliveryID)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var record history.Record
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&record))
+		assert.Equal(t, "test-dest", record.Destination)
+		assert.Equal(t, http.StatusOK, record.StatusCode)
+		assert.Equal(t, 2, record.BodySize)
+
+		assert.Equal(t, http.StatusNotFound, get("missing").Code)
+	})
+}
+
+func TestHandleDeliveriesDisabled(t *testing.T) {
+	server, err := New(&config.Config{}, logrus.New())
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	server.handleListDeliveries(w, httptest.NewRequest("GET", "/deliveries", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
 func TestHandleTestDestination(t *testing.T) {
 	cfg := &config.Config{
 		Destinations: []config.DestinationConfig{
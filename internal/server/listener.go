@@ -0,0 +1,196 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/vitalvas/alertmanager-gateway/internal/config"
+)
+
+// listenFDsStart is the first file descriptor passed by systemd socket activation
+const listenFDsStart = 3
+
+// activatedFile is a socket inherited through systemd socket activation
+type activatedFile struct {
+	name string
+	file *os.File
+}
+
+// openListeners opens the main TCP listener and every additional configured listener
+func (s *Server) openListeners() ([]net.Listener, error) {
+	var listeners []net.Listener
+
+	closeAll := func() {
+		for _, l := range listeners {
+			l.Close()
+		}
+	}
+
+	if s.config.Server.Address != "" {
+		l, err := net.Listen("tcp", s.config.Server.Address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen on %s: %w", s.config.Server.Address, err)
+		}
+		listeners = append(listeners, l)
+	}
+
+	var activated []activatedFile
+	var activatedLoaded bool
+
+	// Listeners hold duplicates of the inherited descriptors
+	defer func() {
+		for _, f := range activated {
+			f.file.Close()
+		}
+	}()
+
+	for _, cfg := range s.config.Server.Listeners {
+		switch cfg.Type {
+		case config.ListenerTypeTCP:
+			l, err := net.Listen("tcp", cfg.Address)
+			if err != nil {
+				closeAll()
+				return nil, fmt.Errorf("failed to listen on %s: %w", cfg.Address, err)
+			}
+			listeners = append(listeners, l)
+
+		case config.ListenerTypeUnix:
+			mode, err := cfg.FileMode()
+			if err != nil {
+				closeAll()
+				return nil, err
+			}
+
+			l, err := listenUnix(cfg.Address, mode)
+			if err != nil {
+				closeAll()
+				return nil, err
+			}
+			listeners = append(listeners, l)
+
+		case config.ListenerTypeSystemd:
+			// The inherited sockets are read once and shared by all systemd listeners
+			if !activatedLoaded {
+				files, err := systemdFiles()
+				if err != nil {
+					closeAll()
+					return nil, err
+				}
+				activated = files
+				activatedLoaded = true
+			}
+
+			ls, err := systemdListeners(activated, cfg.Address)
+			if err != nil {
+				closeAll()
+				return nil, err
+			}
+			listeners = append(listeners, ls...)
+
+		default:
+			closeAll()
+			return nil, fmt.Errorf("unknown listener type: %s", cfg.Type)
+		}
+	}
+
+	if len(listeners) == 0 {
+		return nil, fmt.Errorf("no listeners configured")
+	}
+
+	return listeners, nil
+}
+
+// listenUnix listens on a unix domain socket, replacing a stale socket file left by a previous run
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("failed to listen on %s: file exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket %s: %w", path, err)
+		}
+	}
+
+	l, err := listenUnixPrivate(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+
+	if err := os.Chmod(path, mode); err != nil {
+		l.Close()
+		return nil, fmt.Errorf("failed to set socket mode on %s: %w", path, err)
+	}
+
+	return l, nil
+}
+
+// systemdFiles returns the sockets passed by systemd socket activation and clears the
+// activation environment so child processes do not inherit it
+func systemdFiles() ([]activatedFile, error) {
+	defer func() {
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+	}()
+
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, fmt.Errorf("no sockets passed by systemd socket activation")
+	}
+
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count <= 0 {
+		return nil, fmt.Errorf("no sockets passed by systemd socket activation")
+	}
+
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+
+	files := make([]activatedFile, 0, count)
+	for i := 0; i < count; i++ {
+		fd := listenFDsStart + i
+
+		name := ""
+		if i < len(names) {
+			name = names[i]
+		}
+
+		files = append(files, activatedFile{
+			name: name,
+			file: os.NewFile(uintptr(fd), "LISTEN_FD_"+strconv.Itoa(fd)),
+		})
+	}
+
+	return files, nil
+}
+
+// systemdListeners creates listeners for the inherited sockets matching name; an empty name matches all
+func systemdListeners(files []activatedFile, name string) ([]net.Listener, error) {
+	var listeners []net.Listener
+
+	for _, f := range files {
+		if name != "" && f.name != name {
+			continue
+		}
+
+		l, err := net.FileListener(f.file)
+		if err != nil {
+			for _, opened := range listeners {
+				opened.Close()
+			}
+			return nil, fmt.Errorf("failed to use systemd socket %s: %w", f.file.Name(), err)
+		}
+		listeners = append(listeners, l)
+	}
+
+	if len(listeners) == 0 {
+		if name != "" {
+			return nil, fmt.Errorf("no systemd socket named %s", name)
+		}
+		return nil, fmt.Errorf("no sockets passed by systemd socket activation")
+	}
+
+	return listeners, nil
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package server
+
+import "net"
+
+// listenUnixPrivate creates a unix domain socket; file permissions are left to the platform
+func listenUnixPrivate(path string) (net.Listener, error) {
+	return net.Listen("unix", path)
+}
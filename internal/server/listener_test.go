@@ -0,0 +1,159 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/alertmanager-gateway/internal/config"
+)
+
+func TestListenUnix(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("sets socket mode", func(t *testing.T) {
+		path := filepath.Join(dir, "mode.sock")
+
+		l, err := listenUnix(path, 0o600)
+		require.NoError(t, err)
+		defer l.Close()
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+		assert.NotZero(t, info.Mode()&os.ModeSocket)
+	})
+
+	t.Run("replaces stale socket", func(t *testing.T) {
+		path := filepath.Join(dir, "stale.sock")
+
+		stale, err := net.Listen("unix", path)
+		require.NoError(t, err)
+		stale.(*net.UnixListener).SetUnlinkOnClose(false)
+		stale.Close()
+
+		l, err := listenUnix(path, 0o660)
+		require.NoError(t, err)
+		l.Close()
+	})
+
+	t.Run("refuses regular file", func(t *testing.T) {
+		path := filepath.Join(dir, "regular")
+		require.NoError(t, os.WriteFile(path, []byte("data"), 0o600))
+
+		_, err := listenUnix(path, 0o660)
+		assert.ErrorContains(t, err, "not a socket")
+	})
+}
+
+func TestSystemdListeners(t *testing.T) {
+	tcp, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer tcp.Close()
+
+	file, err := tcp.(*net.TCPListener).File()
+	require.NoError(t, err)
+	defer file.Close()
+
+	files := []activatedFile{{name: "webhooks", file: file}}
+
+	tests := []struct {
+		name    string
+		match   string
+		wantLen int
+		wantErr string
+	}{
+		{name: "all sockets", wantLen: 1},
+		{name: "matching name", match: "webhooks", wantLen: 1},
+		{name: "unknown name", match: "admin", wantErr: "no systemd socket named admin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listeners, err := systemdListeners(files, tt.match)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Len(t, listeners, tt.wantLen)
+			for _, l := range listeners {
+				assert.Equal(t, tcp.Addr().String(), l.Addr().String())
+				l.Close()
+			}
+		})
+	}
+}
+
+func TestSystemdFilesNotActivated(t *testing.T) {
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()+1))
+	t.Setenv("LISTEN_FDS", "1")
+
+	_, err := systemdFiles()
+	assert.ErrorContains(t, err, "no sockets passed")
+	assert.Empty(t, os.Getenv("LISTEN_FDS"))
+}
+
+func TestOpenListeners(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "gateway.sock")
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Address: "127.0.0.1:0",
+			Listeners: []config.ListenerConfig{
+				{Type: config.ListenerTypeTCP, Address: "127.0.0.1:0"},
+				{Type: config.ListenerTypeUnix, Address: socketPath, SocketMode: "0660"},
+			},
+		},
+		Destinations: []config.DestinationConfig{
+			{
+				Name:     "test-dest",
+				Method:   "POST",
+				URL:      "https://example.com/webhook",
+				Format:   "json",
+				Engine:   "go-template",
+				Template: `{}`,
+				Enabled:  true,
+			},
+		},
+	}
+
+	server, err := New(cfg, logrus.New())
+	require.NoError(t, err)
+	defer server.webhookHandler.Close()
+
+	listeners, err := server.openListeners()
+	require.NoError(t, err)
+	require.Len(t, listeners, 3)
+
+	assert.Equal(t, "tcp", listeners[0].Addr().Network())
+	assert.Equal(t, "tcp", listeners[1].Addr().Network())
+	assert.Equal(t, "unix", listeners[2].Addr().Network())
+
+	for _, l := range listeners {
+		go server.httpServer.Serve(l)
+	}
+	defer server.httpServer.Shutdown(context.Background())
+
+	// The unix socket serves the same routes as the TCP listener
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", socketPath)
+			},
+		},
+	}
+
+	resp, err := client.Get("http://gateway/health")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
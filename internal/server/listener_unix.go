@@ -0,0 +1,17 @@
+//go:build unix
+
+package server
+
+import (
+	"net"
+	"syscall"
+)
+
+// listenUnixPrivate creates a unix domain socket that only its owner can connect to until
+// the configured mode is applied. The umask is process wide, so it is only held for the bind.
+func listenUnixPrivate(path string) (net.Listener, error) {
+	old := syscall.Umask(0o177)
+	defer syscall.Umask(old)
+
+	return net.Listen("unix", path)
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/vitalvas/alertmanager-gateway/internal/config"
+	"github.com/vitalvas/alertmanager-gateway/internal/webhook"
+	"gopkg.in/yaml.v3"
+)
+
+// maxDestinationBodySize limits the size of destination management requests
+const maxDestinationBodySize = 1 << 20
+
+// RegisterManagementRoutes registers destination management routes with the router
+func (s *Server) RegisterManagementRoutes(router *mux.Router) {
+	router.HandleFunc("/destinations", s.handleCreateDestination).Methods(http.MethodPost)
+	router.HandleFunc("/destinations/{name}", s.handleUpdateDestination).Methods(http.MethodPut)
+	router.HandleFunc("/destinations/{name}", s.handlePatchDestination).Methods(http.MethodPatch)
+	router.HandleFunc("/destinations/{name}", s.handleDeleteDestination).Methods(http.MethodDelete)
+}
+
+func (s *Server) handleCreateDestination(w http.ResponseWriter, r *http.Request) {
+	// New destinations are enabled unless the request disables them
+	dest := config.DestinationConfig{Enabled: true}
+	if err := decodeDestination(r, &dest); err != nil {
+		s.sendAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	created, err := s.webhookHandler.CreateDestination(dest)
+	if err != nil {
+		s.sendManagementError(w, err)
+		return
+	}
+
+	s.sendJSON(w, http.StatusCreated, newDestinationDetails(&created))
+}
+
+func (s *Server) handleUpdateDestination(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	dest := config.DestinationConfig{Name: name, Enabled: true}
+	if err := decodeDestination(r, &dest); err != nil {
+		s.sendAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.updateDestination(w, name, dest)
+}
+
+func (s *Server) handlePatchDestination(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	dest, exists := s.webhookHandler.Destination(name)
+	if !exists {
+		s.sendAPIError(w, http.StatusNotFound, "Destination not found")
+		return
+	}
+
+	// Content read from files is read again, unless the request replaces it inline
+	templateFile, transformFile := dest.TemplateFile, dest.TransformFile
+	dest.ClearFileContent()
+
+	// Fields present in the request replace the current values
+	if err := decodeDestination(r, &dest); err != nil {
+		s.sendAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if dest.Template != "" && dest.TemplateFile == templateFile {
+		dest.TemplateFile = ""
+	}
+	if dest.Transform != "" && dest.TransformFile == transformFile {
+		dest.TransformFile = ""
+	}
+
+	s.updateDestination(w, name, dest)
+}
+
+func (s *Server) handleDeleteDestination(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	if err := s.webhookHandler.DeleteDestination(name); err != nil {
+		s.sendManagementError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) updateDestination(w http.ResponseWriter, name string, dest config.DestinationConfig) {
+	updated, err := s.webhookHandler.UpdateDestination(name, dest)
+	if err != nil {
+		s.sendManagementError(w, err)
+		return
+	}
+
+	s.sendJSON(w, http.StatusOK, newDestinationDetails(&updated))
+}
+
+func (s *Server) sendManagementError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, webhook.ErrDestinationNotFound):
+		s.sendAPIError(w, http.StatusNotFound, "Destination not found")
+	case errors.Is(err, webhook.ErrDestinationExists):
+		s.sendAPIError(w, http.StatusConflict, "Destination already exists")
+	case errors.Is(err, webhook.ErrInvalidDestination):
+		s.sendAPIError(w, http.StatusBadRequest, err.Error())
+	default:
+		s.logger.WithError(err).Error("Failed to apply destination change")
+		s.sendAPIError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// decodeDestination decodes a JSON or YAML destination using the configuration file field names
+func decodeDestination(r *http.Request, dest *config.DestinationConfig) error {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxDestinationBodySize))
+	if err != nil {
+		return fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	// YAML is a superset of JSON, so both are accepted with the same field names and durations like "30s"
+	if err := yaml.Unmarshal(body, dest); err != nil {
+		return fmt.Errorf("invalid destination payload: %w", err)
+	}
+
+	return nil
+}
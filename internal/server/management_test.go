@@ -0,0 +1,251 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/alertmanager-gateway/internal/config"
+)
+
+func TestManagementRoutes(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Address: ":8080",
+			Auth:    config.AuthConfig{Enabled: true, Username: "admin", Password: "secret"},
+		},
+		Management: config.ManagementConfig{Enabled: true},
+		Destinations: []config.DestinationConfig{
+			{Name: "static", URL: "https://example.com", Method: "POST", Format: "json", Engine: "go-template", Template: "{}", Enabled: true},
+		},
+	}
+
+	server, err := New(cfg, logrus.New())
+	require.NoError(t, err)
+	defer server.webhookHandler.Close()
+
+	do := func(method, path, body string, authenticated bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if authenticated {
+			req.SetBasicAuth("admin", "secret")
+		}
+		w := httptest.NewRecorder()
+		server.GetRouter().ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("requires authentication", func(t *testing.T) {
+		w := do(http.MethodPost, "/api/v1/destinations", `{"name": "new"}`, false)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("create", func(t *testing.T) {
+		w := do(http.MethodPost, "/api/v1/destinations",
+			`{"name": "dynamic", "url": "https://hooks.example.com", "template": "{}", "repeat_interval": "1h"}`, true)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var details DestinationDetails
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&details))
+		assert.Equal(t, "dynamic", details.Name)
+		assert.Equal(t, 1, details.Version)
+		assert.True(t, details.Enabled)
+		assert.False(t, details.CreatedAt.IsZero())
+
+		dest, exists := server.webhookHandler.Destination("dynamic")
+		require.True(t, exists)
+		assert.Equal(t, "1h0m0s", dest.RepeatInterval.String())
+	})
+
+	t.Run("create duplicate", func(t *testing.T) {
+		w := do(http.MethodPost, "/api/v1/destinations", `{"name": "dynamic", "url": "https://hooks.example.com", "template": "{}"}`, true)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("create invalid", func(t *testing.T) {
+		w := do(http.MethodPost, "/api/v1/destinations", `{"name": "broken", "template": "{}"}`, true)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("patch disables", func(t *testing.T) {
+		w := do(http.MethodPatch, "/api/v1/destinations/dynamic", `{"enabled": false}`, true)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var details DestinationDetails
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&details))
+		assert.False(t, details.Enabled)
+		assert.Equal(t, 2, details.Version)
+		assert.Contains(t, details.TargetURL, "https://hooks")
+	})
+
+	t.Run("put replaces", func(t *testing.T) {
+		w := do(http.MethodPut, "/api/v1/destinations/dynamic", `{"url": "https://other.example.com", "engine": "jq", "transform": "."}`, true)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		dest, _ := server.webhookHandler.Destination("dynamic")
+		assert.True(t, dest.Enabled)
+		assert.Equal(t, "jq", dest.Engine)
+		assert.Equal(t, 3, dest.Version)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		w := do(http.MethodDelete, "/api/v1/destinations/dynamic", "", true)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		w = do(http.MethodGet, "/api/v1/destinations/dynamic", "", true)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = do(http.MethodDelete, "/api/v1/destinations/dynamic", "", true)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestManagementRoutesDisabled(t *testing.T) {
+	cfg := &config.Config{
+		Destinations: []config.DestinationConfig{
+			{Name: "static", URL: "https://example.com", Method: "POST", Format: "json", Engine: "go-template", Template: "{}", Enabled: true},
+		},
+	}
+
+	server, err := New(cfg, logrus.New())
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/destinations/static", nil)
+	w := httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(w, req)
+	assert.NotEqual(t, http.StatusNoContent, w.Code)
+
+	_, exists := server.webhookHandler.Destination("static")
+	assert.True(t, exists)
+}
+
+func TestManagementRoutes_SourceFiles(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "chat.tmpl"), []byte(`{"text": "{{ .Status }}"}`), 0o600))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sources"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sources", "tickets.jq"), []byte(`{summary: .status}`), 0o600))
+	require.NoError(t, os.Symlink(configPath, filepath.Join(dir, "sources", "config.tmpl")))
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+server:
+  auth:
+    enabled: true
+    username: admin
+    password: secret
+management:
+  enabled: true
+  overlay_file: `+filepath.Join(dir, "overlay.yaml")+`
+  source_directory: sources
+destinations:
+  - name: chat
+    url: https://chat.example.com/hook
+    template_file: chat.tmpl
+`), 0o600))
+
+	cfg, err := config.LoadConfig(configPath)
+	require.NoError(t, err)
+
+	server, err := New(cfg, logrus.New())
+	require.NoError(t, err)
+	defer server.webhookHandler.Close()
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.SetBasicAuth("admin", "secret")
+		w := httptest.NewRecorder()
+		server.GetRouter().ServeHTTP(w, req)
+		return w
+	}
+
+	reload := func(t *testing.T) *config.Config {
+		t.Helper()
+
+		cfg, err := config.LoadConfig(configPath)
+		require.NoError(t, err)
+		return cfg
+	}
+
+	t.Run("update keeps the file", func(t *testing.T) {
+		w := do(http.MethodPatch, "/api/v1/destinations/chat", `{"url": "https://chat.example.com/alerts"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		dest := reload(t).Destinations[0]
+		assert.Equal(t, "https://chat.example.com/alerts", dest.URL)
+		assert.Equal(t, filepath.Join(dir, "chat.tmpl"), dest.TemplateFile)
+		assert.Equal(t, `{"text": "{{ .Status }}"}`, dest.Template)
+	})
+
+	t.Run("inline content replaces the file", func(t *testing.T) {
+		w := do(http.MethodPatch, "/api/v1/destinations/chat", `{"template": "{\"text\": \"inline\"}"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		dest := reload(t).Destinations[0]
+		assert.Empty(t, dest.TemplateFile)
+		assert.Equal(t, `{"text": "inline"}`, dest.Template)
+	})
+
+	t.Run("create resolves files", func(t *testing.T) {
+		w := do(http.MethodPost, "/api/v1/destinations",
+			`{"name": "tickets", "url": "https://jira.example.com", "transform_file": "tickets.jq"}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		dest, _ := server.webhookHandler.Destination("tickets")
+		assert.Equal(t, "jq", dest.Engine)
+		assert.Equal(t, `{summary: .status}`, dest.Transform)
+
+		loaded := reload(t).Destinations[1]
+		assert.Equal(t, filepath.Join(dir, "sources", "tickets.jq"), loaded.TransformFile)
+		assert.Equal(t, `{summary: .status}`, loaded.Transform)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		w := do(http.MethodPost, "/api/v1/destinations",
+			`{"name": "broken", "url": "https://jira.example.com", "transform_file": "missing.jq"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "failed to read transform_file")
+	})
+
+	t.Run("files outside the source directory", func(t *testing.T) {
+		for _, file := range []string{"../config.yaml", "../sources/../config.yaml", configPath, "/etc/passwd", "config.tmpl"} {
+			w := do(http.MethodPost, "/api/v1/destinations",
+				`{"name": "leak", "url": "https://attacker.example.com", "template_file": "`+file+`"}`)
+			assert.Equal(t, http.StatusBadRequest, w.Code, file)
+			assert.Contains(t, w.Body.String(), "failed to read template_file", file)
+
+			_, exists := server.webhookHandler.Destination("leak")
+			assert.False(t, exists, file)
+		}
+	})
+}
+
+func TestManagementRoutes_SourceFilesDisabled(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "chat.tmpl"), []byte(`{}`), 0o600))
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Address: ":8080",
+			Auth:    config.AuthConfig{Enabled: true, Username: "admin", Password: "secret"},
+		},
+		Management: config.ManagementConfig{Enabled: true},
+	}
+
+	server, err := New(cfg, logrus.New())
+	require.NoError(t, err)
+	defer server.webhookHandler.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/destinations",
+		strings.NewReader(`{"name": "chat", "url": "https://chat.example.com", "template_file": "`+filepath.Join(dir, "chat.tmpl")+`"}`))
+	req.SetBasicAuth("admin", "secret")
+	w := httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "template_file requires management.source_directory")
+}
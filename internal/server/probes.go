@@ -0,0 +1,154 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Probe statuses
+const (
+	probeStatusOK      = "ok"
+	probeStatusFailing = "failing"
+)
+
+// isProbePath reports whether a path is a health check or probe endpoint
+func isProbePath(path string) bool {
+	switch path {
+	case "/health", "/livez", "/readyz", "/startupz":
+		return true
+	}
+	return false
+}
+
+// handleLivez reports that the process is running and serving requests
+func (s *Server) handleLivez(w http.ResponseWriter, _ *http.Request) {
+	s.sendJSON(w, http.StatusOK, ProbeResponse{
+		Status:    probeStatusOK,
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+// handleStartupz reports whether the listeners are open and every destination probe ran once
+func (s *Server) handleStartupz(w http.ResponseWriter, _ *http.Request) {
+	s.sendProbe(w, []HealthCheck{s.startupCheck()}, nil)
+}
+
+// handleReadyz reports whether the gateway can accept and deliver alerts.
+// Failing destinations only fail readiness when health.destination_readiness is set.
+func (s *Server) handleReadyz(w http.ResponseWriter, _ *http.Request) {
+	checks := []HealthCheck{
+		s.configCheck(),
+		s.startupCheck(),
+	}
+
+	if s.webhookHandler.Draining() {
+		checks = append(checks, HealthCheck{Name: "shutdown", Status: "error", Message: "Gateway is shutting down"})
+	}
+
+	if limit := s.config.Health.MaxQueueBacklog; limit > 0 {
+		backlog := s.webhookHandler.QueueBacklog()
+		check := HealthCheck{
+			Name:    "queue_backlog",
+			Status:  "healthy",
+			Message: fmt.Sprintf("%d payloads waiting, limit %d", backlog, limit),
+		}
+		if backlog > limit {
+			check.Status = "error"
+		}
+		checks = append(checks, check)
+	}
+
+	destinations := s.destinationReadiness()
+
+	failing := make([]string, 0)
+	for name, readiness := range destinations {
+		if !readiness.Ready {
+			failing = append(failing, name)
+		}
+	}
+	sort.Strings(failing)
+
+	check := HealthCheck{
+		Name:    "destinations",
+		Status:  "healthy",
+		Message: fmt.Sprintf("%d destinations ready", len(destinations)),
+	}
+	if len(failing) > 0 {
+		check.Status = "warning"
+		if s.config.Health.DestinationReadiness {
+			check.Status = "error"
+		}
+		check.Message = fmt.Sprintf("%d destinations failing: %s", len(failing), strings.Join(failing, ", "))
+	}
+	checks = append(checks, check)
+
+	s.sendProbe(w, checks, destinations)
+}
+
+// startupCheck reports whether the server finished starting
+func (s *Server) startupCheck() HealthCheck {
+	switch {
+	case !s.started.Load():
+		return HealthCheck{Name: "startup", Status: "error", Message: "Listeners are not open yet"}
+	case !s.prober.Initialized():
+		return HealthCheck{Name: "startup", Status: "error", Message: "Destination health checks have not completed yet"}
+	default:
+		return HealthCheck{Name: "startup", Status: "healthy", Message: "Started"}
+	}
+}
+
+// destinationReadiness evaluates the delivery failures and probe results of enabled destinations
+func (s *Server) destinationReadiness() map[string]DestinationReadiness {
+	threshold := s.config.Health.FailureThreshold
+	result := make(map[string]DestinationReadiness)
+
+	for _, dest := range s.webhookHandler.Destinations() {
+		if !dest.Enabled {
+			continue
+		}
+
+		readiness := DestinationReadiness{
+			Ready:               true,
+			ConsecutiveFailures: s.webhookHandler.ConsecutiveFailures(dest.Name),
+		}
+
+		if threshold > 0 && readiness.ConsecutiveFailures >= threshold {
+			readiness.Ready = false
+		}
+
+		if probe, ok := s.prober.Result(dest.Name); ok {
+			readiness.Probe = &probe
+			if !probe.Reachable {
+				readiness.Ready = false
+			}
+		}
+
+		result[dest.Name] = readiness
+	}
+
+	return result
+}
+
+// sendProbe responds with 200 when every check is healthy and 503 otherwise
+func (s *Server) sendProbe(w http.ResponseWriter, checks []HealthCheck, destinations map[string]DestinationReadiness) {
+	response := ProbeResponse{
+		Status:       probeStatusOK,
+		Timestamp:    time.Now().UTC(),
+		Checks:       checks,
+		Destinations: destinations,
+	}
+
+	statusCode := http.StatusOK
+	for _, check := range checks {
+		if check.Status == "error" {
+			response.Status = probeStatusFailing
+			statusCode = http.StatusServiceUnavailable
+			break
+		}
+	}
+
+	s.sendJSON(w, statusCode, response)
+}
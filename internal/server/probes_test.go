@@ -0,0 +1,213 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/alertmanager-gateway/internal/config"
+)
+
+func TestProbes(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	newDestination := func(name, url, probeURL string) config.DestinationConfig {
+		dest := config.DestinationConfig{
+			Name:     name,
+			URL:      url,
+			Method:   "POST",
+			Format:   "json",
+			Engine:   "go-template",
+			Template: `{"status": "{{ .Status }}"}`,
+			Enabled:  true,
+		}
+		if probeURL != "" {
+			dest.HealthCheck = config.HealthCheckConfig{URL: probeURL, Method: http.MethodHead, Interval: time.Minute, Timeout: time.Second}
+		}
+		return dest
+	}
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{Address: ":8080"},
+		Health: config.HealthConfig{FailureThreshold: 2},
+		Destinations: []config.DestinationConfig{
+			newDestination("chat", upstream.URL, upstream.URL+"/"),
+			newDestination("pager", upstream.URL+"/down", ""),
+		},
+	}
+
+	server, err := New(cfg, logrus.New())
+	require.NoError(t, err)
+	defer server.webhookHandler.Close()
+
+	get := func(path string) (int, ProbeResponse) {
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+
+		var response ProbeResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+
+	code, response := get("/livez")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", response.Status)
+
+	// Not started until the listeners are open and the destination probes ran
+	code, response = get("/startupz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "failing", response.Status)
+
+	server.started.Store(true)
+	code, _ = get("/startupz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+
+	server.prober.Start()
+	server.prober.Stop()
+
+	code, _ = get("/startupz")
+	assert.Equal(t, http.StatusOK, code)
+
+	code, response = get("/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	require.Contains(t, response.Destinations, "chat")
+	require.NotNil(t, response.Destinations["chat"].Probe)
+	assert.True(t, response.Destinations["chat"].Probe.Reachable)
+	assert.Nil(t, response.Destinations["pager"].Probe)
+
+	// Repeated delivery failures make the destination not ready, but only fail readiness when enabled
+	body := `{"version":"4","groupKey":"g","status":"firing","alerts":[{"status":"firing","labels":{"alertname":"Test"},"fingerprint":"abc","startsAt":"2024-01-01T00:00:00Z"}]}`
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("POST", "/webhook/pager", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		server.router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	destinationsCheck := func(response ProbeResponse) HealthCheck {
+		for _, check := range response.Checks {
+			if check.Name == "destinations" {
+				return check
+			}
+		}
+		return HealthCheck{}
+	}
+
+	code, response = get("/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", response.Status)
+	assert.True(t, response.Destinations["chat"].Ready)
+	assert.False(t, response.Destinations["pager"].Ready)
+	assert.Equal(t, 2, response.Destinations["pager"].ConsecutiveFailures)
+	assert.Equal(t, "warning", destinationsCheck(response).Status)
+	assert.Contains(t, destinationsCheck(response).Message, "pager")
+
+	server.config.Health.DestinationReadiness = true
+
+	code, response = get("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "failing", response.Status)
+	assert.Equal(t, "error", destinationsCheck(response).Status)
+}
+
+func TestReadyzDuringShutdown(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{Address: ":8080"},
+		Destinations: []config.DestinationConfig{
+			{Name: "chat", URL: "http://example.com", Method: "POST", Format: "json", Engine: "go-template", Template: `{}`, Enabled: true},
+		},
+	}
+
+	server, err := New(cfg, logrus.New())
+	require.NoError(t, err)
+	defer server.webhookHandler.Close()
+	server.started.Store(true)
+
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	server.webhookHandler.StartDrain()
+
+	w = httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "Gateway is shutting down")
+
+	// Liveness is unaffected while draining
+	w = httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("GET", "/livez", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestReadyzAfterFailedReload(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+
+	writeConfig := func(template string) {
+		require.NoError(t, os.WriteFile(configPath, []byte(`
+destinations:
+  - name: chat
+    url: https://chat.example.com
+    template: "`+template+`"
+`), 0o600))
+	}
+
+	writeConfig("{}")
+	cfg, err := config.LoadConfig(configPath)
+	require.NoError(t, err)
+
+	server, err := New(cfg, logrus.New())
+	require.NoError(t, err)
+	defer server.webhookHandler.Close()
+	server.started.Store(true)
+
+	configCheck := func() (int, HealthCheck) {
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+
+		var response ProbeResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		for _, check := range response.Checks {
+			if check.Name == "config" {
+				return w.Code, check
+			}
+		}
+		t.Fatal("config check missing")
+		return 0, HealthCheck{}
+	}
+
+	_, check := configCheck()
+	assert.Equal(t, "healthy", check.Status)
+
+	// The previous configuration keeps serving, so a failed reload is a warning
+	writeConfig("{{ .Status ")
+	_, err = server.ReloadConfig(context.Background())
+	require.Error(t, err)
+
+	code, check := configCheck()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "warning", check.Status)
+	assert.Contains(t, check.Message, "Reload failed")
+
+	writeConfig("{}")
+	_, err = server.ReloadConfig(context.Background())
+	require.NoError(t, err)
+
+	_, check = configCheck()
+	assert.Equal(t, "healthy", check.Status)
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/vitalvas/alertmanager-gateway/internal/audit"
+	"github.com/vitalvas/alertmanager-gateway/internal/config"
+)
+
+// errNoConfigFile is returned when reloading a configuration that was not loaded from a file
+var errNoConfigFile = errors.New("configuration was not loaded from a file")
+
+// ReloadConfig re-reads the configuration file and applies its templates, time intervals and
+// destinations. The running configuration is kept when the file is invalid.
+func (s *Server) ReloadConfig(ctx context.Context) (*ConfigReloadResponse, error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	path := s.config.Path()
+	if path == "" {
+		return nil, errNoConfigFile
+	}
+
+	// Destinations managed through the API are merged again from the running overlay
+	var cfg *config.Config
+	var loadErr error
+	result, err := s.webhookHandler.ReloadFrom(ctx, func(overlay *config.Overlay) (*config.Config, error) {
+		cfg, loadErr = config.LoadConfigWithOverlay(path, overlay)
+		return cfg, loadErr
+	})
+	if loadErr == nil && err != nil {
+		loadErr = fmt.Errorf("failed to apply configuration: %w", err)
+	}
+	if loadErr != nil {
+		s.reloadErr = loadErr
+		s.reloadFailedAt = time.Now().UTC()
+		return nil, loadErr
+	}
+
+	s.configLoadedAt = time.Now().UTC()
+	s.reloadErr = nil
+	s.watched = cfg.WatchedFiles()
+
+	return &ConfigReloadResponse{
+		Success:      true,
+		Destinations: result.Destinations,
+		Templates:    result.Templates,
+		Modules:      result.Modules,
+		Timestamp:    s.configLoadedAt,
+	}, nil
+}
+
+// reloadAudited reloads the configuration outside an API request and writes an audit event
+// with actor naming the trigger, such as "signal" or "watch"
+func (s *Server) reloadAudited(actor string) {
+	ctx := context.Background()
+	event := audit.Event{Actor: actor, Action: "config.reload", Result: "success"}
+
+	if _, err := s.ReloadConfig(ctx); err != nil {
+		s.logger.WithError(err).Error("Failed to reload configuration")
+		event.Result = "error"
+		event.Details = map[string]interface{}{"error": err.Error()}
+	}
+
+	if err := s.audit.Log(ctx, event); err != nil {
+		s.logger.WithError(err).Error("Failed to write audit event")
+	}
+}
+
+// lastConfigReload returns when the configuration was last loaded
+func (s *Server) lastConfigReload() time.Time {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	return s.configLoadedAt
+}
+
+// configCheck reports the outcome of the last configuration load or reload. A failed reload
+// is a warning, as the previous configuration keeps serving.
+func (s *Server) configCheck() HealthCheck {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	if s.reloadErr != nil {
+		return HealthCheck{
+			Name:   "config",
+			Status: "warning",
+			Message: fmt.Sprintf("Reload failed at %s, running configuration loaded at %s: %v",
+				s.reloadFailedAt.Format(time.RFC3339), s.configLoadedAt.Format(time.RFC3339), s.reloadErr),
+		}
+	}
+
+	return HealthCheck{
+		Name:    "config",
+		Status:  "healthy",
+		Message: fmt.Sprintf("Configuration loaded at %s", s.configLoadedAt.Format(time.RFC3339)),
+	}
+}
+
+func (s *Server) handleReloadConfig(w http.ResponseWriter, r *http.Request) {
+	response, err := s.ReloadConfig(r.Context())
+	if err != nil {
+		if errors.Is(err, errNoConfigFile) {
+			s.sendAPIError(w, http.StatusConflict, err.Error())
+			return
+		}
+
+		s.logger.WithContext(r.Context()).WithError(err).Error("Failed to reload configuration")
+		s.sendAPIError(w, http.StatusBadRequest, fmt.Sprintf("Reload failed: %v", err))
+		return
+	}
+
+	response.RequestID = responseRequestID(w)
+	s.sendJSON(w, http.StatusOK, response)
+}
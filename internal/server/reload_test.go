@@ -0,0 +1,220 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/alertmanager-gateway/internal/audit"
+	"github.com/vitalvas/alertmanager-gateway/internal/config"
+)
+
+func TestReloadConfig(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+
+	writeConfig := func(content string) {
+		require.NoError(t, os.WriteFile(configPath, []byte(content), 0o600))
+	}
+
+	writeConfig(`
+destinations:
+  - name: chat
+    url: https://chat.example.com
+    template: "{}"
+`)
+
+	cfg, err := config.LoadConfig(configPath)
+	require.NoError(t, err)
+
+	server, err := New(cfg, logrus.New())
+	require.NoError(t, err)
+	defer server.webhookHandler.Close()
+
+	reload := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		server.GetRouter().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/config/reload", nil))
+		return w
+	}
+
+	t.Run("applies templates and destinations", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "title.tmpl"), []byte(`{{ .Status }}`), 0o600))
+		writeConfig(`
+templates:
+  directory: ` + dir + `
+  jq:
+    utils: "def twice: . * 2;"
+destinations:
+  - name: chat
+    url: https://chat.example.com
+    template: '{"title": "{{ template "title" . }}"}'
+  - name: pager
+    url: https://pager.example.com
+    template: "{}"
+`)
+
+		w := reload()
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var response ConfigReloadResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.True(t, response.Success)
+		assert.Equal(t, 2, response.Destinations)
+		assert.Equal(t, []string{"title"}, response.Templates)
+		assert.Equal(t, []string{"utils"}, response.Modules)
+
+		_, exists := server.webhookHandler.Destination("pager")
+		assert.True(t, exists)
+	})
+
+	t.Run("invalid configuration is rejected", func(t *testing.T) {
+		writeConfig(`
+destinations:
+  - name: chat
+    url: https://chat.example.com
+    template: '{{ template "missing" . }'
+`)
+
+		w := reload()
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		_, exists := server.webhookHandler.Destination("pager")
+		assert.True(t, exists)
+	})
+
+	t.Run("configuration built in code", func(t *testing.T) {
+		inline, err := New(&config.Config{Server: config.ServerConfig{Address: ":8080"}}, logrus.New())
+		require.NoError(t, err)
+		defer inline.webhookHandler.Close()
+
+		w := httptest.NewRecorder()
+		inline.GetRouter().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/config/reload", nil))
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestReloadConfig_KeepsRuntimeState(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+
+	writeConfig := func(chatURL string) {
+		require.NoError(t, os.WriteFile(configPath, []byte(`
+server:
+  auth:
+    enabled: true
+    username: admin
+    password: secret
+management:
+  enabled: true
+destinations:
+  - name: chat
+    url: `+chatURL+`
+    template: "{}"
+  - name: pager
+    url: https://pager.example.com
+    template: "{}"
+`), 0o600))
+	}
+
+	writeConfig("https://chat.example.com")
+	cfg, err := config.LoadConfig(configPath)
+	require.NoError(t, err)
+
+	server, err := New(cfg, logrus.New())
+	require.NoError(t, err)
+	defer server.webhookHandler.Close()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/destinations", strings.NewReader(`{"name": "dynamic", "url": "https://hooks.example.com", "template": "{}"}`))
+	req.SetBasicAuth("admin", "secret")
+	server.GetRouter().ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	before := make(map[string]config.DestinationConfig)
+	for _, dest := range server.webhookHandler.Destinations() {
+		before[dest.Name] = dest
+	}
+
+	writeConfig("https://chat.example.com/alerts")
+	_, err = server.ReloadConfig(context.Background())
+	require.NoError(t, err)
+
+	// Destinations created through the API survive the reload without an overlay file
+	dynamic, exists := server.webhookHandler.Destination("dynamic")
+	require.True(t, exists)
+	assert.Equal(t, before["dynamic"].Version, dynamic.Version)
+	assert.Equal(t, before["dynamic"].CreatedAt, dynamic.CreatedAt)
+
+	// Unchanged destinations keep their metadata and changed ones get the next version
+	pager, _ := server.webhookHandler.Destination("pager")
+	assert.Equal(t, 1, pager.Version)
+	assert.Equal(t, before["pager"].CreatedAt, pager.CreatedAt)
+	assert.Equal(t, before["pager"].UpdatedAt, pager.UpdatedAt)
+
+	chat, _ := server.webhookHandler.Destination("chat")
+	assert.Equal(t, 2, chat.Version)
+	assert.Equal(t, before["chat"].CreatedAt, chat.CreatedAt)
+	assert.True(t, chat.UpdatedAt.After(before["chat"].UpdatedAt))
+}
+
+func TestReloadConfig_Audit(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	auditFile := filepath.Join(dir, "audit.jsonl")
+
+	writeConfig := func(template string) {
+		require.NoError(t, os.WriteFile(configPath, []byte(`
+audit:
+  enabled: true
+  file: `+auditFile+`
+destinations:
+  - name: chat
+    url: https://chat.example.com
+    template: "`+template+`"
+`), 0o600))
+	}
+
+	writeConfig("{}")
+	cfg, err := config.LoadConfig(configPath)
+	require.NoError(t, err)
+
+	server, err := New(cfg, logrus.New())
+	require.NoError(t, err)
+	defer server.webhookHandler.Close()
+
+	w := httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/config/reload", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	server.reloadAudited("watch")
+
+	writeConfig("{{ .Status ")
+	server.reloadAudited("signal")
+	require.NoError(t, server.audit.Close())
+
+	data, err := os.ReadFile(auditFile)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 3)
+
+	events := make([]audit.Event, len(lines))
+	for i, line := range lines {
+		require.NoError(t, json.Unmarshal([]byte(line), &events[i]))
+		assert.Equal(t, "config.reload", events[i].Action)
+	}
+
+	assert.Equal(t, "success", events[0].Result)
+	assert.Equal(t, "watch", events[1].Actor)
+	assert.Equal(t, "success", events[1].Result)
+	assert.Equal(t, "signal", events[2].Actor)
+	assert.Equal(t, "error", events[2].Result)
+	assert.Contains(t, events[2].Details["error"], "failed to parse template")
+}
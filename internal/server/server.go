@@ -3,16 +3,25 @@ package server
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	_ "net/http/pprof" // Enable pprof endpoints for profiling
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
+	"github.com/vitalvas/alertmanager-gateway/internal/audit"
 	"github.com/vitalvas/alertmanager-gateway/internal/config"
+	"github.com/vitalvas/alertmanager-gateway/internal/health"
+	"github.com/vitalvas/alertmanager-gateway/internal/requestid"
+	"github.com/vitalvas/alertmanager-gateway/internal/tracing"
+	"github.com/vitalvas/alertmanager-gateway/internal/transform"
 	"github.com/vitalvas/alertmanager-gateway/internal/webhook"
 )
 
@@ -21,18 +30,52 @@ type Server struct {
 	config         *config.Config
 	router         *mux.Router
 	httpServer     *http.Server
+	adminRouter    *mux.Router
+	adminServer    *http.Server
 	logger         *logrus.Logger
 	webhookHandler *webhook.Handler
+	audit          *audit.Logger
+	tracerShutdown func(context.Context) error
+	prober         *health.Prober
+	started        atomic.Bool
 	hostname       string
+
+	// reloadMu serialises configuration reloads and guards configLoadedAt, reloadErr and watched
+	reloadMu       sync.Mutex
+	configLoadedAt time.Time
+	reloadErr      error
+	reloadFailedAt time.Time
+	watched        []string
 }
 
 // New creates a new server instance
 func New(cfg *config.Config, logger *logrus.Logger) (*Server, error) {
+	transform.InitTemplateCache(cfg.Templates.CacheSize, cfg.Templates.CacheTTL)
+
 	webhookHandler, err := webhook.NewHandler(cfg, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create webhook handler: %w", err)
 	}
 
+	var auditLogger *audit.Logger
+	if cfg.Audit.Enabled {
+		auditLogger, err = audit.New(cfg.Audit.File)
+		if err != nil {
+			webhookHandler.Close()
+			return nil, err
+		}
+	}
+
+	var tracerShutdown func(context.Context) error
+	if cfg.Tracing.Enabled {
+		tracerShutdown, err = tracing.Setup(context.Background(), cfg.Tracing, version)
+		if err != nil {
+			webhookHandler.Close()
+			auditLogger.Close()
+			return nil, fmt.Errorf("failed to setup tracing: %w", err)
+		}
+	}
+
 	hostname, err := os.Hostname()
 	if err != nil {
 		hostname = "unknown"
@@ -43,79 +86,199 @@ func New(cfg *config.Config, logger *logrus.Logger) (*Server, error) {
 		logger:         logger,
 		router:         mux.NewRouter(),
 		webhookHandler: webhookHandler,
+		audit:          auditLogger,
+		tracerShutdown: tracerShutdown,
+		prober:         health.NewProber(webhookHandler.Destinations, logger),
 		hostname:       hostname,
+		configLoadedAt: time.Now().UTC(),
+		watched:        cfg.WatchedFiles(),
+	}
+
+	if cfg.Server.HasAdminListener() {
+		s.adminRouter = mux.NewRouter()
 	}
 
 	// Setup routes
 	s.setupRoutes()
 
 	// Setup middleware
-	s.router.Use(s.securityHeadersMiddleware)
-	s.router.Use(s.loggingMiddleware)
-	s.router.Use(s.recoveryMiddleware)
-
-	// Create HTTP server
-	s.httpServer = &http.Server{
-		Addr:         cfg.Server.Address,
-		Handler:      s.router,
-		ReadTimeout:  cfg.Server.ReadTimeout,
-		WriteTimeout: cfg.Server.WriteTimeout,
-		IdleTimeout:  120 * time.Second,
+	s.useMiddleware(s.router)
+	s.httpServer = s.newHTTPServer(cfg.Server.Address, s.router)
+
+	if s.adminRouter != nil {
+		s.useMiddleware(s.adminRouter)
+		if cfg.Server.AdminAuth.Enabled {
+			s.adminRouter.Use(s.adminAuthMiddleware)
+		}
+		s.adminServer = s.newHTTPServer(cfg.Server.AdminAddress, s.adminRouter)
 	}
 
 	return s, nil
 }
 
+// useMiddleware installs the middleware shared by the public and admin routers
+func (s *Server) useMiddleware(router *mux.Router) {
+	router.Use(requestid.Middleware)
+	router.Use(tracing.Middleware)
+	router.Use(s.securityHeadersMiddleware)
+	router.Use(s.loggingMiddleware)
+	router.Use(s.recoveryMiddleware)
+}
+
+// newHTTPServer creates an HTTP server for a listener address
+func (s *Server) newHTTPServer(addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:         addr,
+		Handler:      handler,
+		ReadTimeout:  s.config.Server.ReadTimeout,
+		WriteTimeout: s.config.Server.WriteTimeout,
+		IdleTimeout:  120 * time.Second,
+	}
+}
+
 // Run starts the server with graceful shutdown
 func (s *Server) Run() error {
 	// Channel to listen for interrupt signals
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 
-	// Channel to capture server errors
-	serverErr := make(chan error, 1)
+	// SIGHUP reloads the configuration file
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	defer signal.Stop(reload)
 
-	// Start server in a goroutine
-	go func() {
-		s.logger.WithFields(logrus.Fields{
-			"addr": s.httpServer.Addr,
-		}).Info("Starting HTTP server")
+	listeners, err := s.openListeners()
+	if err != nil {
+		return err
+	}
 
-		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			serverErr <- err
+	// Channel to capture server errors
+	serverErr := make(chan error, len(listeners)+1)
+
+	if s.adminServer != nil {
+		adminListener, err := net.Listen("tcp", s.adminServer.Addr)
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return fmt.Errorf("failed to listen on %s: %w", s.adminServer.Addr, err)
 		}
-	}()
+
+		go s.serve(s.adminServer, adminListener, "Starting admin HTTP server", serverErr)
+	}
+
+	// Start servers in goroutines
+	for _, l := range listeners {
+		go s.serve(s.httpServer, l, "Starting HTTP server", serverErr)
+	}
+
+	s.prober.Start()
+	s.started.Store(true)
+
+	// Template files are watched only when the configuration can be re-read
+	stopWatching := make(chan struct{})
+	if s.config.Path() != "" && s.config.Server.WatchInterval > 0 {
+		go s.watchFiles(s.config.Server.WatchInterval, stopWatching)
+	}
 
 	// Wait for interrupt signal or server error
-	select {
-	case err := <-serverErr:
-		return fmt.Errorf("server error: %w", err)
-	case sig := <-stop:
-		s.logger.WithField("signal", sig).Info("Received shutdown signal")
+	for running := true; running; {
+		select {
+		case err := <-serverErr:
+			close(stopWatching)
+			return fmt.Errorf("server error: %w", err)
+		case <-reload:
+			s.logger.Info("Received reload signal")
+			s.reloadAudited("signal")
+		case sig := <-stop:
+			s.logger.WithField("signal", sig).Info("Received shutdown signal")
+			running = false
+		}
 	}
+	close(stopWatching)
 
 	// Graceful shutdown
 	return s.Shutdown()
 }
 
-// Shutdown gracefully shuts down the server
+// serve runs a listener until it is shut down, reporting unexpected errors
+func (s *Server) serve(srv *http.Server, l net.Listener, message string, serverErr chan<- error) {
+	s.logger.WithFields(logrus.Fields{
+		"network": l.Addr().Network(),
+		"addr":    l.Addr().String(),
+	}).Info(message)
+
+	if err := srv.Serve(l); err != nil && err != http.ErrServerClosed {
+		serverErr <- err
+	}
+}
+
+// Shutdown gracefully shuts down the server. Readiness fails and new webhooks are rejected
+// first, then in-flight deliveries get up to the drain timeout to finish.
 func (s *Server) Shutdown() error {
 	s.logger.Info("Shutting down server...")
 
-	// Create a deadline for shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	s.webhookHandler.StartDrain()
+
+	// Give load balancers time to notice the failing readiness probe
+	if delay := s.config.Server.DrainDelay; delay > 0 {
+		s.logger.WithField("drain_delay", delay.String()).Info("Waiting before closing listeners")
+		time.Sleep(delay)
+	}
+
+	// Create a deadline for draining
+	drainTimeout := s.config.Server.DrainTimeout
+	if drainTimeout <= 0 {
+		drainTimeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
 	defer cancel()
 
-	// Shutdown the server
+	// Shutdown the server, waiting for running webhook requests
 	if err := s.httpServer.Shutdown(ctx); err != nil {
-		return fmt.Errorf("server shutdown error: %w", err)
+		s.logger.WithError(err).Warn("HTTP requests did not finish before the drain timeout")
 	}
 
+	if s.adminServer != nil {
+		if err := s.adminServer.Shutdown(ctx); err != nil {
+			s.logger.WithError(err).Warn("Admin HTTP requests did not finish before the drain timeout")
+		}
+	}
+
+	// Wait for deliveries started in the background, such as resumed or scheduled payloads
+	result := s.webhookHandler.Drain(ctx)
+	logger := s.logger.WithFields(logrus.Fields{
+		"completed": result.Completed,
+		"abandoned": result.Abandoned,
+		"persisted": result.Persisted,
+	})
+	if result.Abandoned > 0 {
+		logger.Warn("Abandoned in-flight deliveries after the drain timeout")
+	} else {
+		logger.Info("Drained in-flight deliveries")
+	}
+
+	s.prober.Stop()
+
 	// Close webhook handler
 	if err := s.webhookHandler.Close(); err != nil {
 		s.logger.WithError(err).Error("Failed to close webhook handler")
 	}
 
+	if err := s.audit.Close(); err != nil {
+		s.logger.WithError(err).Error("Failed to close audit log")
+	}
+
+	// Flush pending spans
+	if s.tracerShutdown != nil {
+		flushCtx, flushCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer flushCancel()
+
+		if err := s.tracerShutdown(flushCtx); err != nil {
+			s.logger.WithError(err).Error("Failed to shutdown tracing")
+		}
+	}
+
 	s.logger.Info("Server stopped gracefully")
 	return nil
 }
@@ -125,30 +288,61 @@ func (s *Server) GetRouter() *mux.Router {
 	return s.router
 }
 
+// GetAdminRouter returns the admin listener router, or the main router when there is no admin listener
+func (s *Server) GetAdminRouter() *mux.Router {
+	if s.adminRouter != nil {
+		return s.adminRouter
+	}
+	return s.router
+}
+
 // setupRoutes configures all routes
 func (s *Server) setupRoutes() {
+	// Management and debug endpoints move to the admin listener when configured
+	admin := s.GetAdminRouter()
+
 	// Health check endpoint
-	s.router.HandleFunc("/health", s.handleHealth).Methods(http.MethodGet)
+	admin.HandleFunc("/health", s.handleHealth).Methods(http.MethodGet)
+	if admin != s.router {
+		s.router.HandleFunc("/health", s.handleLiveness).Methods(http.MethodGet)
+	}
+
+	// Kubernetes probes are served on every listener
+	for _, router := range []*mux.Router{s.router, s.adminRouter} {
+		if router == nil {
+			continue
+		}
+		router.HandleFunc("/livez", s.handleLivez).Methods(http.MethodGet)
+		router.HandleFunc("/readyz", s.handleReadyz).Methods(http.MethodGet)
+		router.HandleFunc("/startupz", s.handleStartupz).Methods(http.MethodGet)
+	}
 
 	// Metrics endpoint (placeholder for now)
-	s.router.HandleFunc("/metrics", s.handleMetrics).Methods(http.MethodGet)
+	admin.HandleFunc("/metrics", s.handleMetrics).Methods(http.MethodGet)
 
 	// Profiling endpoints (only in debug mode or when explicitly enabled)
 	if os.Getenv("ENABLE_PPROF") == "true" {
-		s.router.PathPrefix("/debug/pprof/").Handler(http.DefaultServeMux)
+		admin.PathPrefix("/debug/pprof/").Handler(http.DefaultServeMux)
 		s.logger.Warn("pprof endpoints enabled at /debug/pprof/")
 	}
 
 	// API endpoints
-	apiRouter := s.router.PathPrefix("/api/v1").Subrouter()
-	if s.config.Server.Auth.Enabled && s.config.Server.Auth.APIUsername != "" {
+	apiRouter := admin.PathPrefix("/api/v1").Subrouter()
+	adminAuth := admin != s.router && s.config.Server.AdminAuth.Enabled
+	if !adminAuth && s.config.Server.Auth.Enabled && s.config.Server.Auth.APIUsername != "" {
 		apiRouter.Use(s.apiAuthMiddleware)
-	} else if s.config.Server.Auth.Enabled {
+	} else if !adminAuth && s.config.Server.Auth.Enabled {
 		apiRouter.Use(s.authMiddleware)
 	}
+	if s.audit != nil {
+		apiRouter.Use(s.auditMiddleware)
+	}
 
 	// Register API routes
 	s.RegisterAPIRoutes(apiRouter)
+	if s.config.Management.Enabled {
+		s.RegisterManagementRoutes(apiRouter)
+	}
 
 	// Webhook endpoints
 	webhookRouter := s.router.PathPrefix("/webhook").Subrouter()
@@ -158,8 +352,21 @@ func (s *Server) setupRoutes() {
 	webhookRouter.Use(webhook.ValidationMiddleware(s.logger))
 	webhookRouter.HandleFunc("/{destination}", s.webhookHandler.HandleWebhook).Methods(http.MethodPost)
 
+	// Alertmanager API v2 compatible ingestion endpoints
+	if s.config.AlertsAPI.Enabled {
+		webhookRouter.HandleFunc("/{destination}/api/v2/alerts", s.webhookHandler.HandleAlertsAPI).Methods(http.MethodPost)
+
+		alertsRouter := s.router.PathPrefix("/api/v2").Subrouter()
+		if s.config.Server.Auth.Enabled {
+			alertsRouter.Use(s.authMiddleware)
+		}
+		alertsRouter.Use(webhook.ValidationMiddleware(s.logger))
+		alertsRouter.HandleFunc("/alerts", s.webhookHandler.HandleAlertsAPI).Methods(http.MethodPost)
+	}
+
 	// Default handler for unmatched routes
 	s.router.NotFoundHandler = http.HandlerFunc(s.handleNotFound)
+	admin.NotFoundHandler = http.HandlerFunc(s.handleNotFound)
 }
 
 // Middleware functions
@@ -186,13 +393,13 @@ func (s *Server) loggingMiddleware(next http.Handler) http.Handler {
 		// Process request
 		next.ServeHTTP(wrapped, r)
 
-		// Skip logging for health and metrics endpoints to reduce noise
-		if r.URL.Path == "/health" || r.URL.Path == "/metrics" {
+		// Skip logging for health, probe and metrics endpoints to reduce noise
+		if isProbePath(r.URL.Path) || r.URL.Path == "/metrics" {
 			return
 		}
 
 		// Log request details
-		s.logger.WithFields(logrus.Fields{
+		s.logger.WithContext(r.Context()).WithFields(logrus.Fields{
 			"method":      r.Method,
 			"path":        r.URL.Path,
 			"remote_addr": r.RemoteAddr,
@@ -207,7 +414,7 @@ func (s *Server) recoveryMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if err := recover(); err != nil {
-				s.logger.WithFields(logrus.Fields{
+				s.logger.WithContext(r.Context()).WithFields(logrus.Fields{
 					"error":  err,
 					"method": r.Method,
 					"path":   r.URL.Path,
@@ -259,6 +466,107 @@ func (s *Server) apiAuthMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// adminAuthMiddleware protects the admin listener; health checks and probes stay open
+func (s *Server) adminAuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isProbePath(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		username, password, ok := r.BasicAuth()
+		if !ok {
+			s.sendUnauthorized(w, "Basic authentication required")
+			return
+		}
+
+		if username != s.config.Server.AdminAuth.Username || password != s.config.Server.AdminAuth.Password {
+			s.sendUnauthorized(w, "Invalid credentials")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) auditMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Only actions that change or exercise the gateway are audited
+		if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		wrapped := &responseWriter{
+			ResponseWriter: w,
+			statusCode:     http.StatusOK,
+		}
+
+		next.ServeHTTP(wrapped, r)
+
+		event := audit.Event{
+			Action: auditAction(r),
+			Result: "success",
+			Details: map[string]interface{}{
+				"method":      r.Method,
+				"path":        r.URL.Path,
+				"status":      wrapped.statusCode,
+				"remote_addr": r.RemoteAddr,
+			},
+		}
+
+		if username, _, ok := r.BasicAuth(); ok {
+			event.Actor = username
+		}
+
+		vars := mux.Vars(r)
+		if target := vars["name"]; target != "" {
+			event.Target = target
+		} else if target := vars["destination"]; target != "" {
+			event.Target = target
+		}
+
+		if wrapped.statusCode >= http.StatusBadRequest {
+			event.Result = "error"
+		}
+
+		if err := s.audit.Log(r.Context(), event); err != nil {
+			s.logger.WithContext(r.Context()).WithError(err).Error("Failed to write audit event")
+		}
+	})
+}
+
+// auditActions maps API route templates to audit action names
+var auditActions = map[string]string{
+	"POST /destinations":                     "destination.create",
+	"PUT /destinations/{name}":               "destination.update",
+	"PATCH /destinations/{name}":             "destination.update",
+	"DELETE /destinations/{name}":            "destination.delete",
+	"POST /destinations/{name}/pause":        "destination.pause",
+	"POST /destinations/{name}/resume":       "destination.resume",
+	"POST /destinations/{name}/digest/flush": "destination.digest_flush",
+	"POST /destinations/{name}/stats/reset":  "destination.stats_reset",
+	"POST /stats/reset":                      "stats.reset",
+	"POST /simulate":                         "destination.simulate",
+	"POST /test/{destination}":               "destination.test",
+	"POST /emulate/{destination}":            "destination.emulate",
+	"POST /config/validate":                  "config.validate",
+	"POST /config/reload":                    "config.reload",
+}
+
+// auditAction returns the audit action name of an API request
+func auditAction(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			if action, ok := auditActions[r.Method+" "+strings.TrimPrefix(template, "/api/v1")]; ok {
+				return action
+			}
+		}
+	}
+
+	return "api." + strings.ToLower(r.Method)
+}
+
 // Helper functions
 
 func (s *Server) sendUnauthorized(w http.ResponseWriter, message string) {
@@ -0,0 +1,269 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"text/template"
+	"time"
+
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+	"github.com/vitalvas/alertmanager-gateway/internal/transform"
+)
+
+// Alert statuses a simulation can generate
+const (
+	statusFiring   = "firing"
+	statusResolved = "resolved"
+)
+
+// Limits of a single simulation request
+const (
+	maxSimulationAlerts   = 1000
+	maxSimulationWebhooks = 100
+	maxSimulationDuration = 5 * time.Minute
+)
+
+// simulationAlertData is the data available to a simulation alert template
+type simulationAlertData struct {
+	Index       int
+	Count       int
+	Status      string
+	Severity    string
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// simulationAlertOverrides is the output expected from a simulation alert template
+type simulationAlertOverrides struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// simulationPlan is a validated simulation request
+type simulationPlan struct {
+	simulation WebhookSimulation
+	statuses   []string
+	interval   time.Duration
+	template   *template.Template
+}
+
+func (s *Server) handleSimulate(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	var req SimulationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.sendAPIError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return
+	}
+
+	dest, exists := s.webhookHandler.Destination(req.Destination)
+	if !exists {
+		s.sendAPIError(w, http.StatusNotFound, "Destination not found")
+		return
+	}
+
+	// Webhooks sent at an interval must finish before the response write times out
+	maxDuration := maxSimulationDuration
+	if writeTimeout := s.config.Server.WriteTimeout; writeTimeout > 0 && writeTimeout < maxDuration {
+		maxDuration = writeTimeout
+	}
+
+	plan, err := newSimulationPlan(&req, maxDuration)
+	if err != nil {
+		s.sendAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response := SimulationResponse{
+		Success:           true,
+		Destination:       req.Destination,
+		DryRun:            req.DryRun,
+		GeneratedWebhooks: make([]*alertmanager.WebhookPayload, 0, len(plan.statuses)),
+		Results:           make([]*EmulationResult, 0, len(plan.statuses)),
+		RequestID:         responseRequestID(w),
+	}
+
+	startsAt := time.Now().UTC()
+	for i, status := range plan.statuses {
+		if i > 0 && plan.interval > 0 {
+			select {
+			case <-time.After(plan.interval):
+			case <-r.Context().Done():
+				s.sendAPIError(w, http.StatusRequestTimeout, "Simulation cancelled")
+				return
+			}
+		}
+
+		payload, err := plan.generate(status, startsAt, time.Now().UTC())
+		if err != nil {
+			s.sendAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		result, err := s.emulateDestinationRequest(&dest, payload, req.DryRun)
+		if err != nil {
+			s.sendAPIError(w, http.StatusInternalServerError, fmt.Sprintf("Simulation failed: %v", err))
+			return
+		}
+
+		response.GeneratedWebhooks = append(response.GeneratedWebhooks, payload)
+		response.Results = append(response.Results, result)
+		if !result.Success {
+			response.Success = false
+		}
+	}
+
+	response.SimulationTime = time.Since(start)
+	response.Timestamp = time.Now().UTC()
+
+	s.sendJSON(w, http.StatusOK, response)
+}
+
+// newSimulationPlan applies defaults to a simulation request and validates it
+func newSimulationPlan(req *SimulationRequest, maxDuration time.Duration) (*simulationPlan, error) {
+	plan := &simulationPlan{simulation: req.Simulation}
+	sim := &plan.simulation
+
+	if sim.AlertCount == 0 {
+		sim.AlertCount = 1
+	}
+	if sim.AlertCount < 0 || sim.AlertCount > maxSimulationAlerts {
+		return nil, fmt.Errorf("alert_count must be between 1 and %d", maxSimulationAlerts)
+	}
+
+	if sim.Status == "" {
+		sim.Status = statusFiring
+	}
+	if sim.Severity == "" {
+		sim.Severity = "warning"
+	}
+
+	if sim.Template != "" {
+		tmpl, err := template.New("simulation").Funcs(transform.GetTemplateFuncs()).Parse(sim.Template)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template: %w", err)
+		}
+		plan.template = tmpl
+	}
+
+	opts := req.Options
+	count := 1
+	if opts.GenerateMultiple {
+		count = opts.Count
+		if count == 0 {
+			count = max(len(opts.Sequence), 1)
+		}
+	}
+	if count < 1 || count > maxSimulationWebhooks {
+		return nil, fmt.Errorf("count must be between 1 and %d", maxSimulationWebhooks)
+	}
+
+	if opts.Interval != "" {
+		interval, err := time.ParseDuration(opts.Interval)
+		if err != nil || interval < 0 {
+			return nil, fmt.Errorf("invalid interval: %s", opts.Interval)
+		}
+		if interval*time.Duration(count-1) >= maxDuration {
+			return nil, fmt.Errorf("count and interval exceed the maximum simulation duration of %s", maxDuration)
+		}
+		plan.interval = interval
+	}
+
+	// Each webhook takes the next status of the sequence; the last one repeats
+	sequence := opts.Sequence
+	if len(sequence) == 0 {
+		sequence = []string{sim.Status}
+	}
+
+	plan.statuses = make([]string, count)
+	for i := range plan.statuses {
+		status := sequence[min(i, len(sequence)-1)]
+		if status != statusFiring && status != statusResolved {
+			return nil, fmt.Errorf("invalid status: %s", status)
+		}
+		plan.statuses[i] = status
+	}
+
+	return plan, nil
+}
+
+// generate builds a webhook payload with the planned alerts. Alerts keep the same labels,
+// and therefore fingerprints, across webhooks so a resolved webhook resolves the firing ones.
+func (p *simulationPlan) generate(status string, startsAt, now time.Time) (*alertmanager.WebhookPayload, error) {
+	sim := &p.simulation
+
+	alerts := make([]alertmanager.Alert, 0, sim.AlertCount)
+	for i := 1; i <= sim.AlertCount; i++ {
+		labels := map[string]string{
+			"alertname": "SimulatedAlert",
+			"instance":  fmt.Sprintf("simulated-%d", i),
+			"job":       "simulation",
+			"severity":  sim.Severity,
+		}
+		for name, value := range sim.CustomLabels {
+			labels[name] = value
+		}
+
+		annotations := map[string]string{
+			"summary": fmt.Sprintf("Simulated alert %d of %d", i, sim.AlertCount),
+		}
+		for name, value := range sim.CustomAnnotations {
+			annotations[name] = value
+		}
+
+		if p.template != nil {
+			if err := p.applyTemplate(i, status, labels, annotations); err != nil {
+				return nil, err
+			}
+		}
+
+		alert := alertmanager.Alert{
+			Status:       status,
+			Labels:       labels,
+			Annotations:  annotations,
+			StartsAt:     startsAt,
+			GeneratorURL: "http://localhost:9090/graph",
+			Fingerprint:  alertmanager.Fingerprint(labels),
+		}
+		if status == statusResolved {
+			alert.EndsAt = now
+		}
+
+		alerts = append(alerts, alert)
+	}
+
+	// All generated alerts form a single group
+	return alertmanager.GroupAlerts(alerts, nil, "simulation", "http://localhost:9093")[0], nil
+}
+
+// applyTemplate renders the alert template and merges its labels and annotations
+func (p *simulationPlan) applyTemplate(index int, status string, labels, annotations map[string]string) error {
+	var buf bytes.Buffer
+	err := p.template.Execute(&buf, simulationAlertData{
+		Index:       index,
+		Count:       p.simulation.AlertCount,
+		Status:      status,
+		Severity:    p.simulation.Severity,
+		Labels:      labels,
+		Annotations: annotations,
+	})
+	if err != nil {
+		return fmt.Errorf("template execution failed for alert %d: %w", index, err)
+	}
+
+	var overrides simulationAlertOverrides
+	if err := json.Unmarshal(buf.Bytes(), &overrides); err != nil {
+		return fmt.Errorf("template output for alert %d is not a JSON object with labels and annotations: %w", index, err)
+	}
+
+	for name, value := range overrides.Labels {
+		labels[name] = value
+	}
+	for name, value := range overrides.Annotations {
+		annotations[name] = value
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/alertmanager-gateway/internal/config"
+)
+
+func TestHandleSimulate(t *testing.T) {
+	var received atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		received.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{Address: ":8080"},
+		Destinations: []config.DestinationConfig{{
+			Name:     "chat",
+			URL:      upstream.URL,
+			Method:   "POST",
+			Format:   "json",
+			Engine:   "go-template",
+			Template: `{"status": "{{ .Status }}", "count": {{ len .Alerts }}}`,
+			Enabled:  true,
+		}},
+	}
+
+	server, err := New(cfg, logrus.New())
+	require.NoError(t, err)
+	defer server.webhookHandler.Close()
+
+	simulate := func(body string) (int, SimulationResponse) {
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/simulate", strings.NewReader(body)))
+
+		var response SimulationResponse
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		}
+		return w.Code, response
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		code, response := simulate(`{"destination":"chat","dry_run":true,"simulation":{"alert_count":3,"severity":"critical","custom_labels":{"team":"ops"}}}`)
+		require.Equal(t, http.StatusOK, code)
+
+		assert.True(t, response.Success)
+		assert.True(t, response.DryRun)
+		require.Len(t, response.GeneratedWebhooks, 1)
+		require.Len(t, response.Results, 1)
+
+		payload := response.GeneratedWebhooks[0]
+		assert.Equal(t, "firing", payload.Status)
+		require.Len(t, payload.Alerts, 3)
+		assert.Equal(t, "critical", payload.Alerts[0].Labels["severity"])
+		assert.Equal(t, "ops", payload.CommonLabels["team"])
+		assert.NotEmpty(t, payload.Alerts[0].Fingerprint)
+
+		assert.Equal(t, `{"count":3,"status":"firing"}`, response.Results[0].FormattedOutput)
+		assert.Equal(t, "dry-run", response.Results[0].HTTPStatus)
+		assert.Equal(t, int32(0), received.Load())
+	})
+
+	t.Run("firing then resolved", func(t *testing.T) {
+		code, response := simulate(`{"destination":"chat","simulation":{"alert_count":2},"options":{"generate_multiple":true,"sequence":["firing","resolved"],"interval":"10ms"}}`)
+		require.Equal(t, http.StatusOK, code)
+
+		require.Len(t, response.GeneratedWebhooks, 2)
+		firing, resolved := response.GeneratedWebhooks[0], response.GeneratedWebhooks[1]
+		assert.Equal(t, "firing", firing.Status)
+		assert.Equal(t, "resolved", resolved.Status)
+		assert.Equal(t, firing.Alerts[1].Fingerprint, resolved.Alerts[1].Fingerprint)
+		assert.False(t, resolved.Alerts[0].EndsAt.IsZero())
+
+		require.Len(t, response.Results, 2)
+		assert.Equal(t, http.StatusOK, response.Results[1].HTTPStatusCode)
+		assert.Equal(t, int32(2), received.Load())
+	})
+
+	t.Run("alert template", func(t *testing.T) {
+		code, response := simulate(`{"destination":"chat","dry_run":true,"simulation":{"alert_count":2,"template":"{\"labels\":{\"instance\":\"host-{{ .Index }}\"},\"annotations\":{\"summary\":\"{{ .Severity }} on host-{{ .Index }}\"}}"}}`)
+		require.Equal(t, http.StatusOK, code)
+
+		alerts := response.GeneratedWebhooks[0].Alerts
+		assert.Equal(t, "host-2", alerts[1].Labels["instance"])
+		assert.Equal(t, "warning on host-2", alerts[1].Annotations["summary"])
+	})
+
+	errorTests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{name: "unknown destination", body: `{"destination":"missing"}`, code: http.StatusNotFound},
+		{name: "invalid status", body: `{"destination":"chat","simulation":{"status":"pending"}}`, code: http.StatusBadRequest},
+		{name: "too many alerts", body: `{"destination":"chat","simulation":{"alert_count":100000}}`, code: http.StatusBadRequest},
+		{name: "invalid interval", body: `{"destination":"chat","options":{"generate_multiple":true,"count":2,"interval":"soon"}}`, code: http.StatusBadRequest},
+		{name: "too long", body: `{"destination":"chat","options":{"generate_multiple":true,"count":100,"interval":"1h"}}`, code: http.StatusBadRequest},
+		{name: "invalid template", body: `{"destination":"chat","simulation":{"template":"{{ .Index"}}`, code: http.StatusBadRequest},
+		{name: "template output not json", body: `{"destination":"chat","simulation":{"template":"plain"}}`, code: http.StatusBadRequest},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _ := simulate(tt.body)
+			assert.Equal(t, tt.code, code)
+		})
+	}
+}
+
+func TestNewSimulationPlan_Defaults(t *testing.T) {
+	plan, err := newSimulationPlan(&SimulationRequest{
+		Options: SimulationOptions{GenerateMultiple: true, Count: 3, Sequence: []string{"firing", "resolved"}},
+	}, time.Minute)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, plan.simulation.AlertCount)
+	assert.Equal(t, "warning", plan.simulation.Severity)
+	assert.Equal(t, []string{"firing", "resolved", "resolved"}, plan.statuses)
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/vitalvas/alertmanager-gateway/internal/destination"
+	"github.com/vitalvas/alertmanager-gateway/internal/transform"
+)
+
+// handleSystemStats returns gateway totals and the statistics of every configured destination
+func (s *Server) handleSystemStats(w http.ResponseWriter, _ *http.Request) {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	stats := SystemStats{
+		TotalWebhooks:       s.webhookHandler.ReceivedWebhooks(),
+		UptimeSeconds:       time.Since(startTime).Seconds(),
+		DestinationStats:    make([]DestinationStats, 0),
+		MemoryUsage:         memStats.Alloc,
+		GoroutineCount:      runtime.NumGoroutine(),
+		LastConfigReload:    s.lastConfigReload(),
+		TemplateCache:       newTemplateCacheStats(transform.GetTemplateCache().Stats(), false),
+		CollectionTimestamp: time.Now().UTC(),
+	}
+
+	for _, dest := range s.webhookHandler.Destinations() {
+		destStats := newDestinationStats(s.webhookHandler.DestinationStats(dest.Name))
+		stats.TotalRequests += destStats.RequestCount
+		stats.TotalErrors += destStats.ErrorCount
+		stats.DestinationStats = append(stats.DestinationStats, destStats)
+	}
+
+	s.sendJSON(w, http.StatusOK, stats)
+}
+
+// handleTemplateCacheStats returns the shared template cache with its compiled engines
+func (s *Server) handleTemplateCacheStats(w http.ResponseWriter, _ *http.Request) {
+	s.sendJSON(w, http.StatusOK, newTemplateCacheStats(transform.GetTemplateCache().Stats(), true))
+}
+
+// handleDestinationStats returns the statistics of a single destination
+func (s *Server) handleDestinationStats(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	if _, exists := s.webhookHandler.Destination(name); !exists {
+		s.sendAPIError(w, http.StatusNotFound, "Destination not found")
+		return
+	}
+
+	s.sendJSON(w, http.StatusOK, newDestinationStats(s.webhookHandler.DestinationStats(name)))
+}
+
+// handleResetStats clears the statistics of every destination
+func (s *Server) handleResetStats(w http.ResponseWriter, _ *http.Request) {
+	s.webhookHandler.ResetAllStats()
+
+	s.sendJSON(w, http.StatusOK, StatsResetResponse{
+		Success:   true,
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+// handleResetDestinationStats clears the statistics of a single destination
+func (s *Server) handleResetDestinationStats(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	if _, exists := s.webhookHandler.Destination(name); !exists {
+		s.sendAPIError(w, http.StatusNotFound, "Destination not found")
+		return
+	}
+
+	s.webhookHandler.ResetStats(name)
+
+	s.sendJSON(w, http.StatusOK, StatsResetResponse{
+		Success:     true,
+		Destination: name,
+		Timestamp:   time.Now().UTC(),
+	})
+}
+
+// newDestinationStats converts a statistics snapshot to its API representation
+func newDestinationStats(snapshot destination.StatsSnapshot) DestinationStats {
+	return DestinationStats{
+		Name:             snapshot.Name,
+		RequestCount:     snapshot.Requests,
+		SuccessCount:     snapshot.Successes,
+		ErrorCount:       snapshot.Errors,
+		AverageLatencyMS: milliseconds(snapshot.AverageLatency),
+		LatencyHistogram: snapshot.LatencyBuckets,
+		LastRequestTime:  optionalTime(snapshot.LastRequestTime),
+		LastError:        snapshot.LastError,
+		LastErrorTime:    optionalTime(snapshot.LastErrorTime),
+		Since:            optionalTime(snapshot.Since),
+	}
+}
+
+// newTemplateCacheStats converts template cache statistics to their API representation
+func newTemplateCacheStats(stats transform.CacheStats, withEntries bool) TemplateCacheStats {
+	result := TemplateCacheStats{
+		Size:            stats.Size,
+		MaxSize:         stats.MaxSize,
+		TTLSeconds:      stats.TTL.Seconds(),
+		Hits:            stats.Hits,
+		Misses:          stats.Misses,
+		Evictions:       stats.Evictions,
+		Renders:         stats.Renders,
+		AverageRenderMS: milliseconds(stats.AverageRenderTime),
+	}
+
+	if withEntries {
+		result.Entries = make([]TemplateCacheEntryStats, 0, len(stats.Entries))
+		for _, entry := range stats.Entries {
+			result.Entries = append(result.Entries, TemplateCacheEntryStats{
+				Key:             entry.Key,
+				Engine:          entry.Engine,
+				Hits:            entry.Hits,
+				Renders:         entry.Render.Renders,
+				AverageRenderMS: milliseconds(entry.Render.AverageDuration),
+				CompiledAt:      entry.CreatedAt.UTC(),
+				LastUsed:        entry.LastUsed.UTC(),
+			})
+		}
+	}
+
+	return result
+}
+
+// milliseconds converts a duration to fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// optionalTime returns nil for the zero time so it is omitted from responses
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	utc := t.UTC()
+	return &utc
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/alertmanager-gateway/internal/config"
+)
+
+func TestStatsEndpoints(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	newDestination := func(name, url string) config.DestinationConfig {
+		return config.DestinationConfig{
+			Name:     name,
+			URL:      url,
+			Method:   "POST",
+			Format:   "json",
+			Engine:   "go-template",
+			Template: `{"status": "{{ .Status }}"}`,
+			Enabled:  true,
+		}
+	}
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{Address: ":8080"},
+		Destinations: []config.DestinationConfig{
+			newDestination("chat", upstream.URL),
+			newDestination("pager", upstream.URL+"/down"),
+		},
+	}
+
+	server, err := New(cfg, logrus.New())
+	require.NoError(t, err)
+	defer server.webhookHandler.Close()
+
+	body := `{"version":"4","groupKey":"g","status":"firing","alerts":[{"status":"firing","labels":{"alertname":"Test"},"fingerprint":"abc","startsAt":"2024-01-01T00:00:00Z"}]}`
+	for _, dest := range []string{"chat", "chat", "pager"} {
+		req := httptest.NewRequest("POST", "/webhook/"+dest, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		server.router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	request := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	w := request("GET", "/api/v1/stats")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var stats SystemStats
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.Equal(t, int64(3), stats.TotalWebhooks)
+	assert.Equal(t, int64(3), stats.TotalRequests)
+	assert.Equal(t, int64(1), stats.TotalErrors)
+	assert.False(t, stats.LastConfigReload.IsZero())
+	require.Len(t, stats.DestinationStats, 2)
+
+	w = request("GET", "/api/v1/destinations/pager/stats")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var pager DestinationStats
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &pager))
+	assert.Equal(t, int64(1), pager.ErrorCount)
+	assert.Contains(t, pager.LastError, "503")
+	assert.NotNil(t, pager.LastErrorTime)
+	assert.NotEmpty(t, pager.LatencyHistogram)
+
+	assert.Equal(t, http.StatusNotFound, request("GET", "/api/v1/destinations/missing/stats").Code)
+
+	// Resetting one destination keeps the others
+	require.Equal(t, http.StatusOK, request("POST", "/api/v1/destinations/pager/stats/reset").Code)
+
+	w = request("GET", "/api/v1/destinations/pager/stats")
+	var reset DestinationStats
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &reset))
+	assert.Equal(t, int64(0), reset.RequestCount)
+	assert.Nil(t, reset.LastRequestTime)
+
+	w = request("GET", "/api/v1/destinations/chat/stats")
+	var chat DestinationStats
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &chat))
+	assert.Equal(t, int64(2), chat.SuccessCount)
+
+	require.Equal(t, http.StatusOK, request("POST", "/api/v1/stats/reset").Code)
+
+	w = request("GET", "/api/v1/stats")
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.Equal(t, int64(0), stats.TotalWebhooks)
+	assert.Equal(t, int64(0), stats.TotalRequests)
+}
+
+func TestTemplateCacheStats(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	// Both destinations share one compiled engine
+	template := `{"cache_stats_test": "{{ .Status }}"}`
+	cfg := &config.Config{Server: config.ServerConfig{Address: ":8080"}}
+	for _, name := range []string{"first", "second"} {
+		cfg.Destinations = append(cfg.Destinations, config.DestinationConfig{
+			Name: name, URL: upstream.URL, Method: "POST", Format: "json", Engine: "go-template", Template: template, Enabled: true,
+		})
+	}
+
+	server, err := New(cfg, logrus.New())
+	require.NoError(t, err)
+	defer server.webhookHandler.Close()
+
+	body := `{"version":"4","groupKey":"g","status":"firing","alerts":[{"status":"firing","labels":{"alertname":"Test"},"fingerprint":"abc","startsAt":"2024-01-01T00:00:00Z"}]}`
+	for _, dest := range []string{"first", "second"} {
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest("POST", "/webhook/"+dest, strings.NewReader(body)))
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/stats/templates", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var stats TemplateCacheStats
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.Positive(t, stats.Size)
+	assert.Positive(t, stats.Hits)
+	assert.GreaterOrEqual(t, stats.Renders, int64(2))
+	assert.Len(t, stats.Entries, stats.Size)
+
+	w = httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/stats", nil))
+	var system SystemStats
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &system))
+	assert.Equal(t, stats.Size, system.TemplateCache.Size)
+	assert.Empty(t, system.TemplateCache.Entries)
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"maps"
+	"os"
+	"time"
+)
+
+// watchFiles reloads the configuration whenever a template file it references changes
+func (s *Server) watchFiles(interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	previous := modTimes(s.watchedFiles())
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		if maps.Equal(previous, modTimes(s.watchedFiles())) {
+			continue
+		}
+
+		s.logger.Info("Template files changed, reloading configuration")
+		s.reloadAudited("watch")
+
+		// A reload can add or remove watched files
+		previous = modTimes(s.watchedFiles())
+	}
+}
+
+// watchedFiles returns the template files of the running configuration
+func (s *Server) watchedFiles() []string {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	return s.watched
+}
+
+// modTimes returns the modification time of each file; missing files have a zero time
+func modTimes(files []string) map[string]time.Time {
+	times := make(map[string]time.Time, len(files))
+	for _, file := range files {
+		var modTime time.Time
+		if info, err := os.Stat(file); err == nil {
+			modTime = info.ModTime()
+		}
+		times[file] = modTime
+	}
+
+	return times
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/alertmanager-gateway/internal/config"
+)
+
+func TestWatchFiles(t *testing.T) {
+	dir := t.TempDir()
+	templatePath := filepath.Join(dir, "chat.tmpl")
+	configPath := filepath.Join(dir, "config.yaml")
+
+	require.NoError(t, os.WriteFile(templatePath, []byte(`{"version": 1}`), 0o600))
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+destinations:
+  - name: chat
+    url: https://chat.example.com
+    template_file: chat.tmpl
+`), 0o600))
+
+	cfg, err := config.LoadConfig(configPath)
+	require.NoError(t, err)
+
+	server, err := New(cfg, logrus.New())
+	require.NoError(t, err)
+	defer server.webhookHandler.Close()
+
+	assert.Equal(t, []string{templatePath}, server.watchedFiles())
+
+	done := make(chan struct{})
+	defer close(done)
+	go server.watchFiles(10*time.Millisecond, done)
+
+	// Wait for the watcher to record the initial modification times
+	time.Sleep(50 * time.Millisecond)
+
+	require.NoError(t, os.WriteFile(templatePath, []byte(`{"version": 2}`), 0o600))
+	modified := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(templatePath, modified, modified))
+
+	assert.Eventually(t, func() bool {
+		dest, exists := server.webhookHandler.Destination("chat")
+		return exists && dest.Template == `{"version": 2}`
+	}, 5*time.Second, 10*time.Millisecond)
+}
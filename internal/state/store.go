@@ -0,0 +1,318 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+)
+
+// Suppression reasons returned by Store.Decide
+const (
+	ReasonFlapping             = "flapping"
+	ReasonSendResolvedDisabled = "send_resolved_disabled"
+	ReasonNotDelivered         = "not_delivered"
+	ReasonUnchanged            = "unchanged"
+	ReasonRepeatInterval       = "repeat_interval"
+)
+
+// Policy defines how a destination deduplicates alert notifications
+type Policy struct {
+	SendResolved    bool
+	RepeatInterval  time.Duration
+	OnlyOnChange    bool
+	FlapTransitions int
+	FlapWindow      time.Duration
+}
+
+// Entry holds the delivery state of one alert for one destination
+type Entry struct {
+	Destination    string      `json:"destination"`
+	Fingerprint    string      `json:"fingerprint"`
+	LastStatus     string      `json:"last_status"`
+	LastSentStatus string      `json:"last_sent_status,omitempty"`
+	LastSent       time.Time   `json:"last_sent,omitempty"`
+	SendCount      int         `json:"send_count"`
+	Transitions    []time.Time `json:"transitions,omitempty"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+// Store is an in-memory alert state store keyed by destination and fingerprint
+type Store struct {
+	mu           sync.Mutex
+	entries      map[string]*Entry
+	snapshotFile string
+	retention    time.Duration
+	logger       *logrus.Entry
+	done         chan struct{}
+	closeOnce    sync.Once
+}
+
+// NewStore creates a new state store and restores the snapshot file if present
+func NewStore(snapshotFile string, retention time.Duration, logger *logrus.Logger) (*Store, error) {
+	if retention <= 0 {
+		retention = 24 * time.Hour
+	}
+
+	s := &Store{
+		entries:      make(map[string]*Entry),
+		snapshotFile: snapshotFile,
+		retention:    retention,
+		logger:       logger.WithField("component", "state"),
+		done:         make(chan struct{}),
+	}
+
+	if snapshotFile != "" {
+		if err := s.load(); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// Decide records the observed alert status and reports whether it should be sent
+func (s *Store) Decide(destination string, alert *alertmanager.Alert, policy Policy, now time.Time) (bool, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := s.observe(destination, alert, now)
+
+	if policy.FlapTransitions > 0 && policy.FlapWindow > 0 {
+		if countSince(entry.Transitions, now.Add(-policy.FlapWindow)) >= policy.FlapTransitions {
+			return false, ReasonFlapping
+		}
+	}
+
+	if alert.IsResolved() {
+		if !policy.SendResolved {
+			return false, ReasonSendResolvedDisabled
+		}
+
+		if entry.LastSentStatus == "" {
+			return false, ReasonNotDelivered
+		}
+	}
+
+	if entry.LastSentStatus != alert.Status {
+		return true, ""
+	}
+
+	if policy.OnlyOnChange {
+		return false, ReasonUnchanged
+	}
+
+	if alert.IsResolved() {
+		return false, ReasonUnchanged
+	}
+
+	if policy.RepeatInterval > 0 && now.Sub(entry.LastSent) < policy.RepeatInterval {
+		return false, ReasonRepeatInterval
+	}
+
+	return true, ""
+}
+
+// MarkSent records a successful delivery of the alert to the destination
+func (s *Store) MarkSent(destination string, alert *alertmanager.Alert, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := s.observe(destination, alert, now)
+	entry.LastSentStatus = alert.Status
+	entry.LastSent = now
+	entry.SendCount++
+}
+
+// Get returns a copy of the entry for the destination and fingerprint
+func (s *Store) Get(destination, fingerprint string) (Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[entryKey(destination, fingerprint)]
+	if !ok {
+		return Entry{}, false
+	}
+
+	return copyEntry(entry), true
+}
+
+// Len returns the number of tracked entries
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.entries)
+}
+
+// Prune removes entries that have not been updated within the retention period
+func (s *Store) Prune(now time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	for key, entry := range s.entries {
+		if now.Sub(entry.UpdatedAt) > s.retention {
+			delete(s.entries, key)
+			removed++
+		}
+	}
+
+	return removed
+}
+
+// Start begins periodic pruning and snapshotting
+func (s *Store) Start(interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.Prune(time.Now())
+				if err := s.Snapshot(); err != nil {
+					s.logger.WithError(err).Error("Failed to write state snapshot")
+				}
+			case <-s.done:
+				return
+			}
+		}
+	}()
+}
+
+// Close stops background work and writes a final snapshot
+func (s *Store) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+
+	return s.Snapshot()
+}
+
+// Snapshot writes all entries to the snapshot file
+func (s *Store) Snapshot() error {
+	if s.snapshotFile == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	entries := make([]Entry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		entries = append(entries, copyEntry(entry))
+	}
+	s.mu.Unlock()
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state snapshot: %w", err)
+	}
+
+	// Write atomically via a temporary file in the same directory
+	tmp, err := os.CreateTemp(filepath.Dir(s.snapshotFile), ".state-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create state snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state snapshot: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state snapshot: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.snapshotFile); err != nil {
+		return fmt.Errorf("failed to write state snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// load restores entries from the snapshot file
+func (s *Store) load() error {
+	data, err := os.ReadFile(s.snapshotFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read state snapshot: %w", err)
+	}
+
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("failed to parse state snapshot: %w", err)
+	}
+
+	for i := range entries {
+		entry := entries[i]
+		s.entries[entryKey(entry.Destination, entry.Fingerprint)] = &entry
+	}
+
+	s.logger.WithField("entries", len(entries)).Info("Restored alert state snapshot")
+
+	return nil
+}
+
+// observe returns the entry for the alert, recording a status transition if it changed
+func (s *Store) observe(destination string, alert *alertmanager.Alert, now time.Time) *Entry {
+	key := entryKey(destination, alert.Fingerprint)
+
+	entry, ok := s.entries[key]
+	if !ok {
+		entry = &Entry{
+			Destination: destination,
+			Fingerprint: alert.Fingerprint,
+			LastStatus:  alert.Status,
+		}
+		s.entries[key] = entry
+	}
+
+	if entry.LastStatus != alert.Status {
+		entry.LastStatus = alert.Status
+		entry.Transitions = append(entry.Transitions, now)
+	}
+
+	// Keep only transitions that can still matter for flap detection
+	cutoff := now.Add(-s.retention)
+	for len(entry.Transitions) > 0 && entry.Transitions[0].Before(cutoff) {
+		entry.Transitions = entry.Transitions[1:]
+	}
+
+	entry.UpdatedAt = now
+
+	return entry
+}
+
+func entryKey(destination, fingerprint string) string {
+	return destination + "/" + fingerprint
+}
+
+func countSince(times []time.Time, since time.Time) int {
+	count := 0
+	for _, t := range times {
+		if !t.Before(since) {
+			count++
+		}
+	}
+	return count
+}
+
+func copyEntry(entry *Entry) Entry {
+	c := *entry
+	if entry.Transitions != nil {
+		c.Transitions = append([]time.Time(nil), entry.Transitions...)
+	}
+	return c
+}
@@ -0,0 +1,182 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+)
+
+func newTestStore(t *testing.T, snapshotFile string) *Store {
+	t.Helper()
+
+	store, err := NewStore(snapshotFile, time.Hour, logrus.New())
+	require.NoError(t, err)
+
+	return store
+}
+
+func alertWithStatus(fingerprint, status string) *alertmanager.Alert {
+	return &alertmanager.Alert{
+		Status:      status,
+		Fingerprint: fingerprint,
+		StartsAt:    time.Now(),
+	}
+}
+
+func TestStore_Decide(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		policy   Policy
+		history  []string // statuses already sent, in order
+		alert    string
+		at       time.Duration
+		send     bool
+		reason   string
+		observed []string // statuses observed but not sent, after history
+	}{
+		{
+			name:   "first firing is sent",
+			policy: Policy{SendResolved: true},
+			alert:  "firing",
+			send:   true,
+		},
+		{
+			name:    "repeat firing without policy is sent",
+			policy:  Policy{SendResolved: true},
+			history: []string{"firing"},
+			alert:   "firing",
+			send:    true,
+		},
+		{
+			name:    "repeat firing with only_on_change is suppressed",
+			policy:  Policy{SendResolved: true, OnlyOnChange: true},
+			history: []string{"firing"},
+			alert:   "firing",
+			reason:  ReasonUnchanged,
+		},
+		{
+			name:    "repeat firing within repeat interval is suppressed",
+			policy:  Policy{SendResolved: true, RepeatInterval: time.Hour},
+			history: []string{"firing"},
+			alert:   "firing",
+			at:      30 * time.Minute,
+			reason:  ReasonRepeatInterval,
+		},
+		{
+			name:    "repeat firing after repeat interval is sent",
+			policy:  Policy{SendResolved: true, RepeatInterval: time.Hour},
+			history: []string{"firing"},
+			alert:   "firing",
+			at:      2 * time.Hour,
+			send:    true,
+		},
+		{
+			name:   "resolved for never delivered alert is suppressed",
+			policy: Policy{SendResolved: true},
+			alert:  "resolved",
+			reason: ReasonNotDelivered,
+		},
+		{
+			name:    "resolved after firing is sent",
+			policy:  Policy{SendResolved: true},
+			history: []string{"firing"},
+			alert:   "resolved",
+			send:    true,
+		},
+		{
+			name:    "resolved with send_resolved disabled is suppressed",
+			policy:  Policy{SendResolved: false},
+			history: []string{"firing"},
+			alert:   "resolved",
+			reason:  ReasonSendResolvedDisabled,
+		},
+		{
+			name:    "duplicate resolved is suppressed",
+			policy:  Policy{SendResolved: true},
+			history: []string{"firing", "resolved"},
+			alert:   "resolved",
+			reason:  ReasonUnchanged,
+		},
+		{
+			name:     "flapping alert is suppressed",
+			policy:   Policy{SendResolved: true, FlapTransitions: 3, FlapWindow: time.Hour},
+			history:  []string{"firing"},
+			observed: []string{"resolved", "firing"},
+			alert:    "resolved",
+			reason:   ReasonFlapping,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestStore(t, "")
+
+			ts := now
+			for _, status := range tt.history {
+				store.MarkSent("dest", alertWithStatus("fp", status), ts)
+				ts = ts.Add(time.Second)
+			}
+
+			for _, status := range tt.observed {
+				store.Decide("dest", alertWithStatus("fp", status), Policy{}, ts)
+				ts = ts.Add(time.Second)
+			}
+
+			send, reason := store.Decide("dest", alertWithStatus("fp", tt.alert), tt.policy, now.Add(tt.at).Add(time.Minute))
+			assert.Equal(t, tt.send, send)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+}
+
+func TestStore_MarkSent(t *testing.T) {
+	store := newTestStore(t, "")
+	now := time.Now()
+
+	store.MarkSent("dest", alertWithStatus("fp", "firing"), now)
+	store.MarkSent("dest", alertWithStatus("fp", "firing"), now.Add(time.Minute))
+
+	entry, ok := store.Get("dest", "fp")
+	require.True(t, ok)
+	assert.Equal(t, 2, entry.SendCount)
+	assert.Equal(t, "firing", entry.LastSentStatus)
+	assert.Equal(t, now.Add(time.Minute), entry.LastSent)
+
+	_, ok = store.Get("other", "fp")
+	assert.False(t, ok)
+}
+
+func TestStore_Prune(t *testing.T) {
+	store := newTestStore(t, "")
+	now := time.Now()
+
+	store.MarkSent("dest", alertWithStatus("old", "firing"), now.Add(-2*time.Hour))
+	store.MarkSent("dest", alertWithStatus("new", "firing"), now)
+
+	assert.Equal(t, 1, store.Prune(now))
+	assert.Equal(t, 1, store.Len())
+
+	_, ok := store.Get("dest", "new")
+	assert.True(t, ok)
+}
+
+func TestStore_Snapshot(t *testing.T) {
+	snapshotFile := filepath.Join(t.TempDir(), "state.json")
+
+	store := newTestStore(t, snapshotFile)
+	store.MarkSent("dest", alertWithStatus("fp", "firing"), time.Now())
+	require.NoError(t, store.Close())
+
+	restored := newTestStore(t, snapshotFile)
+	entry, ok := restored.Get("dest", "fp")
+	require.True(t, ok)
+	assert.Equal(t, "firing", entry.LastSentStatus)
+	assert.Equal(t, 1, entry.SendCount)
+}
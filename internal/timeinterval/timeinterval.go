@@ -0,0 +1,289 @@
+package timeinterval
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeRange is a time of day range in HH:MM format, start inclusive and end exclusive
+type TimeRange struct {
+	StartTime string `yaml:"start_time"`
+	EndTime   string `yaml:"end_time"`
+}
+
+// Spec describes one time interval using Alertmanager time_intervals syntax.
+// Empty fields match any value; all non-empty fields must match.
+type Spec struct {
+	Times       []TimeRange `yaml:"times"`
+	Weekdays    []string    `yaml:"weekdays"`
+	DaysOfMonth []string    `yaml:"days_of_month"`
+	Months      []string    `yaml:"months"`
+	Location    string      `yaml:"location"`
+}
+
+// Interval is a compiled Spec
+type Interval struct {
+	times       []intRange
+	weekdays    []intRange
+	daysOfMonth []intRange
+	months      []intRange
+	location    *time.Location
+}
+
+// Set is a group of intervals that matches when any of its intervals matches
+type Set []*Interval
+
+type intRange struct {
+	begin int
+	end   int
+}
+
+var weekdayNames = map[string]int{
+	"sunday":    0,
+	"monday":    1,
+	"tuesday":   2,
+	"wednesday": 3,
+	"thursday":  4,
+	"friday":    5,
+	"saturday":  6,
+}
+
+var monthNames = map[string]int{
+	"january":   1,
+	"february":  2,
+	"march":     3,
+	"april":     4,
+	"may":       5,
+	"june":      6,
+	"july":      7,
+	"august":    8,
+	"september": 9,
+	"october":   10,
+	"november":  11,
+	"december":  12,
+}
+
+// Compile parses and validates a Spec
+func Compile(spec Spec) (*Interval, error) {
+	interval := &Interval{location: time.UTC}
+
+	if spec.Location != "" {
+		location, err := time.LoadLocation(spec.Location)
+		if err != nil {
+			return nil, fmt.Errorf("invalid location %q: %w", spec.Location, err)
+		}
+		interval.location = location
+	}
+
+	for _, tr := range spec.Times {
+		start, err := parseClock(tr.StartTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start_time: %w", err)
+		}
+
+		end, err := parseClock(tr.EndTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end_time: %w", err)
+		}
+
+		if start >= end {
+			return nil, fmt.Errorf("start_time %s must be before end_time %s", tr.StartTime, tr.EndTime)
+		}
+
+		interval.times = append(interval.times, intRange{begin: start, end: end})
+	}
+
+	for _, s := range spec.Weekdays {
+		r, err := parseRange(s, func(v string) (int, error) { return parseNamed(v, weekdayNames, 0, 6) })
+		if err != nil {
+			return nil, fmt.Errorf("invalid weekdays: %w", err)
+		}
+		interval.weekdays = append(interval.weekdays, r)
+	}
+
+	for _, s := range spec.DaysOfMonth {
+		r, err := parseRange(s, parseDayOfMonth)
+		if err != nil {
+			return nil, fmt.Errorf("invalid days_of_month: %w", err)
+		}
+		interval.daysOfMonth = append(interval.daysOfMonth, r)
+	}
+
+	for _, s := range spec.Months {
+		r, err := parseRange(s, func(v string) (int, error) { return parseNamed(v, monthNames, 1, 12) })
+		if err != nil {
+			return nil, fmt.Errorf("invalid months: %w", err)
+		}
+		interval.months = append(interval.months, r)
+	}
+
+	return interval, nil
+}
+
+// CompileSet compiles a list of specs into a Set
+func CompileSet(specs []Spec) (Set, error) {
+	set := make(Set, 0, len(specs))
+	for i, spec := range specs {
+		interval, err := Compile(spec)
+		if err != nil {
+			return nil, fmt.Errorf("interval %d: %w", i, err)
+		}
+		set = append(set, interval)
+	}
+
+	return set, nil
+}
+
+// Contains reports whether the time falls within the interval
+func (i *Interval) Contains(t time.Time) bool {
+	t = t.In(i.location)
+
+	if len(i.times) > 0 {
+		minute := t.Hour()*60 + t.Minute()
+		if !anyRange(i.times, func(r intRange) bool { return minute >= r.begin && minute < r.end }) {
+			return false
+		}
+	}
+
+	if len(i.weekdays) > 0 {
+		weekday := int(t.Weekday())
+		if !anyRange(i.weekdays, inclusive(weekday)) {
+			return false
+		}
+	}
+
+	if len(i.daysOfMonth) > 0 {
+		day := t.Day()
+		daysInMonth := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+
+		match := false
+		for _, r := range i.daysOfMonth {
+			begin, end := resolveDay(r.begin, daysInMonth), resolveDay(r.end, daysInMonth)
+			if day >= begin && day <= end {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+
+	if len(i.months) > 0 {
+		month := int(t.Month())
+		if !anyRange(i.months, inclusive(month)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Contains reports whether any interval of the set contains the time
+func (s Set) Contains(t time.Time) bool {
+	for _, interval := range s {
+		if interval.Contains(t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func anyRange(ranges []intRange, match func(intRange) bool) bool {
+	for _, r := range ranges {
+		if match(r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func inclusive(value int) func(intRange) bool {
+	return func(r intRange) bool {
+		return value >= r.begin && value <= r.end
+	}
+}
+
+// resolveDay converts negative days counted from the end of the month
+func resolveDay(day, daysInMonth int) int {
+	if day < 0 {
+		return daysInMonth + day + 1
+	}
+
+	return day
+}
+
+// parseRange parses "value" or "begin:end"
+func parseRange(s string, parse func(string) (int, error)) (intRange, error) {
+	beginStr, endStr, isRange := strings.Cut(strings.TrimSpace(s), ":")
+
+	begin, err := parse(beginStr)
+	if err != nil {
+		return intRange{}, err
+	}
+
+	end := begin
+	if isRange {
+		end, err = parse(endStr)
+		if err != nil {
+			return intRange{}, err
+		}
+	}
+
+	// Ranges mixing negative and positive days are resolved per month
+	if (begin < 0) == (end < 0) && begin > end {
+		return intRange{}, fmt.Errorf("range %q: start must not be after end", s)
+	}
+
+	return intRange{begin: begin, end: end}, nil
+}
+
+// parseNamed parses a lowercase name or a number within bounds
+func parseNamed(s string, names map[string]int, minValue, maxValue int) (int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	if value, ok := names[s]; ok {
+		return value, nil
+	}
+
+	value, err := strconv.Atoi(s)
+	if err != nil || value < minValue || value > maxValue {
+		return 0, fmt.Errorf("unknown value %q", s)
+	}
+
+	return value, nil
+}
+
+// parseDayOfMonth parses 1..31 or -31..-1
+func parseDayOfMonth(s string) (int, error) {
+	value, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || value == 0 || value < -31 || value > 31 {
+		return 0, fmt.Errorf("invalid day of month %q", s)
+	}
+
+	return value, nil
+}
+
+// parseClock parses HH:MM into minutes since midnight, allowing 24:00
+func parseClock(s string) (int, error) {
+	hourStr, minuteStr, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return 0, fmt.Errorf("time %q must be in HH:MM format", s)
+	}
+
+	hour, err := strconv.Atoi(hourStr)
+	if err != nil || hour < 0 || hour > 24 {
+		return 0, fmt.Errorf("invalid hour in %q", s)
+	}
+
+	minute, err := strconv.Atoi(minuteStr)
+	if err != nil || minute < 0 || minute > 59 || (hour == 24 && minute != 0) {
+		return 0, fmt.Errorf("invalid minute in %q", s)
+	}
+
+	return hour*60 + minute, nil
+}
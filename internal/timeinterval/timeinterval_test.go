@@ -0,0 +1,132 @@
+package timeinterval
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		spec Spec
+	}{
+		{name: "bad location", spec: Spec{Location: "Mars/Base"}},
+		{name: "bad time format", spec: Spec{Times: []TimeRange{{StartTime: "9", EndTime: "17:00"}}}},
+		{name: "end before start", spec: Spec{Times: []TimeRange{{StartTime: "17:00", EndTime: "09:00"}}}},
+		{name: "bad minute", spec: Spec{Times: []TimeRange{{StartTime: "09:60", EndTime: "17:00"}}}},
+		{name: "after midnight", spec: Spec{Times: []TimeRange{{StartTime: "09:00", EndTime: "24:30"}}}},
+		{name: "unknown weekday", spec: Spec{Weekdays: []string{"funday"}}},
+		{name: "reversed weekday range", spec: Spec{Weekdays: []string{"friday:monday"}}},
+		{name: "zero day of month", spec: Spec{DaysOfMonth: []string{"0"}}},
+		{name: "unknown month", spec: Spec{Months: []string{"13"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.spec)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestInterval_Contains(t *testing.T) {
+	// 2024-01-15 is a Monday
+	monday := func(hour, minute int) time.Time {
+		return time.Date(2024, 1, 15, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		spec Spec
+		at   time.Time
+		want bool
+	}{
+		{name: "empty spec matches everything", spec: Spec{}, at: monday(3, 0), want: true},
+		{
+			name: "business hours inside",
+			spec: Spec{Weekdays: []string{"monday:friday"}, Times: []TimeRange{{StartTime: "09:00", EndTime: "17:00"}}},
+			at:   monday(9, 0),
+			want: true,
+		},
+		{
+			name: "business hours end is exclusive",
+			spec: Spec{Weekdays: []string{"monday:friday"}, Times: []TimeRange{{StartTime: "09:00", EndTime: "17:00"}}},
+			at:   monday(17, 0),
+			want: false,
+		},
+		{
+			name: "weekend",
+			spec: Spec{Weekdays: []string{"saturday", "sunday"}},
+			at:   monday(12, 0),
+			want: false,
+		},
+		{
+			name: "end of day",
+			spec: Spec{Times: []TimeRange{{StartTime: "22:00", EndTime: "24:00"}}},
+			at:   monday(23, 59),
+			want: true,
+		},
+		{
+			name: "location shifts the weekday and time",
+			spec: Spec{Weekdays: []string{"tuesday"}, Times: []TimeRange{{StartTime: "08:00", EndTime: "10:00"}}, Location: "Asia/Tokyo"},
+			at:   monday(23, 30), // 08:30 on Tuesday in Tokyo
+			want: true,
+		},
+		{
+			name: "days of month",
+			spec: Spec{DaysOfMonth: []string{"10:20"}},
+			at:   monday(12, 0),
+			want: true,
+		},
+		{
+			name: "last day of month",
+			spec: Spec{DaysOfMonth: []string{"-1"}},
+			at:   time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC),
+			want: true,
+		},
+		{
+			name: "not last day of month",
+			spec: Spec{DaysOfMonth: []string{"-1"}},
+			at:   time.Date(2024, 2, 28, 12, 0, 0, 0, time.UTC),
+			want: false,
+		},
+		{
+			name: "months by name and number",
+			spec: Spec{Months: []string{"december:december", "1:2"}},
+			at:   monday(12, 0),
+			want: true,
+		},
+		{
+			name: "month outside range",
+			spec: Spec{Months: []string{"june:august"}},
+			at:   monday(12, 0),
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interval, err := Compile(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, interval.Contains(tt.at))
+		})
+	}
+}
+
+func TestSet_Contains(t *testing.T) {
+	set, err := CompileSet([]Spec{
+		{Weekdays: []string{"saturday", "sunday"}},
+		{Times: []TimeRange{{StartTime: "00:00", EndTime: "08:00"}}},
+	})
+	require.NoError(t, err)
+
+	assert.True(t, set.Contains(time.Date(2024, 1, 13, 12, 0, 0, 0, time.UTC)))  // Saturday
+	assert.True(t, set.Contains(time.Date(2024, 1, 15, 7, 0, 0, 0, time.UTC)))   // Monday early morning
+	assert.False(t, set.Contains(time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC))) // Monday noon
+
+	_, err = CompileSet([]Spec{{Weekdays: []string{"nope"}}})
+	assert.ErrorContains(t, err, "interval 0")
+}
@@ -0,0 +1,159 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/alertmanager-gateway/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	SetupPropagation()
+
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		_ = provider.Shutdown(context.Background())
+	})
+
+	return recorder
+}
+
+func TestMiddleware(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	router := mux.NewRouter()
+	router.Use(Middleware)
+
+	var handlerSpan trace.SpanContext
+	router.HandleFunc("/webhook/{destination}", func(w http.ResponseWriter, r *http.Request) {
+		handlerSpan = trace.SpanContextFromContext(r.Context())
+		w.WriteHeader(http.StatusBadGateway)
+	}).Methods(http.MethodPost)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook/slack", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+
+	span := spans[0]
+	assert.Equal(t, "POST /webhook/{destination}", span.Name())
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+	assert.Equal(t, codes.Error, span.Status().Code)
+	assert.Contains(t, span.Attributes(), attribute.Int("http.response.status_code", http.StatusBadGateway))
+
+	assert.Equal(t, span.SpanContext().SpanID(), handlerSpan.SpanID())
+}
+
+func TestInject(t *testing.T) {
+	setupRecorder(t)
+
+	ctx, span := Start(context.Background(), "send")
+	defer span.End()
+
+	header := http.Header{}
+	Inject(ctx, header)
+
+	traceparent := header.Get("traceparent")
+	require.NotEmpty(t, traceparent)
+	assert.Contains(t, traceparent, span.SpanContext().TraceID().String())
+	assert.Contains(t, traceparent, span.SpanContext().SpanID().String())
+}
+
+func TestEnd(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	_, okSpan := Start(context.Background(), "ok", attribute.String("destination", "slack"))
+	End(okSpan, nil)
+
+	_, failedSpan := Start(context.Background(), "failed")
+	End(failedSpan, errors.New("boom"))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Contains(t, spans[0].Attributes(), attribute.String("destination", "slack"))
+
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "boom", spans[1].Status().Description)
+	require.Len(t, spans[1].Events(), 1)
+	assert.Equal(t, "exception", spans[1].Events()[0].Name)
+}
+
+func TestSetup(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+	})
+
+	tests := []struct {
+		name    string
+		cfg     config.TracingConfig
+		wantErr bool
+	}{
+		{
+			name: "otlp exporter",
+			cfg: config.TracingConfig{
+				Exporter:    ExporterOTLP,
+				Endpoint:    "http://localhost:4318/v1/traces",
+				Headers:     map[string]string{"Authorization": "Bearer token"},
+				ServiceName: "alertmanager-gateway",
+				SampleRatio: 1,
+			},
+		},
+		{
+			name: "stdout exporter",
+			cfg: config.TracingConfig{
+				Exporter:    ExporterStdout,
+				ServiceName: "alertmanager-gateway",
+				SampleRatio: 0.5,
+			},
+		},
+		{
+			name:    "unknown exporter",
+			cfg:     config.TracingConfig{Exporter: "zipkin"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shutdown, err := Setup(context.Background(), tt.cfg, "1.0.0")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, shutdown)
+			assert.NoError(t, shutdown(context.Background()))
+		})
+	}
+}
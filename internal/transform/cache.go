@@ -3,25 +3,48 @@ package transform
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"strconv"
 	"sync"
 	"time"
+
+	"github.com/vitalvas/alertmanager-gateway/internal/cache"
+)
+
+// Defaults of the global template cache
+const (
+	DefaultCacheSize = 1000
+	DefaultCacheTTL  = time.Hour
 )
 
-// TemplateCache provides a thread-safe cache for compiled templates
+// Global template cache
+var (
+	globalTemplateCache     *TemplateCache
+	globalTemplateCacheOnce sync.Once
+)
+
+// InitTemplateCache initializes the global template cache; later calls have no effect
+func InitTemplateCache(maxSize int, ttl time.Duration) {
+	globalTemplateCacheOnce.Do(func() {
+		globalTemplateCache = NewTemplateCache(maxSize, ttl)
+		globalTemplateCache.cache.StartCleanupTask(5 * time.Minute)
+	})
+}
+
+// GetTemplateCache returns the global template cache shared by destinations and the API
+func GetTemplateCache() *TemplateCache {
+	// Initialize with defaults if not already initialized
+	InitTemplateCache(DefaultCacheSize, DefaultCacheTTL)
+	return globalTemplateCache
+}
+
+// TemplateCache shares compiled engines, keyed by engine type, content, library and timeout.
+// Cached engines are used concurrently and must not be modified.
 type TemplateCache struct {
-	mu      sync.RWMutex
-	engines map[string]*cacheEntry
+	cache   *cache.TemplateCache
 	maxSize int
 	ttl     time.Duration
 }
 
-type cacheEntry struct {
-	engine    Engine
-	createdAt time.Time
-	lastUsed  time.Time
-	hits      uint64
-}
-
 // NewTemplateCache creates a new template cache
 func NewTemplateCache(maxSize int, ttl time.Duration) *TemplateCache {
 	if maxSize <= 0 {
@@ -31,155 +54,115 @@ func NewTemplateCache(maxSize int, ttl time.Duration) *TemplateCache {
 		ttl = 1 * time.Hour
 	}
 
-	cache := &TemplateCache{
-		engines: make(map[string]*cacheEntry),
+	return &TemplateCache{
+		cache:   cache.NewTemplateCache(maxSize, ttl),
 		maxSize: maxSize,
 		ttl:     ttl,
 	}
-
-	// Start cleanup goroutine
-	go cache.cleanupLoop()
-
-	return cache
 }
 
-// Get retrieves an engine from the cache or creates a new one
+// Get retrieves an engine without shared templates from the cache or creates a new one
 func (c *TemplateCache) Get(engineType EngineType, template string) (Engine, error) {
-	key := c.generateKey(engineType, template)
+	return c.GetEngine(engineType, template, nil, 0)
+}
 
-	// Try to get from cache
-	c.mu.RLock()
-	entry, exists := c.engines[key]
-	c.mu.RUnlock()
+// GetEngine retrieves an engine from the cache or compiles a new one against lib with the given
+// execution timeout; zero uses DefaultTimeout
+func (c *TemplateCache) GetEngine(engineType EngineType, template string, lib *Library, timeout time.Duration) (Engine, error) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
 
-	if exists && !c.isExpired(entry) {
-		// Update last used time and hit count
-		c.mu.Lock()
-		entry.lastUsed = time.Now()
-		entry.hits++
-		c.mu.Unlock()
+	key := c.generateKey(engineType, template, lib, timeout)
 
-		return entry.engine, nil
+	if cached, ok := c.cache.Get(key); ok {
+		return cached.(Engine), nil
 	}
 
-	// Create new engine
-	engine, err := NewEngine(engineType, template)
+	engine, err := NewEngineWithLibrary(engineType, template, lib)
 	if err != nil {
 		return nil, err
 	}
 
-	// Add to cache
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	// Check size limit and evict if necessary
-	if len(c.engines) >= c.maxSize {
-		c.evictOldest()
+	if setter, ok := engine.(TimeoutSetter); ok {
+		setter.SetTimeout(timeout)
 	}
 
-	c.engines[key] = &cacheEntry{
-		engine:    engine,
-		createdAt: time.Now(),
-		lastUsed:  time.Now(),
-		hits:      1,
-	}
+	c.cache.Set(key, engine)
 
 	return engine, nil
 }
 
 // Clear removes all entries from the cache
 func (c *TemplateCache) Clear() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	c.engines = make(map[string]*cacheEntry)
+	c.cache.Clear()
 }
 
 // Size returns the current number of cached engines
 func (c *TemplateCache) Size() int {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	return len(c.engines)
+	return c.cache.Len()
 }
 
-// Stats returns cache statistics
+// Stats returns cache statistics and the render statistics of the cached engines
 func (c *TemplateCache) Stats() CacheStats {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	cacheStats := c.cache.Stats()
+	entries := c.cache.Entries()
 
 	stats := CacheStats{
-		Size:    len(c.engines),
-		MaxSize: c.maxSize,
-		TTL:     c.ttl,
-		Entries: make([]EntryStats, 0, len(c.engines)),
+		Size:      len(entries),
+		MaxSize:   c.maxSize,
+		TTL:       c.ttl,
+		Hits:      cacheStats.Hits,
+		Misses:    cacheStats.Misses,
+		Evictions: cacheStats.Evictions,
+		Entries:   make([]EntryStats, 0, len(entries)),
+	}
+
+	var renderTime time.Duration
+	for _, entry := range entries {
+		entryStats := EntryStats{
+			Key:       entry.Key,
+			CreatedAt: entry.CompiledAt,
+			LastUsed:  entry.LastUsed,
+			Hits:      entry.AccessCount,
+			Age:       time.Since(entry.CompiledAt),
+		}
+
+		if engine, ok := entry.Value.(Engine); ok {
+			entryStats.Engine = engine.Name()
+		}
+		if provider, ok := entry.Value.(RenderStatsProvider); ok {
+			entryStats.Render = provider.RenderStats()
+			stats.Renders += entryStats.Render.Renders
+			renderTime += entryStats.Render.AverageDuration * time.Duration(entryStats.Render.Renders)
+		}
+
+		stats.Entries = append(stats.Entries, entryStats)
 	}
 
-	for key, entry := range c.engines {
-		stats.Entries = append(stats.Entries, EntryStats{
-			Key:       key,
-			CreatedAt: entry.createdAt,
-			LastUsed:  entry.lastUsed,
-			Hits:      entry.hits,
-			Age:       time.Since(entry.createdAt),
-		})
-		stats.TotalHits += entry.hits
+	if stats.Renders > 0 {
+		stats.AverageRenderTime = renderTime / time.Duration(stats.Renders)
 	}
 
 	return stats
 }
 
-// generateKey creates a cache key from engine type and template
-func (c *TemplateCache) generateKey(engineType EngineType, template string) string {
+// generateKey creates a cache key from the engine type, template, library and timeout
+func (c *TemplateCache) generateKey(engineType EngineType, template string, lib *Library, timeout time.Duration) string {
 	h := sha256.New()
 	h.Write([]byte(engineType))
 	h.Write([]byte(":"))
+	h.Write([]byte(strconv.FormatUint(lib.ID(), 10)))
+	h.Write([]byte(":"))
+	h.Write([]byte(timeout.String()))
+	h.Write([]byte(":"))
 	h.Write([]byte(template))
 	return hex.EncodeToString(h.Sum(nil))
 }
 
-// isExpired checks if a cache entry has expired
-func (c *TemplateCache) isExpired(entry *cacheEntry) bool {
-	return time.Since(entry.createdAt) > c.ttl
-}
-
-// evictOldest removes the least recently used entry
-func (c *TemplateCache) evictOldest() {
-	var oldestKey string
-	var oldestTime time.Time
-
-	for key, entry := range c.engines {
-		if oldestKey == "" || entry.lastUsed.Before(oldestTime) {
-			oldestKey = key
-			oldestTime = entry.lastUsed
-		}
-	}
-
-	if oldestKey != "" {
-		delete(c.engines, oldestKey)
-	}
-}
-
-// cleanupLoop periodically removes expired entries
-func (c *TemplateCache) cleanupLoop() {
-	ticker := time.NewTicker(5 * time.Minute)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		c.cleanup()
-	}
-}
-
 // cleanup removes expired entries
 func (c *TemplateCache) cleanup() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	for key, entry := range c.engines {
-		if c.isExpired(entry) {
-			delete(c.engines, key)
-		}
-	}
+	c.cache.Purge()
 }
 
 // CacheStats holds cache statistics
@@ -187,15 +170,24 @@ type CacheStats struct {
 	Size      int
 	MaxSize   int
 	TTL       time.Duration
-	TotalHits uint64
-	Entries   []EntryStats
+	Hits      int64
+	Misses    int64
+	Evictions int64
+
+	// Executions of the cached engines
+	Renders           int64
+	AverageRenderTime time.Duration
+
+	Entries []EntryStats
 }
 
 // EntryStats holds statistics for a single cache entry
 type EntryStats struct {
 	Key       string
+	Engine    string
 	CreatedAt time.Time
 	LastUsed  time.Time
-	Hits      uint64
+	Hits      int64
 	Age       time.Duration
+	Render    RenderStats
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
 )
 
 func TestNewTemplateCache(t *testing.T) {
@@ -89,7 +90,8 @@ func TestTemplateCache_Stats(t *testing.T) {
 	assert.Equal(t, 2, stats.Size)
 	assert.Equal(t, 10, stats.MaxSize)
 	assert.Equal(t, time.Hour, stats.TTL)
-	assert.Equal(t, uint64(3), stats.TotalHits)
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, int64(2), stats.Misses)
 	assert.Len(t, stats.Entries, 2)
 }
 
@@ -119,15 +121,57 @@ func TestTemplateCache_GenerateKey(t *testing.T) {
 	cache := NewTemplateCache(10, time.Hour)
 
 	// Same input should generate same key
-	key1 := cache.generateKey(EngineTypeGoTemplate, `{{ .Status }}`)
-	key2 := cache.generateKey(EngineTypeGoTemplate, `{{ .Status }}`)
+	key1 := cache.generateKey(EngineTypeGoTemplate, `{{ .Status }}`, nil, 0)
+	key2 := cache.generateKey(EngineTypeGoTemplate, `{{ .Status }}`, nil, 0)
 	assert.Equal(t, key1, key2)
 
 	// Different template should generate different key
-	key3 := cache.generateKey(EngineTypeGoTemplate, `{{ .Version }}`)
+	key3 := cache.generateKey(EngineTypeGoTemplate, `{{ .Version }}`, nil, 0)
 	assert.NotEqual(t, key1, key3)
 
 	// Different engine type should generate different key
-	key4 := cache.generateKey(EngineTypeJQ, `{{ .Status }}`)
+	key4 := cache.generateKey(EngineTypeJQ, `{{ .Status }}`, nil, 0)
 	assert.NotEqual(t, key1, key4)
+
+	// Different library or timeout should generate different key
+	lib, err := NewLibrary(nil, nil)
+	require.NoError(t, err)
+	key5 := cache.generateKey(EngineTypeGoTemplate, `{{ .Status }}`, lib, 0)
+	assert.NotEqual(t, key1, key5)
+
+	key6 := cache.generateKey(EngineTypeGoTemplate, `{{ .Status }}`, nil, time.Second)
+	assert.NotEqual(t, key1, key6)
+}
+
+func TestTemplateCache_GetEngine(t *testing.T) {
+	cache := NewTemplateCache(10, time.Hour)
+
+	engine1, err := cache.GetEngine(EngineTypeJQ, `.status`, nil, time.Second)
+	require.NoError(t, err)
+
+	// The same transformation shares one compiled engine
+	engine2, err := cache.GetEngine(EngineTypeJQ, `.status`, nil, time.Second)
+	require.NoError(t, err)
+	assert.Same(t, engine1, engine2)
+
+	// A zero timeout is the default timeout
+	engine3, err := cache.GetEngine(EngineTypeJQ, `.status`, nil, 0)
+	require.NoError(t, err)
+	engine4, err := cache.GetEngine(EngineTypeJQ, `.status`, nil, DefaultTimeout)
+	require.NoError(t, err)
+	assert.Same(t, engine3, engine4)
+	assert.NotSame(t, engine1, engine3)
+
+	payload := &alertmanager.WebhookPayload{Status: "firing"}
+	for range 3 {
+		_, err := engine1.Transform(payload)
+		require.NoError(t, err)
+	}
+
+	stats := cache.Stats()
+	assert.Equal(t, int64(3), stats.Renders)
+	assert.Positive(t, stats.AverageRenderTime)
+	for _, entry := range stats.Entries {
+		assert.Equal(t, "jq", entry.Engine)
+	}
 }
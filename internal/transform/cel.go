@@ -0,0 +1,237 @@
+package transform
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/google/cel-go/ext"
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+)
+
+// CELCostLimit bounds the work of a single CEL evaluation. CEL expressions are limited
+// by cost instead of a wall-clock timeout, so a transformation can't hang a delivery.
+const CELCostLimit = 1_000_000
+
+var (
+	celEnvOnce sync.Once
+	celEnv     *cel.Env
+	celEnvErr  error
+)
+
+// CELEnvironment returns the environment CEL expressions are checked against. Payload and
+// alert fields use their JSON names, as in jq queries.
+func CELEnvironment() (*cel.Env, error) {
+	celEnvOnce.Do(func() {
+		celEnv, celEnvErr = cel.NewEnv(
+			ext.NativeTypes(
+				reflect.TypeOf(&alertmanager.WebhookPayload{}),
+				reflect.TypeOf(&alertmanager.Alert{}),
+				ext.ParseStructTag("json"),
+			),
+			ext.Strings(),
+			cel.OptionalTypes(),
+			cel.Variable("payload", cel.ObjectType("alertmanager.WebhookPayload")),
+			cel.Variable("alert", cel.ObjectType("alertmanager.Alert")),
+		)
+	})
+
+	return celEnv, celEnvErr
+}
+
+// CELEngine implements the CEL transformation engine
+type CELEngine struct {
+	expression string
+	program    cel.Program
+	renders    renderCounter
+}
+
+// NewCELEngine creates a CEL engine. The expression is type-checked, so unknown fields
+// such as alert.lables are reported here rather than when alerts arrive.
+func NewCELEngine(expression string) (*CELEngine, error) {
+	if expression == "" {
+		return nil, fmt.Errorf("cel expression cannot be empty")
+	}
+
+	env, err := CELEnvironment()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cel environment: %w", err)
+	}
+
+	ast, issues := env.Compile(expression)
+	if issues.Err() != nil {
+		return nil, fmt.Errorf("failed to compile cel expression: %w", issues.Err())
+	}
+
+	program, err := env.Program(ast, cel.CostLimit(CELCostLimit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cel program: %w", err)
+	}
+
+	return &CELEngine{
+		expression: expression,
+		program:    program,
+	}, nil
+}
+
+// Transform evaluates the expression for the webhook payload. The alert variable
+// refers to the first alert of the payload.
+func (c *CELEngine) Transform(payload *alertmanager.WebhookPayload) (interface{}, error) {
+	defer c.renders.record(time.Now())
+
+	alert := &alertmanager.Alert{}
+	if len(payload.Alerts) > 0 {
+		alert = &payload.Alerts[0]
+	}
+
+	result, err := c.evaluate(alert, payload)
+	if err != nil {
+		return nil, fmt.Errorf("cel transformation failed: %w", err)
+	}
+
+	return result, nil
+}
+
+// TransformAlert evaluates the expression for a single alert (for split mode)
+func (c *CELEngine) TransformAlert(alert *alertmanager.Alert, payload *alertmanager.WebhookPayload) (interface{}, error) {
+	defer c.renders.record(time.Now())
+
+	result, err := c.evaluate(alert, payload)
+	if err != nil {
+		return nil, fmt.Errorf("cel alert transformation failed: %w", err)
+	}
+
+	return result, nil
+}
+
+// evaluate runs the program and converts its result to JSON-compatible values
+func (c *CELEngine) evaluate(alert *alertmanager.Alert, payload *alertmanager.WebhookPayload) (interface{}, error) {
+	val, _, err := c.program.Eval(map[string]interface{}{
+		"payload": payload,
+		"alert":   alert,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return celToValue(val)
+}
+
+// celToValue converts a CEL value to the values produced by decoding JSON
+func celToValue(val ref.Val) (interface{}, error) {
+	switch v := val.(type) {
+	case types.Null:
+		return nil, nil
+	case types.Bool:
+		return bool(v), nil
+	case types.Int:
+		return float64(v), nil
+	case types.Uint:
+		return float64(v), nil
+	case types.Double:
+		return float64(v), nil
+	case types.String:
+		return string(v), nil
+	case types.Timestamp:
+		return v.Time.Format(time.RFC3339Nano), nil
+	case types.Duration:
+		return v.Duration.String(), nil
+	case traits.Mapper:
+		result := make(map[string]interface{})
+		for it := v.Iterator(); it.HasNext() == types.True; {
+			key := it.Next()
+			name, ok := key.(types.String)
+			if !ok {
+				return nil, fmt.Errorf("cel map keys must be strings, got %s", key.Type())
+			}
+			item, err := celToValue(v.Get(key))
+			if err != nil {
+				return nil, err
+			}
+			result[string(name)] = item
+		}
+		return result, nil
+	case traits.Lister:
+		result := make([]interface{}, 0)
+		for it := v.Iterator(); it.HasNext() == types.True; {
+			item, err := celToValue(it.Next())
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, item)
+		}
+		return result, nil
+	}
+
+	// Payloads and alerts are returned as their JSON representation
+	switch v := val.Value().(type) {
+	case *alertmanager.WebhookPayload:
+		return payloadToValue(v), nil
+	case alertmanager.WebhookPayload:
+		return payloadToValue(&v), nil
+	case *alertmanager.Alert:
+		return alertToValue(v), nil
+	case alertmanager.Alert:
+		return alertToValue(&v), nil
+	}
+
+	return nil, fmt.Errorf("unsupported cel result type %s", val.Type())
+}
+
+// Validate checks the expression against a sample payload
+func (c *CELEngine) Validate() error {
+	payload := &alertmanager.WebhookPayload{
+		Version:  "4",
+		GroupKey: "test",
+		Status:   "firing",
+		Receiver: "test",
+		GroupLabels: map[string]string{
+			"alertname": "TestAlert",
+		},
+		CommonLabels: map[string]string{
+			"alertname": "TestAlert",
+			"severity":  "warning",
+		},
+		CommonAnnotations: map[string]string{
+			"summary": "Test alert",
+		},
+		ExternalURL: "http://alertmanager.example.com",
+		Alerts: []alertmanager.Alert{
+			{
+				Status:      "firing",
+				Fingerprint: "test123",
+				Labels: map[string]string{
+					"alertname": "TestAlert",
+					"severity":  "warning",
+				},
+				Annotations: map[string]string{
+					"summary": "Test alert",
+				},
+				StartsAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	_, err := c.evaluate(&payload.Alerts[0], payload)
+	return err
+}
+
+// Name returns the engine name
+func (c *CELEngine) Name() string {
+	return "cel"
+}
+
+// RenderStats returns the number and average duration of transformations
+func (c *CELEngine) RenderStats() RenderStats {
+	return c.renders.stats()
+}
+
+// GetExpression returns the CEL expression
+func (c *CELEngine) GetExpression() string {
+	return c.expression
+}
@@ -0,0 +1,198 @@
+package transform
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+)
+
+func TestNewCELEngine(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		wantErr    bool
+		errMsg     string
+	}{
+		{
+			name:       "valid field mapping",
+			expression: `{"name": alert.labels.alertname, "status": payload.status}`,
+		},
+		{
+			name:       "valid filter",
+			expression: `payload.alerts.filter(a, a.labels["severity"] == "critical").map(a, a.fingerprint)`,
+		},
+		{
+			name:       "empty expression",
+			expression: "",
+			wantErr:    true,
+			errMsg:     "cel expression cannot be empty",
+		},
+		{
+			name:       "invalid syntax",
+			expression: `{"name": alert.labels.`,
+			wantErr:    true,
+			errMsg:     "failed to compile cel expression",
+		},
+		{
+			name:       "misspelled alert field",
+			expression: `{"name": alert.lables.alertname}`,
+			wantErr:    true,
+			errMsg:     "undefined field 'lables'",
+		},
+		{
+			name:       "misspelled payload field",
+			expression: `payload.commonLabel.severity`,
+			wantErr:    true,
+			errMsg:     "undefined field 'commonLabel'",
+		},
+		{
+			name:       "type mismatch",
+			expression: `alert.labels.severity + 1`,
+			wantErr:    true,
+			errMsg:     "no matching overload",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, err := NewCELEngine(tt.expression)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+				assert.Nil(t, engine)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, engine)
+				assert.Equal(t, "cel", engine.Name())
+				assert.Equal(t, tt.expression, engine.GetExpression())
+			}
+		})
+	}
+}
+
+func TestCELEngine_Transform(t *testing.T) {
+	startsAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	payload := &alertmanager.WebhookPayload{
+		Status:       "firing",
+		Receiver:     "pager",
+		CommonLabels: map[string]string{"severity": "critical"},
+		Alerts: []alertmanager.Alert{
+			{
+				Status:      "firing",
+				Fingerprint: "abc",
+				Labels:      map[string]string{"alertname": "HighCPU", "severity": "critical"},
+				StartsAt:    startsAt,
+			},
+			{
+				Status:      "firing",
+				Fingerprint: "def",
+				Labels:      map[string]string{"alertname": "HighMemory", "severity": "warning"},
+				StartsAt:    startsAt,
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		expected   interface{}
+	}{
+		{
+			name:       "field mapping",
+			expression: `{"receiver": payload.receiver, "count": size(payload.alerts), "first": alert.labels.alertname}`,
+			expected: map[string]interface{}{
+				"receiver": "pager",
+				"count":    float64(2),
+				"first":    "HighCPU",
+			},
+		},
+		{
+			name:       "filtering",
+			expression: `payload.alerts.filter(a, a.labels.severity == "critical").map(a, a.fingerprint)`,
+			expected:   []interface{}{"abc"},
+		},
+		{
+			name:       "optional label",
+			expression: `alert.labels[?"team"].orValue("unassigned")`,
+			expected:   "unassigned",
+		},
+		{
+			name:       "timestamp",
+			expression: `{"startsAt": alert.startsAt}`,
+			expected:   map[string]interface{}{"startsAt": "2024-05-01T12:00:00Z"},
+		},
+		{
+			name:       "alert object",
+			expression: `payload.alerts[1]`,
+			expected:   alertToValue(&payload.Alerts[1]),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, err := NewCELEngine(tt.expression)
+			require.NoError(t, err)
+
+			result, err := engine.Transform(payload)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestCELEngine_TransformAlert(t *testing.T) {
+	engine, err := NewCELEngine(`{"name": alert.labels.alertname, "receiver": payload.receiver}`)
+	require.NoError(t, err)
+
+	payload := &alertmanager.WebhookPayload{
+		Receiver: "pager",
+		Alerts: []alertmanager.Alert{
+			{Labels: map[string]string{"alertname": "First"}},
+			{Labels: map[string]string{"alertname": "Second"}},
+		},
+	}
+
+	result, err := engine.TransformAlert(&payload.Alerts[1], payload)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "Second", "receiver": "pager"}, result)
+	assert.Equal(t, int64(1), engine.RenderStats().Renders)
+}
+
+func TestCELEngine_CostLimit(t *testing.T) {
+	// Nested comprehensions over a small list exceed the limit long before a timeout would
+	engine, err := NewCELEngine(`[0,1,2,3,4,5,6,7,8,9].map(a, [0,1,2,3,4,5,6,7,8,9].map(b,
+		[0,1,2,3,4,5,6,7,8,9].map(c, [0,1,2,3,4,5,6,7,8,9].map(d,
+		[0,1,2,3,4,5,6,7,8,9].map(e, [0,1,2,3,4,5,6,7,8,9].map(f, a + b + c + d + e + f))))))`)
+	require.NoError(t, err)
+
+	_, err = engine.Transform(&alertmanager.WebhookPayload{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cost limit exceeded")
+}
+
+func TestCELEngine_Validate(t *testing.T) {
+	engine, err := NewCELEngine(`{"text": alert.annotations.summary}`)
+	require.NoError(t, err)
+	assert.NoError(t, engine.Validate())
+
+	// Labels missing from the sample alert fail at evaluation, not compilation
+	engine, err = NewCELEngine(`alert.labels.team`)
+	require.NoError(t, err)
+	assert.Error(t, engine.Validate())
+}
+
+func TestNewEngine_CEL(t *testing.T) {
+	engine, err := NewEngine(EngineTypeCEL, `payload.status`)
+	require.NoError(t, err)
+
+	_, ok := engine.(*CELEngine)
+	assert.True(t, ok)
+
+	result, err := engine.Transform(&alertmanager.WebhookPayload{Status: "resolved"})
+	require.NoError(t, err)
+	assert.Equal(t, "resolved", result)
+}
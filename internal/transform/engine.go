@@ -2,6 +2,7 @@ package transform
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
 )
@@ -21,6 +22,15 @@ type Engine interface {
 	Name() string
 }
 
+// DefaultTimeout limits a single template or jq execution
+const DefaultTimeout = 5 * time.Second
+
+// TimeoutSetter is implemented by engines with a configurable execution timeout
+type TimeoutSetter interface {
+	// SetTimeout sets the execution timeout; zero restores DefaultTimeout
+	SetTimeout(timeout time.Duration)
+}
+
 // EngineType represents the type of transformation engine
 type EngineType string
 
@@ -30,15 +40,30 @@ const (
 
 	// EngineTypeJQ represents jq transformation engine
 	EngineTypeJQ EngineType = "jq"
+
+	// EngineTypeCEL represents CEL expression engine
+	EngineTypeCEL EngineType = "cel"
+
+	// EngineTypeStarlark represents Starlark scripting engine
+	EngineTypeStarlark EngineType = "starlark"
 )
 
 // NewEngine creates a new transformation engine based on the type
 func NewEngine(engineType EngineType, template string) (Engine, error) {
+	return NewEngineWithLibrary(engineType, template, nil)
+}
+
+// NewEngineWithLibrary creates a transformation engine that can use shared templates and jq modules
+func NewEngineWithLibrary(engineType EngineType, template string, lib *Library) (Engine, error) {
 	switch engineType {
 	case EngineTypeGoTemplate:
-		return NewGoTemplateEngine(template)
+		return NewGoTemplateEngineWithLibrary(template, lib)
 	case EngineTypeJQ:
-		return NewJQEngine(template)
+		return NewJQEngineWithLibrary(template, lib)
+	case EngineTypeCEL:
+		return NewCELEngine(template)
+	case EngineTypeStarlark:
+		return NewStarlarkEngine(template)
 	default:
 		return nil, fmt.Errorf("unknown engine type: %s", engineType)
 	}
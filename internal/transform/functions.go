@@ -32,7 +32,7 @@ func GetTemplateFuncs() template.FuncMap {
 		"contains":   strings.Contains,
 		"hasPrefix":  strings.HasPrefix,
 		"hasSuffix":  strings.HasSuffix,
-		"repeat":     strings.Repeat,
+		"repeat":     repeat,
 
 		// URL functions
 		"urlquery":  url.QueryEscape,
@@ -112,6 +112,17 @@ func toTitle(s string) string {
 	return strings.Join(words, " ")
 }
 
+// repeat is strings.Repeat limited to the size of a template output
+func repeat(s string, count int) (string, error) {
+	if count < 0 {
+		return "", fmt.Errorf("negative repeat count")
+	}
+	if count > 0 && len(s) > maxTemplateOutput/count {
+		return "", errTemplateOutputLimit
+	}
+	return strings.Repeat(s, count), nil
+}
+
 func parseURL(urlStr string) (*url.URL, error) {
 	return url.Parse(urlStr)
 }
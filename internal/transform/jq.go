@@ -2,7 +2,6 @@ package transform
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"sync"
 	"time"
@@ -15,11 +14,19 @@ import (
 type JQEngine struct {
 	query         string
 	compiledQuery *gojq.Code
+	timeout       time.Duration
+	renders       renderCounter
+	payloads      payloadValueCache
 	mu            sync.RWMutex
 }
 
 // NewJQEngine creates a new jq transformation engine
 func NewJQEngine(query string) (*JQEngine, error) {
+	return NewJQEngineWithLibrary(query, nil)
+}
+
+// NewJQEngineWithLibrary creates a jq engine whose query can import and include the modules of lib
+func NewJQEngineWithLibrary(query string, lib *Library) (*JQEngine, error) {
 	if query == "" {
 		return nil, fmt.Errorf("jq query cannot be empty")
 	}
@@ -31,7 +38,12 @@ func NewJQEngine(query string) (*JQEngine, error) {
 	}
 
 	// Compile the query for better performance
-	compiledQuery, err := gojq.Compile(q)
+	var options []gojq.CompilerOption
+	if lib != nil {
+		options = append(options, gojq.WithModuleLoader(lib))
+	}
+
+	compiledQuery, err := gojq.Compile(q, options...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to compile jq query: %w", err)
 	}
@@ -39,30 +51,34 @@ func NewJQEngine(query string) (*JQEngine, error) {
 	return &JQEngine{
 		query:         query,
 		compiledQuery: compiledQuery,
+		timeout:       DefaultTimeout,
 	}, nil
 }
 
+// SetTimeout sets the query execution timeout; zero restores DefaultTimeout
+func (j *JQEngine) SetTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.timeout = timeout
+}
+
 // Transform applies the jq transformation to the webhook payload
 func (j *JQEngine) Transform(payload *alertmanager.WebhookPayload) (interface{}, error) {
-	j.mu.RLock()
-	defer j.mu.RUnlock()
+	defer j.renders.record(time.Now())
 
-	// Convert payload to interface{} for jq processing
-	var data interface{}
-	jsonBytes, err := json.Marshal(payload)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal payload: %w", err)
-	}
-
-	if err := json.Unmarshal(jsonBytes, &data); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal payload for jq: %w", err)
-	}
+	j.mu.RLock()
+	timeout := j.timeout
+	j.mu.RUnlock()
 
-	// Execute the jq query with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	result, err := j.executeQuery(ctx, data)
+	result, err := j.executeQuery(ctx, payloadToValue(payload))
 	if err != nil {
 		return nil, fmt.Errorf("jq transformation failed: %w", err)
 	}
@@ -70,86 +86,68 @@ func (j *JQEngine) Transform(payload *alertmanager.WebhookPayload) (interface{},
 	return result, nil
 }
 
-// TransformAlert transforms a single alert using jq
+// TransformAlert transforms a single alert using jq. The converted payload is reused for
+// the other alerts of the same payload.
 func (j *JQEngine) TransformAlert(alert *alertmanager.Alert, payload *alertmanager.WebhookPayload) (interface{}, error) {
-	j.mu.RLock()
-	defer j.mu.RUnlock()
-
-	// Create context with both alert and payload data
-	contextData := map[string]interface{}{
-		"alert":   alert,
-		"payload": payload,
-	}
+	defer j.renders.record(time.Now())
 
-	// Convert to interface{} for jq processing
-	var data interface{}
-	jsonBytes, err := json.Marshal(contextData)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal alert context: %w", err)
-	}
+	j.mu.RLock()
+	timeout := j.timeout
+	j.mu.RUnlock()
 
-	if err := json.Unmarshal(jsonBytes, &data); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal alert context for jq: %w", err)
-	}
+	values := j.payloads.values(payload)
+	payloadValue := values.get()
 
-	// Execute the jq query with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	result, err := j.executeQuery(ctx, data)
+	result, err := j.executeQuery(ctx, map[string]interface{}{
+		"alert":   alertToValue(alert),
+		"payload": payloadValue,
+	})
 	if err != nil {
+		// Errors can carry parts of the payload, so its value is not reused
 		return nil, fmt.Errorf("jq alert transformation failed: %w", err)
 	}
 
+	// The result may refer to parts of the payload, which is handed to the next query
+	result = copyValue(result)
+	values.put(payloadValue)
+
 	return result, nil
 }
 
-// executeQuery executes the compiled jq query with context and timeout
-func (j *JQEngine) executeQuery(ctx context.Context, data interface{}) (interface{}, error) {
-	// Create a channel to receive the result
-	resultChan := make(chan interface{}, 1)
-	errorChan := make(chan error, 1)
-
-	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				errorChan <- fmt.Errorf("jq query panicked: %v", r)
-			}
-		}()
+// executeQuery executes the compiled jq query, stopping when the context is done
+func (j *JQEngine) executeQuery(ctx context.Context, data interface{}) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("jq query panicked: %v", r)
+		}
+	}()
 
-		// Run the jq query
-		iter := j.compiledQuery.Run(data)
-		results := make([]interface{}, 0)
+	iter := j.compiledQuery.RunWithContext(ctx, data)
+	results := make([]interface{}, 0)
 
-		for {
-			v, ok := iter.Next()
-			if !ok {
-				break
-			}
-			if err, ok := v.(error); ok {
-				errorChan <- err
-				return
-			}
-			results = append(results, v)
+	for {
+		v, ok := iter.Next()
+		if !ok {
+			break
 		}
-
-		// Return single result if only one, array if multiple
-		if len(results) == 1 {
-			resultChan <- results[0]
-		} else {
-			resultChan <- results
+		if err, ok := v.(error); ok {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("jq query timed out")
+			}
+			return nil, err
 		}
-	}()
+		results = append(results, v)
+	}
 
-	// Wait for result or timeout
-	select {
-	case result := <-resultChan:
-		return result, nil
-	case err := <-errorChan:
-		return nil, err
-	case <-ctx.Done():
-		return nil, fmt.Errorf("jq query timed out")
+	// Return single result if only one, array if multiple
+	if len(results) == 1 {
+		return results[0], nil
 	}
+
+	return results, nil
 }
 
 // Validate checks if the jq query is valid
@@ -207,6 +205,11 @@ func (j *JQEngine) Name() string {
 	return "jq"
 }
 
+// RenderStats returns the number and average duration of transformations
+func (j *JQEngine) RenderStats() RenderStats {
+	return j.renders.stats()
+}
+
 // GetQuery returns the jq query string
 func (j *JQEngine) GetQuery() string {
 	j.mu.RLock()
@@ -477,3 +477,13 @@ func TestJQEngine_Advanced(t *testing.T) {
 		assert.Contains(t, err.Error(), "jq transformation failed")
 	})
 }
+
+func TestJQEngine_Timeout(t *testing.T) {
+	engine, err := NewJQEngine(`[range(10000000)] | length`)
+	require.NoError(t, err)
+	engine.SetTimeout(time.Millisecond)
+
+	_, err = engine.Transform(&alertmanager.WebhookPayload{Status: "firing"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out")
+}
@@ -0,0 +1,141 @@
+package transform
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+)
+
+// payloadToValue converts a webhook payload to the value gojq would see after a JSON
+// round-trip, without encoding it
+func payloadToValue(payload *alertmanager.WebhookPayload) map[string]interface{} {
+	var alerts interface{}
+	if payload.Alerts != nil {
+		values := make([]interface{}, len(payload.Alerts))
+		for i := range payload.Alerts {
+			values[i] = alertToValue(&payload.Alerts[i])
+		}
+		alerts = values
+	}
+
+	return map[string]interface{}{
+		"version":           payload.Version,
+		"groupKey":          payload.GroupKey,
+		"truncatedAlerts":   float64(payload.TruncatedAlerts),
+		"status":            payload.Status,
+		"receiver":          payload.Receiver,
+		"groupLabels":       stringMapToValue(payload.GroupLabels),
+		"commonLabels":      stringMapToValue(payload.CommonLabels),
+		"commonAnnotations": stringMapToValue(payload.CommonAnnotations),
+		"externalURL":       payload.ExternalURL,
+		"alerts":            alerts,
+	}
+}
+
+// alertToValue converts an alert to its gojq value
+func alertToValue(alert *alertmanager.Alert) map[string]interface{} {
+	return map[string]interface{}{
+		"status":       alert.Status,
+		"labels":       stringMapToValue(alert.Labels),
+		"annotations":  stringMapToValue(alert.Annotations),
+		"startsAt":     alert.StartsAt.Format(time.RFC3339Nano),
+		"endsAt":       alert.EndsAt.Format(time.RFC3339Nano),
+		"generatorURL": alert.GeneratorURL,
+		"fingerprint":  alert.Fingerprint,
+	}
+}
+
+// stringMapToValue converts labels or annotations; nil maps become null like in JSON
+func stringMapToValue(m map[string]string) interface{} {
+	if m == nil {
+		return nil
+	}
+
+	value := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		value[k] = v
+	}
+
+	return value
+}
+
+// copyValue deep-copies the maps and arrays of a gojq value
+func copyValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for k, x := range v {
+			copied[k] = copyValue(x)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, x := range v {
+			copied[i] = copyValue(x)
+		}
+		return copied
+	default:
+		return v
+	}
+}
+
+// payloadValues reuses the converted values of one payload across its alerts. gojq writes
+// to its input while normalizing numbers, so each value is used by one query at a time.
+type payloadValues struct {
+	payload *alertmanager.WebhookPayload
+	alerts  *alertmanager.Alert
+	count   int
+	pool    sync.Pool
+}
+
+// matches reports whether the values were converted from payload; payloads must not be
+// modified while their alerts are transformed
+func (p *payloadValues) matches(payload *alertmanager.WebhookPayload) bool {
+	return p.payload == payload && p.count == len(payload.Alerts) && p.alerts == firstAlert(payload)
+}
+
+// get returns a converted value for exclusive use
+func (p *payloadValues) get() map[string]interface{} {
+	if value, ok := p.pool.Get().(map[string]interface{}); ok {
+		return value
+	}
+
+	return payloadToValue(p.payload)
+}
+
+// put returns a value once no query result refers to it
+func (p *payloadValues) put(value map[string]interface{}) {
+	p.pool.Put(value)
+}
+
+// payloadValueCache holds the converted values of the most recent payload
+type payloadValueCache struct {
+	current atomic.Pointer[payloadValues]
+}
+
+// values returns the reusable values of payload
+func (c *payloadValueCache) values(payload *alertmanager.WebhookPayload) *payloadValues {
+	if current := c.current.Load(); current != nil && current.matches(payload) {
+		return current
+	}
+
+	values := &payloadValues{
+		payload: payload,
+		alerts:  firstAlert(payload),
+		count:   len(payload.Alerts),
+	}
+	c.current.Store(values)
+
+	return values
+}
+
+// firstAlert identifies the alerts slice of a payload
+func firstAlert(payload *alertmanager.WebhookPayload) *alertmanager.Alert {
+	if len(payload.Alerts) == 0 {
+		return nil
+	}
+
+	return &payload.Alerts[0]
+}
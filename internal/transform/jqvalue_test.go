@@ -0,0 +1,186 @@
+package transform
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+)
+
+// newLargePayload returns a payload with count firing alerts
+func newLargePayload(count int) *alertmanager.WebhookPayload {
+	payload := &alertmanager.WebhookPayload{
+		Version:           "4",
+		GroupKey:          `{}:{alertname="HighCPU"}`,
+		TruncatedAlerts:   2,
+		Status:            "firing",
+		Receiver:          "team",
+		GroupLabels:       map[string]string{"alertname": "HighCPU"},
+		CommonLabels:      map[string]string{"alertname": "HighCPU", "severity": "critical"},
+		CommonAnnotations: map[string]string{"runbook": "https://runbooks.example.com/cpu"},
+		ExternalURL:       "http://alertmanager.example.com",
+		Alerts:            make([]alertmanager.Alert, count),
+	}
+
+	startsAt := time.Date(2024, 1, 1, 12, 0, 0, 123456789, time.UTC)
+	for i := range payload.Alerts {
+		payload.Alerts[i] = alertmanager.Alert{
+			Status: "firing",
+			Labels: map[string]string{
+				"alertname": "HighCPU",
+				"instance":  fmt.Sprintf("server-%d:9100", i),
+				"job":       "node",
+				"severity":  "critical",
+			},
+			Annotations:  map[string]string{"summary": fmt.Sprintf("CPU usage is high on server-%d", i)},
+			StartsAt:     startsAt,
+			GeneratorURL: "http://prometheus.example.com/graph",
+			Fingerprint:  fmt.Sprintf("%016x", i),
+		}
+	}
+
+	return payload
+}
+
+// jsonValue converts v through a JSON round-trip, as the jq engine used to
+func jsonValue(t testing.TB, v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	var value interface{}
+	require.NoError(t, json.Unmarshal(data, &value))
+
+	return value
+}
+
+func TestPayloadToValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload *alertmanager.WebhookPayload
+	}{
+		{name: "full payload", payload: newLargePayload(3)},
+		{name: "empty payload", payload: &alertmanager.WebhookPayload{}},
+		{name: "no alerts", payload: &alertmanager.WebhookPayload{Status: "resolved", Alerts: []alertmanager.Alert{}}},
+		{
+			name: "alert without labels",
+			payload: &alertmanager.WebhookPayload{
+				Alerts: []alertmanager.Alert{{Status: "resolved", EndsAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.FixedZone("CET", 3600))}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, jsonValue(t, tt.payload), interface{}(payloadToValue(tt.payload)))
+
+			for i := range tt.payload.Alerts {
+				assert.Equal(t, jsonValue(t, &tt.payload.Alerts[i]), interface{}(alertToValue(&tt.payload.Alerts[i])))
+			}
+		})
+	}
+}
+
+func TestJQEngine_TransformAlertConcurrent(t *testing.T) {
+	engine, err := NewJQEngine(`{instance: .alert.labels.instance, labels: .payload.commonLabels, total: (.payload.alerts | length)}`)
+	require.NoError(t, err)
+
+	payload := newLargePayload(200)
+
+	var wg sync.WaitGroup
+	results := make([]interface{}, len(payload.Alerts))
+	errs := make([]error, len(payload.Alerts))
+	for i := range payload.Alerts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = engine.TransformAlert(&payload.Alerts[i], payload)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, result := range results {
+		require.NoError(t, errs[i])
+
+		output := result.(map[string]interface{})
+		assert.Equal(t, fmt.Sprintf("server-%d:9100", i), output["instance"])
+		assert.Equal(t, 200, output["total"])
+
+		// Results do not share maps with the reused payload
+		output["labels"].(map[string]interface{})["severity"] = "changed"
+	}
+
+	result, err := engine.TransformAlert(&payload.Alerts[0], payload)
+	require.NoError(t, err)
+	assert.Equal(t, "critical", result.(map[string]interface{})["labels"].(map[string]interface{})["severity"])
+}
+
+func TestPayloadValueCache(t *testing.T) {
+	var cache payloadValueCache
+
+	payload := newLargePayload(2)
+	values := cache.values(payload)
+	assert.Same(t, values, cache.values(payload))
+
+	// Another payload, or the same one with other alerts, is converted again
+	other := newLargePayload(2)
+	assert.NotSame(t, values, cache.values(other))
+
+	other.Alerts = other.Alerts[:1]
+	assert.NotSame(t, cache.values(other), values)
+	assert.Len(t, cache.values(other).get()["alerts"], 1)
+}
+
+func BenchmarkJQEngine_TransformAlert(b *testing.B) {
+	engine, err := NewJQEngine(`{
+		routing_key: "key",
+		event_action: (if .alert.status == "firing" then "trigger" else "resolve" end),
+		dedup_key: .alert.fingerprint,
+		payload: {summary: .alert.annotations.summary, source: .alert.labels.instance, severity: .payload.commonLabels.severity}
+	}`)
+	require.NoError(b, err)
+
+	payload := newLargePayload(500)
+
+	// Each iteration transforms every alert of the payload in parallel, as in split mode
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, 10)
+		for i := range payload.Alerts {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(alert *alertmanager.Alert) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				if _, err := engine.TransformAlert(alert, payload); err != nil {
+					b.Error(err)
+				}
+			}(&payload.Alerts[i])
+		}
+		wg.Wait()
+	}
+}
+
+func BenchmarkPayloadToValue(b *testing.B) {
+	payload := newLargePayload(500)
+
+	b.Run("direct", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			payloadToValue(payload)
+		}
+	})
+
+	b.Run("json", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			jsonValue(b, payload)
+		}
+	})
+}
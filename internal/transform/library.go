@@ -0,0 +1,169 @@
+package transform
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"text/template"
+
+	"github.com/itchyny/gojq"
+)
+
+// File extensions of shared templates and jq modules loaded from a directory
+const (
+	TemplateFileExt = ".tmpl"
+	ModuleFileExt   = ".jq"
+)
+
+// Library holds named Go templates and jq modules shared by all destinations. Go template
+// destinations use them with {{ template "name" . }}; jq destinations with import or include.
+type Library struct {
+	id        uint64
+	templates *template.Template
+	modules   map[string]string
+}
+
+// libraryIDs numbers libraries so engines compiled against different ones are cached apart
+var libraryIDs atomic.Uint64
+
+// NewLibrary parses shared Go templates and jq modules by name
+func NewLibrary(templates, modules map[string]string) (*Library, error) {
+	lib := &Library{id: libraryIDs.Add(1), modules: make(map[string]string, len(modules))}
+
+	if len(templates) > 0 {
+		base := template.New("").Funcs(GetTemplateFuncs()).Option("missingkey=default")
+		for _, name := range sortedKeys(templates) {
+			if _, err := base.New(name).Parse(templates[name]); err != nil {
+				return nil, fmt.Errorf("failed to parse shared template %s: %w", name, err)
+			}
+		}
+		lib.templates = base
+	}
+
+	for name, source := range modules {
+		if _, err := gojq.Parse(source); err != nil {
+			return nil, fmt.Errorf("failed to parse jq module %s: %w", name, err)
+		}
+		lib.modules[name] = source
+	}
+
+	return lib, nil
+}
+
+// LoadLibrary creates a library from the *.tmpl and *.jq files of a directory and inline
+// definitions. The file name without extension is the template or module name.
+func LoadLibrary(dir string, templates, modules map[string]string) (*Library, error) {
+	allTemplates := make(map[string]string, len(templates))
+	allModules := make(map[string]string, len(modules))
+
+	for name, source := range templates {
+		allTemplates[name] = source
+	}
+	for name, source := range modules {
+		allModules[name] = source
+	}
+
+	if dir != "" {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read templates directory: %w", err)
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+
+			ext := filepath.Ext(entry.Name())
+			target := allTemplates
+			switch ext {
+			case TemplateFileExt:
+			case ModuleFileExt:
+				target = allModules
+			default:
+				continue
+			}
+
+			name := strings.TrimSuffix(entry.Name(), ext)
+			if _, exists := target[name]; exists {
+				return nil, fmt.Errorf("%s is defined both inline and in %s", name, entry.Name())
+			}
+
+			content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+			}
+			target[name] = string(content)
+		}
+	}
+
+	return NewLibrary(allTemplates, allModules)
+}
+
+// Templates returns the names of the shared templates, including those defined inside them
+func (l *Library) Templates() []string {
+	if l == nil || l.templates == nil {
+		return []string{}
+	}
+
+	names := make([]string, 0)
+	for _, tmpl := range l.templates.Templates() {
+		if tmpl.Name() != "" {
+			names = append(names, tmpl.Name())
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Modules returns the names of the jq modules
+func (l *Library) Modules() []string {
+	if l == nil {
+		return []string{}
+	}
+
+	return sortedKeys(l.modules)
+}
+
+// LoadModule implements the gojq module loader for import and include directives
+func (l *Library) LoadModule(name string) (*gojq.Query, error) {
+	source, ok := l.modules[name]
+	if !ok {
+		return nil, fmt.Errorf("module not found: %q", name)
+	}
+
+	return gojq.Parse(source)
+}
+
+// ID identifies the library; nil libraries have ID 0
+func (l *Library) ID() uint64 {
+	if l == nil {
+		return 0
+	}
+
+	return l.id
+}
+
+// base returns a copy of the shared templates a destination template is added to
+func (l *Library) base() (*template.Template, error) {
+	if l == nil || l.templates == nil {
+		return template.New("").Funcs(GetTemplateFuncs()).Option("missingkey=default"), nil
+	}
+
+	return l.templates.Clone()
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,115 @@
+package transform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+)
+
+func TestLibrary_GoTemplatePartials(t *testing.T) {
+	lib, err := NewLibrary(map[string]string{
+		"title":  `[{{ .Status | upper }}] {{ .GroupLabels.alertname }}`,
+		"common": `{{ define "footer" }}via {{ .Receiver }}{{ end }}`,
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"common", "footer", "title"}, lib.Templates())
+
+	engine, err := NewGoTemplateEngineWithLibrary(`{"text": "{{ template "title" . }} {{ template "footer" . }}"}`, lib)
+	require.NoError(t, err)
+
+	payload := &alertmanager.WebhookPayload{
+		Status:      "firing",
+		Receiver:    "team",
+		GroupLabels: map[string]string{"alertname": "HighCPU"},
+	}
+
+	result, err := engine.Transform(payload)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"text": "[FIRING] HighCPU via team"}, result)
+
+	// Engines built without the library do not see the shared templates
+	plain, err := NewGoTemplateEngine(`{{ template "title" . }}`)
+	require.NoError(t, err)
+	_, err = plain.Transform(payload)
+	assert.Error(t, err)
+}
+
+func TestLibrary_JQModules(t *testing.T) {
+	lib, err := NewLibrary(nil, map[string]string{
+		"slack": `def color: if .status == "firing" then "danger" else "good" end;`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"slack"}, lib.Modules())
+
+	payload := &alertmanager.WebhookPayload{Status: "firing"}
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "import", query: `import "slack" as s; {color: s::color}`},
+		{name: "include", query: `include "slack"; {color: color}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, err := NewJQEngineWithLibrary(tt.query, lib)
+			require.NoError(t, err)
+
+			result, err := engine.Transform(payload)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]interface{}{"color": "danger"}, result)
+		})
+	}
+
+	t.Run("unknown module", func(t *testing.T) {
+		engine, err := NewJQEngineWithLibrary(`import "missing" as m; m::f`, lib)
+		if err == nil {
+			_, err = engine.Transform(payload)
+		}
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadLibrary(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "title.tmpl"), []byte(`{{ .Status }}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "utils.jq"), []byte(`def twice: . * 2;`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte(`ignored`), 0o600))
+
+	t.Run("merges directory and inline definitions", func(t *testing.T) {
+		lib, err := LoadLibrary(dir, map[string]string{"body": `{{ .Receiver }}`}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"body", "title"}, lib.Templates())
+		assert.Equal(t, []string{"utils"}, lib.Modules())
+	})
+
+	t.Run("duplicate name", func(t *testing.T) {
+		_, err := LoadLibrary(dir, map[string]string{"title": `{{ .Status }}`}, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "title is defined both inline and in title.tmpl")
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		_, err := LoadLibrary(filepath.Join(dir, "missing"), nil, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid sources", func(t *testing.T) {
+		_, err := NewLibrary(map[string]string{"broken": `{{ .Status `}, nil)
+		assert.Error(t, err)
+
+		_, err = NewLibrary(nil, map[string]string{"broken": `def f: ;`})
+		assert.Error(t, err)
+	})
+}
+
+func TestLibrary_Nil(t *testing.T) {
+	var lib *Library
+	assert.Empty(t, lib.Templates())
+	assert.Empty(t, lib.Modules())
+}
@@ -0,0 +1,62 @@
+package transform
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RenderStats summarises the executions of an engine
+type RenderStats struct {
+	Renders         int64
+	AverageDuration time.Duration
+}
+
+// RenderStatsProvider is implemented by engines that record their executions
+type RenderStatsProvider interface {
+	RenderStats() RenderStats
+}
+
+// renderCounter records execution counts and durations without locking
+type renderCounter struct {
+	renders atomic.Int64
+	total   atomic.Int64 // nanoseconds
+}
+
+// record adds an execution that started at start
+func (c *renderCounter) record(start time.Time) {
+	c.renders.Add(1)
+	c.total.Add(int64(time.Since(start)))
+}
+
+// stats returns the recorded executions
+func (c *renderCounter) stats() RenderStats {
+	stats := RenderStats{Renders: c.renders.Load()}
+	if stats.Renders > 0 {
+		stats.AverageDuration = time.Duration(c.total.Load() / stats.Renders)
+	}
+
+	return stats
+}
+
+// bufferPool reuses template output buffers
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	// Don't keep buffers grown by unusually large outputs
+	if buf.Cap() > 64*1024 {
+		return
+	}
+	bufferPool.Put(buf)
+}
@@ -0,0 +1,61 @@
+package transform
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/itchyny/gojq"
+)
+
+// templateLinePattern matches the line of a Go template parse error
+var templateLinePattern = regexp.MustCompile(`template: ` + templateName + `:(\d+):`)
+
+// celLinePattern matches the line of a CEL compile error
+var celLinePattern = regexp.MustCompile(`<input>:(\d+):\d+:`)
+
+// starlarkLinePattern matches the line of a Starlark error
+var starlarkLinePattern = regexp.MustCompile(regexp.QuoteMeta(starlarkFile) + `:(\d+):`)
+
+// SourceError is a template, jq, CEL or Starlark error located in the file the source was read from
+type SourceError struct {
+	File string
+	Line int // 0 when the error has no position
+	Err  error
+}
+
+// NewSourceError locates an error returned for source, read from file
+func NewSourceError(file, source string, err error) *SourceError {
+	return &SourceError{File: file, Line: errorLine(source, err), Err: err}
+}
+
+func (e *SourceError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
+	}
+	return fmt.Sprintf("%s: %v", e.File, e.Err)
+}
+
+func (e *SourceError) Unwrap() error {
+	return e.Err
+}
+
+// errorLine returns the line of source an error refers to, or 0 when unknown
+func errorLine(source string, err error) int {
+	var parseErr *gojq.ParseError
+	if errors.As(err, &parseErr) {
+		offset := min(max(parseErr.Offset-len(parseErr.Token), 0), len(source))
+		return strings.Count(source[:offset], "\n") + 1
+	}
+
+	for _, pattern := range []*regexp.Regexp{templateLinePattern, celLinePattern, starlarkLinePattern} {
+		if match := pattern.FindStringSubmatch(err.Error()); match != nil {
+			line, _ := strconv.Atoi(match[1])
+			return line
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,80 @@
+package transform
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSourceError(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		build    func(source string) error
+		source   string
+		expected string
+	}{
+		{
+			name: "go template",
+			file: "chat.tmpl",
+			build: func(source string) error {
+				_, err := NewGoTemplateEngine(source)
+				return err
+			},
+			source:   "{\n  \"status\": \"{{ .Status }}\",\n  \"text\": \"{{ .Missing \"\n}",
+			expected: "chat.tmpl:3: ",
+		},
+		{
+			name: "jq",
+			file: "pager.jq",
+			build: func(source string) error {
+				_, err := NewJQEngine(source)
+				return err
+			},
+			source:   "{\n  status: .status,\n  text: )\n}",
+			expected: "pager.jq:3: ",
+		},
+		{
+			name: "cel",
+			file: "pager.cel",
+			build: func(source string) error {
+				_, err := NewCELEngine(source)
+				return err
+			},
+			source:   "{\n  \"status\": payload.status,\n  \"name\": alert.lables.alertname\n}",
+			expected: "pager.cel:3: ",
+		},
+		{
+			name: "starlark",
+			file: "pager.star",
+			build: func(source string) error {
+				_, err := NewStarlarkEngine(source)
+				return err
+			},
+			source:   "def transform(payload, alert):\n    team = alert[\"labels\"][\"team\"]\n    return {\"routing_key\": routes[team]}\n",
+			expected: "pager.star:3: ",
+		},
+		{
+			name: "without position",
+			file: "other.jq",
+			build: func(string) error {
+				return errors.New("compile failed")
+			},
+			source:   "{}",
+			expected: "other.jq: compile failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.build(tt.source)
+			require.Error(t, err)
+
+			sourceErr := NewSourceError(tt.file, tt.source, err)
+			assert.Contains(t, sourceErr.Error(), tt.expected)
+			assert.ErrorIs(t, sourceErr, err)
+		})
+	}
+}
@@ -0,0 +1,466 @@
+package transform
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+// StarlarkMaxSteps bounds the work of a single Starlark call, in addition to its timeout
+const StarlarkMaxSteps = 1_000_000
+
+// starlarkFile is the name Starlark errors refer to the script by
+const starlarkFile = "transform.star"
+
+// starlarkFunction is the function a script defines to transform alerts
+const starlarkFunction = "transform"
+
+// starlarkOptions allows while loops and top-level statements; both are bounded by the step budget
+var starlarkOptions = &syntax.FileOptions{
+	Set:             true,
+	While:           true,
+	TopLevelControl: true,
+}
+
+// starlarkHelpers are the template functions available to scripts
+var starlarkHelpers = []string{
+	"severity", "alertname", "fingerprint",
+	"now", "timeformat", "unixtime",
+	"title", "regex", "regexreplace",
+	"urlquery", "base64", "base64dec", "md5",
+}
+
+// StarlarkEngine implements the Starlark transformation engine. Scripts run without
+// access to files, the network or load statements.
+type StarlarkEngine struct {
+	script   string
+	function starlark.Callable
+	timeout  time.Duration
+	renders  renderCounter
+	payloads starlarkPayloadCache
+	mu       sync.RWMutex
+}
+
+// NewStarlarkEngine creates a Starlark engine. The script must define
+// transform(payload, alert), whose result is sent to the destination.
+func NewStarlarkEngine(script string) (*StarlarkEngine, error) {
+	if script == "" {
+		return nil, fmt.Errorf("starlark script cannot be empty")
+	}
+
+	thread := newStarlarkThread()
+	timer := time.AfterFunc(DefaultTimeout, func() { thread.Cancel("timed out") })
+	defer timer.Stop()
+
+	// Globals are frozen once the script has run, so calls can share them
+	globals, err := starlark.ExecFileOptions(starlarkOptions, thread, starlarkFile, script, starlarkPredeclared())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load starlark script: %w", starlarkError(err))
+	}
+
+	function, ok := globals[starlarkFunction].(starlark.Callable)
+	if !ok {
+		return nil, fmt.Errorf("starlark script must define %s(payload, alert)", starlarkFunction)
+	}
+
+	return &StarlarkEngine{
+		script:   script,
+		function: function,
+		timeout:  DefaultTimeout,
+	}, nil
+}
+
+// SetTimeout sets the script execution timeout; zero restores DefaultTimeout
+func (s *StarlarkEngine) SetTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.timeout = timeout
+}
+
+// Transform calls the script for the webhook payload; alert is None
+func (s *StarlarkEngine) Transform(payload *alertmanager.WebhookPayload) (interface{}, error) {
+	defer s.renders.record(time.Now())
+
+	result, err := s.call(payload, starlark.None)
+	if err != nil {
+		return nil, fmt.Errorf("starlark transformation failed: %w", err)
+	}
+
+	return result, nil
+}
+
+// TransformAlert calls the script for a single alert (for split mode). The converted
+// payload is reused for the other alerts of the same payload.
+func (s *StarlarkEngine) TransformAlert(alert *alertmanager.Alert, payload *alertmanager.WebhookPayload) (interface{}, error) {
+	defer s.renders.record(time.Now())
+
+	alertValue, err := toStarlark(alertToValue(alert))
+	if err != nil {
+		return nil, fmt.Errorf("starlark alert transformation failed: %w", err)
+	}
+	alertValue.Freeze()
+
+	result, err := s.call(payload, alertValue)
+	if err != nil {
+		return nil, fmt.Errorf("starlark alert transformation failed: %w", err)
+	}
+
+	return result, nil
+}
+
+// call runs the transform function within the step and time budget
+func (s *StarlarkEngine) call(payload *alertmanager.WebhookPayload, alert starlark.Value) (interface{}, error) {
+	payloadValue, err := s.payloads.value(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	timeout := s.timeout
+	s.mu.RUnlock()
+
+	thread := newStarlarkThread()
+	timer := time.AfterFunc(timeout, func() { thread.Cancel("timed out") })
+	defer timer.Stop()
+
+	result, err := starlark.Call(thread, s.function, starlark.Tuple{payloadValue, alert}, nil)
+	if err != nil {
+		return nil, starlarkError(err)
+	}
+
+	return fromStarlark(result)
+}
+
+// Validate calls the script with a sample payload
+func (s *StarlarkEngine) Validate() error {
+	payload := &alertmanager.WebhookPayload{
+		Version:  "4",
+		GroupKey: "test",
+		Status:   "firing",
+		Receiver: "test",
+		GroupLabels: map[string]string{
+			"alertname": "TestAlert",
+		},
+		CommonLabels: map[string]string{
+			"alertname": "TestAlert",
+			"severity":  "warning",
+		},
+		CommonAnnotations: map[string]string{
+			"summary": "Test alert",
+		},
+		ExternalURL: "http://alertmanager.example.com",
+		Alerts: []alertmanager.Alert{
+			{
+				Status:      "firing",
+				Fingerprint: "test123",
+				Labels: map[string]string{
+					"alertname": "TestAlert",
+					"severity":  "warning",
+				},
+				Annotations: map[string]string{
+					"summary": "Test alert",
+				},
+				StartsAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	_, err := s.TransformAlert(&payload.Alerts[0], payload)
+	return err
+}
+
+// Name returns the engine name
+func (s *StarlarkEngine) Name() string {
+	return "starlark"
+}
+
+// RenderStats returns the number and average duration of transformations
+func (s *StarlarkEngine) RenderStats() RenderStats {
+	return s.renders.stats()
+}
+
+// GetScript returns the Starlark script
+func (s *StarlarkEngine) GetScript() string {
+	return s.script
+}
+
+// newStarlarkThread creates a thread without load support, discarding print output
+func newStarlarkThread() *starlark.Thread {
+	thread := &starlark.Thread{
+		Name:  starlarkFile,
+		Print: func(*starlark.Thread, string) {},
+	}
+	thread.SetMaxExecutionSteps(StarlarkMaxSteps)
+
+	return thread
+}
+
+// starlarkError adds the script position to runtime errors
+func starlarkError(err error) error {
+	var evalErr *starlark.EvalError
+	if !errors.As(err, &evalErr) {
+		return err
+	}
+
+	for i := range evalErr.CallStack {
+		if frame := evalErr.CallStack.At(i); frame.Pos.Filename() == starlarkFile {
+			return fmt.Errorf("%s: %s", frame.Pos, evalErr.Msg)
+		}
+	}
+
+	return err
+}
+
+// starlarkPredeclared returns the helpers scripts can call
+func starlarkPredeclared() starlark.StringDict {
+	funcs := GetTemplateFuncs()
+	predeclared := make(starlark.StringDict, len(starlarkHelpers))
+	for _, name := range starlarkHelpers {
+		predeclared[name] = starlarkBuiltin(name, funcs[name])
+	}
+
+	return predeclared
+}
+
+// starlarkBuiltin wraps a template function as a Starlark builtin
+func starlarkBuiltin(name string, fn interface{}) *starlark.Builtin {
+	fnValue := reflect.ValueOf(fn)
+	fnType := fnValue.Type()
+
+	return starlark.NewBuiltin(name, func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if len(kwargs) > 0 {
+			return nil, fmt.Errorf("%s: unexpected keyword arguments", b.Name())
+		}
+		if len(args) != fnType.NumIn() {
+			return nil, fmt.Errorf("%s: got %d arguments, want %d", b.Name(), len(args), fnType.NumIn())
+		}
+
+		in := make([]reflect.Value, len(args))
+		for i, arg := range args {
+			value, err := helperArgument(arg, fnType.In(i))
+			if err != nil {
+				return nil, fmt.Errorf("%s: argument %d: %w", b.Name(), i+1, err)
+			}
+			in[i] = value
+		}
+
+		out := fnValue.Call(in)
+		if len(out) == 2 && !out[1].IsNil() {
+			return nil, fmt.Errorf("%s: %w", b.Name(), out[1].Interface().(error))
+		}
+
+		return toStarlark(out[0].Interface())
+	})
+}
+
+// helperArgument converts a Starlark value to a template function parameter.
+// Template functions take labels as map[string]string, so dicts of strings are
+// passed in that form.
+func helperArgument(arg starlark.Value, paramType reflect.Type) (reflect.Value, error) {
+	value, err := fromStarlark(arg)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	value = stringMaps(value)
+
+	if value == nil {
+		return reflect.Zero(paramType), nil
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Type().AssignableTo(paramType) {
+		return v, nil
+	}
+	if v.Kind() == reflect.Int64 && paramType.Kind() == reflect.Int {
+		return v.Convert(paramType), nil
+	}
+
+	return reflect.Value{}, fmt.Errorf("got %s, want %s", arg.Type(), paramType)
+}
+
+// stringMaps converts maps whose values are all strings to map[string]string
+func stringMaps(value interface{}) interface{} {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+
+	strs := make(map[string]string, len(m))
+	for k, v := range m {
+		s, ok := v.(string)
+		if !ok {
+			strs = nil
+			break
+		}
+		strs[k] = s
+	}
+	if strs != nil {
+		return strs
+	}
+
+	result := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		result[k] = stringMaps(v)
+	}
+
+	return result
+}
+
+// toStarlark converts a JSON-compatible Go value to a Starlark value
+func toStarlark(value interface{}) (starlark.Value, error) {
+	switch v := value.(type) {
+	case nil:
+		return starlark.None, nil
+	case starlark.Value:
+		return v, nil
+	case bool:
+		return starlark.Bool(v), nil
+	case string:
+		return starlark.String(v), nil
+	case int:
+		return starlark.MakeInt(v), nil
+	case int64:
+		return starlark.MakeInt64(v), nil
+	case float64:
+		return starlark.Float(v), nil
+	case time.Time:
+		return starlark.String(v.Format(time.RFC3339Nano)), nil
+	case map[string]string:
+		// Sorted keys keep the iteration order of dicts stable
+		dict := starlark.NewDict(len(v))
+		for _, k := range slices.Sorted(maps.Keys(v)) {
+			if err := dict.SetKey(starlark.String(k), starlark.String(v[k])); err != nil {
+				return nil, err
+			}
+		}
+		return dict, nil
+	case map[string]interface{}:
+		dict := starlark.NewDict(len(v))
+		for _, k := range slices.Sorted(maps.Keys(v)) {
+			converted, err := toStarlark(v[k])
+			if err != nil {
+				return nil, err
+			}
+			if err := dict.SetKey(starlark.String(k), converted); err != nil {
+				return nil, err
+			}
+		}
+		return dict, nil
+	case []string:
+		items := make([]starlark.Value, len(v))
+		for i, item := range v {
+			items[i] = starlark.String(item)
+		}
+		return starlark.NewList(items), nil
+	case []interface{}:
+		items := make([]starlark.Value, len(v))
+		for i, item := range v {
+			converted, err := toStarlark(item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = converted
+		}
+		return starlark.NewList(items), nil
+	}
+
+	return nil, fmt.Errorf("unsupported value type %T", value)
+}
+
+// fromStarlark converts a Starlark value to the values produced by decoding JSON,
+// keeping integers as int64
+func fromStarlark(value starlark.Value) (interface{}, error) {
+	switch v := value.(type) {
+	case starlark.NoneType:
+		return nil, nil
+	case starlark.Bool:
+		return bool(v), nil
+	case starlark.String:
+		return string(v), nil
+	case starlark.Int:
+		if i, ok := v.Int64(); ok {
+			return i, nil
+		}
+		return float64(v.Float()), nil
+	case starlark.Float:
+		return float64(v), nil
+	case *starlark.Dict:
+		result := make(map[string]interface{}, v.Len())
+		for _, item := range v.Items() {
+			key, ok := item[0].(starlark.String)
+			if !ok {
+				return nil, fmt.Errorf("dict keys must be strings, got %s", item[0].Type())
+			}
+			converted, err := fromStarlark(item[1])
+			if err != nil {
+				return nil, err
+			}
+			result[string(key)] = converted
+		}
+		return result, nil
+	case starlark.Indexable:
+		// Lists and tuples
+		result := make([]interface{}, v.Len())
+		for i := range result {
+			converted, err := fromStarlark(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			result[i] = converted
+		}
+		return result, nil
+	}
+
+	return nil, fmt.Errorf("unsupported starlark result type %s", value.Type())
+}
+
+// starlarkPayload is a frozen Starlark value of a payload
+type starlarkPayload struct {
+	payload *alertmanager.WebhookPayload
+	alerts  *alertmanager.Alert
+	count   int
+	value   starlark.Value
+}
+
+// starlarkPayloadCache keeps the value of the most recent payload, so split alerts
+// convert their payload once
+type starlarkPayloadCache struct {
+	current atomic.Pointer[starlarkPayload]
+}
+
+// value returns the frozen value of payload
+func (c *starlarkPayloadCache) value(payload *alertmanager.WebhookPayload) (starlark.Value, error) {
+	if current := c.current.Load(); current != nil && current.payload == payload &&
+		current.count == len(payload.Alerts) && current.alerts == firstAlert(payload) {
+		return current.value, nil
+	}
+
+	value, err := toStarlark(payloadToValue(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert payload: %w", err)
+	}
+	value.Freeze()
+
+	c.current.Store(&starlarkPayload{
+		payload: payload,
+		alerts:  firstAlert(payload),
+		count:   len(payload.Alerts),
+		value:   value,
+	})
+
+	return value, nil
+}
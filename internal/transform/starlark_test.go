@@ -0,0 +1,286 @@
+package transform
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+)
+
+func TestNewStarlarkEngine(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name:   "valid script",
+			script: "def transform(payload, alert):\n    return {\"status\": payload[\"status\"]}\n",
+		},
+		{
+			name:    "empty script",
+			script:  "",
+			wantErr: true,
+			errMsg:  "starlark script cannot be empty",
+		},
+		{
+			name:    "syntax error",
+			script:  "def transform(payload, alert)\n    return {}\n",
+			wantErr: true,
+			errMsg:  "transform.star:2:1: got newline, want ':'",
+		},
+		{
+			name:    "undefined name",
+			script:  "def transform(payload, alert):\n    return lookup[payload[\"status\"]]\n",
+			wantErr: true,
+			errMsg:  "transform.star:2:12: undefined: lookup",
+		},
+		{
+			name:    "missing transform function",
+			script:  "def convert(payload, alert):\n    return {}\n",
+			wantErr: true,
+			errMsg:  "starlark script must define transform(payload, alert)",
+		},
+		{
+			name:    "load is not available",
+			script:  "load(\"helpers.star\", \"format\")\ndef transform(payload, alert):\n    return {}\n",
+			wantErr: true,
+			errMsg:  "load not implemented",
+		},
+		{
+			name:    "top-level error",
+			script:  "x = 1 // 0\ndef transform(payload, alert):\n    return x\n",
+			wantErr: true,
+			errMsg:  "transform.star:1:7: floored division by zero",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, err := NewStarlarkEngine(tt.script)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+				assert.Nil(t, engine)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, engine)
+				assert.Equal(t, "starlark", engine.Name())
+				assert.Equal(t, tt.script, engine.GetScript())
+			}
+		})
+	}
+}
+
+func TestStarlarkEngine_Transform(t *testing.T) {
+	payload := &alertmanager.WebhookPayload{
+		Status:   "firing",
+		Receiver: "pager",
+		Alerts: []alertmanager.Alert{
+			{
+				Status:      "firing",
+				Fingerprint: "abc",
+				Labels:      map[string]string{"alertname": "HighCPU", "severity": "critical", "team": "db"},
+				StartsAt:    time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC),
+			},
+			{
+				Status:      "firing",
+				Fingerprint: "def",
+				Labels:      map[string]string{"alertname": "HighMemory", "severity": "warning", "team": "web"},
+				StartsAt:    time.Date(2024, 5, 1, 12, 45, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		script   string
+		expected interface{}
+	}{
+		{
+			name: "lookup table and control flow",
+			script: `
+ROUTING = {"db": "dba-oncall", "web": "frontend"}
+
+def transform(payload, alert):
+    requests = []
+    for a in payload["alerts"]:
+        if a["labels"]["severity"] == "info":
+            continue
+        requests.append({
+            "routing_key": ROUTING.get(a["labels"]["team"], "default"),
+            "summary": a["labels"]["alertname"],
+        })
+    return requests
+`,
+			expected: []interface{}{
+				map[string]interface{}{"routing_key": "dba-oncall", "summary": "HighCPU"},
+				map[string]interface{}{"routing_key": "frontend", "summary": "HighMemory"},
+			},
+		},
+		{
+			name: "grouped mode has no alert",
+			script: `
+def transform(payload, alert):
+    return {"alert": alert, "count": len(payload["alerts"]), "ok": True, "ratio": 0.5}
+`,
+			expected: map[string]interface{}{"alert": nil, "count": int64(2), "ok": true, "ratio": 0.5},
+		},
+		{
+			name: "template helpers",
+			script: `
+def transform(payload, alert):
+    first = payload["alerts"][0]
+    return {
+        "severity": severity(first),
+        "alertname": alertname(first["labels"]),
+        "started": timeformat("15:04", first["startsAt"]),
+        "unix": unixtime(first["startsAt"]),
+        "title": title("high cpu"),
+    }
+`,
+			expected: map[string]interface{}{
+				"severity":  "critical",
+				"alertname": "HighCPU",
+				"started":   "12:30",
+				"unix":      int64(1714566600),
+				"title":     "High Cpu",
+			},
+		},
+		{
+			name: "helper functions",
+			script: `
+def label(alert, name):
+    return alert["labels"].get(name, "none")
+
+def transform(payload, alert):
+    return [label(a, "region") for a in payload["alerts"]]
+`,
+			expected: []interface{}{"none", "none"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, err := NewStarlarkEngine(tt.script)
+			require.NoError(t, err)
+
+			result, err := engine.Transform(payload)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestStarlarkEngine_TransformAlert(t *testing.T) {
+	engine, err := NewStarlarkEngine(`
+def transform(payload, alert):
+    return {"name": alert["labels"]["alertname"], "receiver": payload["receiver"]}
+`)
+	require.NoError(t, err)
+
+	payload := &alertmanager.WebhookPayload{
+		Receiver: "pager",
+		Alerts: []alertmanager.Alert{
+			{Labels: map[string]string{"alertname": "First"}},
+			{Labels: map[string]string{"alertname": "Second"}},
+		},
+	}
+
+	var wg sync.WaitGroup
+	for i := range payload.Alerts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			result, err := engine.TransformAlert(&payload.Alerts[i], payload)
+			assert.NoError(t, err)
+			assert.Equal(t, map[string]interface{}{
+				"name":     payload.Alerts[i].Labels["alertname"],
+				"receiver": "pager",
+			}, result)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(2), engine.RenderStats().Renders)
+}
+
+func TestStarlarkEngine_Budget(t *testing.T) {
+	t.Run("step limit", func(t *testing.T) {
+		engine, err := NewStarlarkEngine(`
+def transform(payload, alert):
+    n = 0
+    while True:
+        n += 1
+`)
+		require.NoError(t, err)
+
+		_, err = engine.Transform(&alertmanager.WebhookPayload{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "too many steps")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		engine, err := NewStarlarkEngine(`
+def transform(payload, alert):
+    return [severity(payload) for _ in range(20000)]
+`)
+		require.NoError(t, err)
+		engine.SetTimeout(time.Millisecond)
+
+		_, err = engine.Transform(&alertmanager.WebhookPayload{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "timed out")
+	})
+}
+
+func TestStarlarkEngine_Sandbox(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		errMsg string
+	}{
+		{
+			name:   "payload is read-only",
+			script: "def transform(payload, alert):\n    payload[\"status\"] = \"resolved\"\n    return payload\n",
+			errMsg: "transform.star:2:12: cannot insert into frozen hash table",
+		},
+		{
+			name:   "no file access",
+			script: "def transform(payload, alert):\n    return open(\"/etc/passwd\")\n",
+			errMsg: "undefined: open",
+		},
+		{
+			name:   "unsupported result",
+			script: "def transform(payload, alert):\n    return transform\n",
+			errMsg: "unsupported starlark result type function",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, err := NewStarlarkEngine(tt.script)
+			if err == nil {
+				_, err = engine.Transform(&alertmanager.WebhookPayload{Status: "firing"})
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestStarlarkEngine_Validate(t *testing.T) {
+	engine, err := NewStarlarkEngine("def transform(payload, alert):\n    return {\"text\": alert[\"annotations\"][\"summary\"]}\n")
+	require.NoError(t, err)
+	assert.NoError(t, engine.Validate())
+
+	engine, err = NewStarlarkEngine("def transform(payload, alert):\n    return alert[\"labels\"][\"team\"]\n")
+	require.NoError(t, err)
+	assert.Error(t, engine.Validate())
+}
@@ -1,31 +1,48 @@
 package transform
 
 import (
-	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"slices"
 	"strings"
 	"sync"
 	"text/template"
+	"text/template/parse"
+	"time"
 
 	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
 )
 
+// templateName is the name of a destination template within its template set
+const templateName = "transform"
+
 // GoTemplateEngine implements the Engine interface for Go templates
 type GoTemplateEngine struct {
 	templateString string
 	template       *template.Template
+	library        *Library
+	timeout        time.Duration
+	renders        renderCounter
 	mu             sync.RWMutex
 }
 
 // NewGoTemplateEngine creates a new Go template engine
 func NewGoTemplateEngine(templateString string) (*GoTemplateEngine, error) {
+	return NewGoTemplateEngineWithLibrary(templateString, nil)
+}
+
+// NewGoTemplateEngineWithLibrary creates a Go template engine that can use the shared templates of lib
+func NewGoTemplateEngineWithLibrary(templateString string, lib *Library) (*GoTemplateEngine, error) {
 	if templateString == "" {
 		return nil, fmt.Errorf("template cannot be empty")
 	}
 
 	engine := &GoTemplateEngine{
 		templateString: templateString,
+		library:        lib,
+		timeout:        DefaultTimeout,
 	}
 
 	// Validate and compile the template
@@ -36,15 +53,171 @@ func NewGoTemplateEngine(templateString string) (*GoTemplateEngine, error) {
 	return engine, nil
 }
 
+// SetTimeout sets the template execution timeout; zero restores DefaultTimeout
+func (e *GoTemplateEngine) SetTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.timeout = timeout
+}
+
+// maxTemplateOutput limits the output of a single template execution
+const maxTemplateOutput = alertmanager.MaxPayloadSize
+
+// maxRangeIterations limits the iterations of nested ranges over integer literals
+const maxRangeIterations = 100000
+
+var (
+	errTemplateTimeout     = errors.New("template execution timed out")
+	errTemplateOutputLimit = fmt.Errorf("template output exceeds %d bytes", maxTemplateOutput)
+)
+
+// boundedWriter fails a write once the output exceeds its limit or the deadline has passed,
+// which makes the template stop executing
+type boundedWriter struct {
+	w         io.Writer
+	remaining int
+	deadline  time.Time
+}
+
+func (b *boundedWriter) Write(p []byte) (int, error) {
+	if time.Now().After(b.deadline) {
+		return 0, errTemplateTimeout
+	}
+	if len(p) > b.remaining {
+		return 0, errTemplateOutputLimit
+	}
+	b.remaining -= len(p)
+
+	return b.w.Write(p)
+}
+
+// executeTemplate runs a template, failing once its output exceeds maxTemplateOutput or the timeout elapses
+func executeTemplate(tmpl *template.Template, data interface{}, timeout time.Duration) (output string, err error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("template panicked: %v", r)
+		}
+	}()
+
+	writer := &boundedWriter{w: buf, remaining: maxTemplateOutput, deadline: time.Now().Add(timeout)}
+
+	err = tmpl.Execute(writer, data)
+	if errors.Is(err, errTemplateTimeout) || (err == nil && time.Now().After(writer.deadline)) {
+		return "", fmt.Errorf("template execution timed out after %s", timeout)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// checkRangeLimits rejects templates whose nested ranges over integer literals, including those
+// of templates they invoke, iterate more than maxRangeIterations times. Ranges over the payload
+// are bounded by its size.
+func checkRangeLimits(set *template.Template) error {
+	for _, tmpl := range set.Templates() {
+		if tmpl.Tree == nil {
+			continue
+		}
+
+		if err := checkRanges(set, tmpl.Tree.Root, 1, []string{tmpl.Name()}); err != nil {
+			return fmt.Errorf("template %q: %w", tmpl.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// checkRanges walks node, multiplying iterations by every enclosing range over an integer literal.
+// calls holds the templates invoked on the way to node.
+func checkRanges(set *template.Template, node parse.Node, iterations int, calls []string) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkRanges(set, child, iterations, calls); err != nil {
+				return err
+			}
+		}
+	case *parse.RangeNode:
+		inner := iterations
+		if count, ok := rangeLiteral(n.Pipe); ok {
+			if count > int64(maxRangeIterations/iterations) {
+				return fmt.Errorf("line %d: nested ranges iterate more than %d times", n.Line, maxRangeIterations)
+			}
+			inner = iterations * int(max(count, 1))
+		}
+		if err := checkRanges(set, n.List, inner, calls); err != nil {
+			return err
+		}
+		return checkRanges(set, n.ElseList, iterations, calls)
+	case *parse.IfNode:
+		if err := checkRanges(set, n.List, iterations, calls); err != nil {
+			return err
+		}
+		return checkRanges(set, n.ElseList, iterations, calls)
+	case *parse.WithNode:
+		if err := checkRanges(set, n.List, iterations, calls); err != nil {
+			return err
+		}
+		return checkRanges(set, n.ElseList, iterations, calls)
+	case *parse.TemplateNode:
+		// Invoked templates are checked on their own; inside a range their ranges multiply
+		if iterations == 1 {
+			return nil
+		}
+		if slices.Contains(calls, n.Name) {
+			return fmt.Errorf("line %d: template %q recurses inside a range", n.Line, n.Name)
+		}
+		if called := set.Lookup(n.Name); called != nil && called.Tree != nil {
+			return checkRanges(set, called.Tree.Root, iterations, append(calls, n.Name))
+		}
+	}
+
+	return nil
+}
+
+// rangeLiteral returns the iteration count of a range over an integer literal
+func rangeLiteral(pipe *parse.PipeNode) (int64, bool) {
+	if pipe == nil || len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return 0, false
+	}
+
+	number, ok := pipe.Cmds[0].Args[0].(*parse.NumberNode)
+	if !ok || !number.IsInt {
+		return 0, false
+	}
+
+	return number.Int64, true
+}
+
 // compile compiles the template with custom functions
 func (e *GoTemplateEngine) compile() error {
-	tmpl := template.New("transform").Funcs(GetTemplateFuncs()).Option("missingkey=default")
+	base, err := e.library.base()
+	if err != nil {
+		return fmt.Errorf("failed to copy shared templates: %w", err)
+	}
 
-	compiled, err := tmpl.Parse(e.templateString)
+	compiled, err := base.New(templateName).Parse(e.templateString)
 	if err != nil {
 		return fmt.Errorf("failed to parse template: %w", err)
 	}
 
+	if err := checkRangeLimits(compiled); err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+
 	e.mu.Lock()
 	e.template = compiled
 	e.mu.Unlock()
@@ -54,12 +227,35 @@ func (e *GoTemplateEngine) compile() error {
 
 // Transform applies the template to the webhook payload
 func (e *GoTemplateEngine) Transform(payload *alertmanager.WebhookPayload) (interface{}, error) {
+	output, err := e.Render(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeOutput(output), nil
+}
+
+// TransformAlert transforms a single alert with access to the full payload context
+func (e *GoTemplateEngine) TransformAlert(alert *alertmanager.Alert, payload *alertmanager.WebhookPayload) (interface{}, error) {
+	output, err := e.RenderAlert(alert, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeOutput(output), nil
+}
+
+// Render executes the template for the webhook payload and returns its trimmed output
+func (e *GoTemplateEngine) Render(payload *alertmanager.WebhookPayload) (string, error) {
+	defer e.renders.record(time.Now())
+
 	e.mu.RLock()
 	tmpl := e.template
+	timeout := e.timeout
 	e.mu.RUnlock()
 
 	if tmpl == nil {
-		return nil, fmt.Errorf("template not compiled")
+		return "", fmt.Errorf("template not compiled")
 	}
 
 	// Create context with the payload
@@ -76,33 +272,25 @@ func (e *GoTemplateEngine) Transform(payload *alertmanager.WebhookPayload) (inte
 		Alerts:            payload.Alerts,
 	}
 
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, ctx); err != nil {
-		return nil, fmt.Errorf("failed to execute template: %w", err)
-	}
-
-	result := strings.TrimSpace(buf.String())
-
-	// Try to parse as JSON if it looks like JSON
-	if strings.HasPrefix(result, "{") || strings.HasPrefix(result, "[") {
-		var jsonResult interface{}
-		if err := json.Unmarshal([]byte(result), &jsonResult); err == nil {
-			return jsonResult, nil
-		}
+	output, err := executeTemplate(tmpl, ctx, timeout)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
 	}
 
-	// Return as string if not JSON
-	return result, nil
+	return strings.TrimSpace(output), nil
 }
 
-// TransformAlert transforms a single alert with access to the full payload context
-func (e *GoTemplateEngine) TransformAlert(alert *alertmanager.Alert, payload *alertmanager.WebhookPayload) (interface{}, error) {
+// RenderAlert executes the template for a single alert and returns its trimmed output
+func (e *GoTemplateEngine) RenderAlert(alert *alertmanager.Alert, payload *alertmanager.WebhookPayload) (string, error) {
+	defer e.renders.record(time.Now())
+
 	e.mu.RLock()
 	tmpl := e.template
+	timeout := e.timeout
 	e.mu.RUnlock()
 
 	if tmpl == nil {
-		return nil, fmt.Errorf("template not compiled")
+		return "", fmt.Errorf("template not compiled")
 	}
 
 	// Create context with single alert (for split mode)
@@ -122,22 +310,25 @@ func (e *GoTemplateEngine) TransformAlert(alert *alertmanager.Alert, payload *al
 		},
 	}
 
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, ctx); err != nil {
-		return nil, fmt.Errorf("failed to execute template for alert: %w", err)
+	output, err := executeTemplate(tmpl, ctx, timeout)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute template for alert: %w", err)
 	}
 
-	result := strings.TrimSpace(buf.String())
+	return strings.TrimSpace(output), nil
+}
 
-	// Try to parse as JSON
-	if strings.HasPrefix(result, "{") || strings.HasPrefix(result, "[") {
+// decodeOutput parses template output as JSON if it looks like JSON
+func decodeOutput(output string) interface{} {
+	if strings.HasPrefix(output, "{") || strings.HasPrefix(output, "[") {
 		var jsonResult interface{}
-		if err := json.Unmarshal([]byte(result), &jsonResult); err == nil {
-			return jsonResult, nil
+		if err := json.Unmarshal([]byte(output), &jsonResult); err == nil {
+			return jsonResult
 		}
 	}
 
-	return result, nil
+	// Return as string if not JSON
+	return output
 }
 
 // Validate checks if the template is valid
@@ -151,6 +342,11 @@ func (e *GoTemplateEngine) Name() string {
 	return string(EngineTypeGoTemplate)
 }
 
+// RenderStats returns the number and average duration of template executions
+func (e *GoTemplateEngine) RenderStats() RenderStats {
+	return e.renders.stats()
+}
+
 // TemplateContext provides the context for template execution
 type TemplateContext struct {
 	Version           string               `json:"version"`
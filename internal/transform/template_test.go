@@ -360,3 +360,67 @@ func TestTemplateContext_JSONMarshaling(t *testing.T) {
 	assert.Equal(t, ctx.Status, unmarshaled.Status)
 	assert.Equal(t, ctx.GroupLabels, unmarshaled.GroupLabels)
 }
+
+func TestGoTemplateEngine_Timeout(t *testing.T) {
+	engine, err := NewGoTemplateEngine(`{{ range .Alerts }}{{ .Status }}{{ end }}`)
+	require.NoError(t, err)
+	engine.SetTimeout(time.Nanosecond)
+
+	payload := &alertmanager.WebhookPayload{Status: "firing", Alerts: []alertmanager.Alert{{Status: "firing"}}}
+
+	_, err = engine.Transform(payload)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out")
+
+	_, err = engine.TransformAlert(&payload.Alerts[0], payload)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out")
+}
+
+func TestGoTemplateEngine_RangeLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		errText  string
+	}{
+		{name: "within limit", template: `{{ range 1000 }}{{ range 100 }}{{ end }}{{ end }}`},
+		{name: "single range", template: `{{ range 1000000 }}{{ end }}`, errText: "iterate more than"},
+		{name: "nested ranges", template: `{{ range 1000 }}{{ range 1000 }}{{ end }}{{ end }}`, errText: "iterate more than"},
+		{name: "else branch", template: `{{ range 1000 }}{{ else }}{{ range 1000 }}{{ end }}{{ end }}`},
+		{name: "invoked template", template: `{{ define "spin" }}{{ range 1000 }}{{ end }}{{ end }}{{ range 1000 }}{{ template "spin" }}{{ end }}`, errText: "iterate more than"},
+		{name: "recursive template", template: `{{ define "loop" }}{{ range 10 }}{{ template "loop" }}{{ end }}{{ end }}{{ template "loop" }}`, errText: "recurses inside a range"},
+		{name: "range over data", template: `{{ range .Alerts }}{{ range .Labels }}{{ . }}{{ end }}{{ end }}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewGoTemplateEngine(tt.template)
+			if tt.errText != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errText)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestGoTemplateEngine_OutputLimit(t *testing.T) {
+	payload := &alertmanager.WebhookPayload{Status: "firing"}
+
+	templates := map[string]string{
+		"range output":  `{{ range 100000 }}{{ printf "%1000s" "x" }}{{ end }}`,
+		"repeat output": `{{ repeat "x" 100000000 }}`,
+	}
+
+	for name, source := range templates {
+		t.Run(name, func(t *testing.T) {
+			engine, err := NewGoTemplateEngine(source)
+			require.NoError(t, err)
+
+			_, err = engine.Transform(payload)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "template output exceeds")
+		})
+	}
+}
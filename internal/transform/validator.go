@@ -44,6 +44,10 @@ func (v *TemplateValidator) Validate() (*ValidationResult, error) {
 		return v.validateGoTemplate()
 	case EngineTypeJQ:
 		return v.validateJQTemplate()
+	case EngineTypeCEL:
+		return v.validateCELExpression()
+	case EngineTypeStarlark:
+		return v.validateStarlarkScript()
 	default:
 		result.Valid = false
 		result.Error = fmt.Sprintf("unknown engine type: %s", v.engineType)
@@ -214,6 +218,89 @@ func (v *TemplateValidator) validateJQTemplate() (*ValidationResult, error) {
 	return result, nil
 }
 
+// validateCELExpression validates a CEL expression
+func (v *TemplateValidator) validateCELExpression() (*ValidationResult, error) {
+	result := &ValidationResult{
+		Valid:    true,
+		Warnings: []string{},
+		Info:     []string{},
+	}
+
+	// Compiling type-checks field references against the payload and alert
+	engine, err := NewCELEngine(v.template)
+	if err != nil {
+		result.Valid = false
+		result.Error = fmt.Sprintf("cel compile error: %v", err)
+		return result, nil
+	}
+
+	samplePayload := v.createSamplePayload()
+
+	output, err := engine.Transform(samplePayload)
+	if err != nil {
+		result.Valid = false
+		result.Error = fmt.Sprintf("cel evaluation error: %v", err)
+		return result, nil
+	}
+
+	v.analyzeOutput(output, result)
+
+	if len(samplePayload.Alerts) > 0 {
+		if _, err := engine.TransformAlert(&samplePayload.Alerts[0], samplePayload); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("cel alert transformation failed: %v", err))
+		} else {
+			result.Info = append(result.Info, "cel expression works with both grouped and split alert modes")
+		}
+	}
+
+	if result.OutputType == "null" {
+		result.Warnings = append(result.Warnings, "cel expression returns null")
+	}
+
+	return result, nil
+}
+
+// validateStarlarkScript validates a Starlark script
+func (v *TemplateValidator) validateStarlarkScript() (*ValidationResult, error) {
+	result := &ValidationResult{
+		Valid:    true,
+		Warnings: []string{},
+		Info:     []string{},
+	}
+
+	engine, err := NewStarlarkEngine(v.template)
+	if err != nil {
+		result.Valid = false
+		result.Error = fmt.Sprintf("starlark load error: %v", err)
+		return result, nil
+	}
+
+	samplePayload := v.createSamplePayload()
+
+	output, err := engine.Transform(samplePayload)
+	if err != nil {
+		result.Valid = false
+		result.Error = fmt.Sprintf("starlark execution error: %v", err)
+		return result, nil
+	}
+
+	v.analyzeOutput(output, result)
+
+	if len(samplePayload.Alerts) > 0 {
+		if _, err := engine.TransformAlert(&samplePayload.Alerts[0], samplePayload); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("starlark alert transformation failed: %v", err))
+		} else {
+			result.Info = append(result.Info, "starlark script works with both grouped and split alert modes")
+		}
+	}
+
+	if result.OutputType == "null" {
+		result.Warnings = append(result.Warnings, "starlark script returns None")
+	}
+
+	return result, nil
+}
+
 // analyzeOutput analyzes the template output
 func (v *TemplateValidator) analyzeOutput(output interface{}, result *ValidationResult) {
 	switch out := output.(type) {
@@ -0,0 +1,158 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+	"github.com/vitalvas/alertmanager-gateway/internal/config"
+	"github.com/vitalvas/alertmanager-gateway/internal/requestid"
+	"github.com/vitalvas/alertmanager-gateway/internal/tracing"
+)
+
+// AlertsAPIResponse represents the response for an Alertmanager API v2 alerts request
+type AlertsAPIResponse struct {
+	Status       string    `json:"status"`
+	Destinations []string  `json:"destinations"`
+	ReceivedAt   time.Time `json:"received_at"`
+	AlertsCount  int       `json:"alerts_count"`
+	GroupsCount  int       `json:"groups_count"`
+	ProcessingMS int64     `json:"processing_ms"`
+	RequestID    string    `json:"request_id,omitempty"`
+	DeliveryIDs  []string  `json:"delivery_ids,omitempty"`
+	Errors       []string  `json:"errors,omitempty"`
+}
+
+// HandleAlertsAPI processes Alertmanager API v2 compatible POST /api/v2/alerts requests.
+// Alerts are grouped into webhook payloads and sent to the destination in the URL path,
+// or to every destination listed in the alerts_api configuration.
+func (h *Handler) HandleAlertsAPI(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	apiConfig := h.alertsAPIConfig()
+	destinations := apiConfig.Destinations
+	if destName, ok := mux.Vars(r)["destination"]; ok {
+		destinations = []string{destName}
+	}
+
+	r = ensureRequestID(w, r)
+	h.received.Add(1)
+
+	logger := h.logger.WithContext(r.Context()).WithFields(logrus.Fields{
+		"destinations": destinations,
+		"remote_addr":  r.RemoteAddr,
+	})
+
+	if h.Draining() {
+		logger.Warn("Rejected alerts during shutdown")
+		h.rejectDraining(w)
+		return
+	}
+
+	if len(destinations) == 0 {
+		logger.Warn("No destinations configured for alerts API")
+		h.sendErrorResponse(w, http.StatusNotFound, "No destinations configured for alerts API")
+		return
+	}
+
+	for _, destName := range destinations {
+		if h.enabledDestination(destName) == nil {
+			logger.WithField("destination", destName).Warn("Destination not found")
+			h.sendErrorResponse(w, http.StatusNotFound, "Destination not found")
+			return
+		}
+	}
+
+	_, parseSpan := tracing.Start(r.Context(), "alerts_api.parse")
+	postable, err := alertmanager.ParsePostableAlerts(r)
+	tracing.End(parseSpan, err)
+	if err != nil {
+		logger.WithError(err).Error("Failed to parse alerts")
+
+		statusCode := http.StatusBadRequest
+		if errors.Is(err, alertmanager.ErrPayloadTooLarge) {
+			statusCode = http.StatusRequestEntityTooLarge
+		}
+
+		h.sendErrorResponse(w, statusCode, fmt.Sprintf("Invalid payload: %v", err))
+		return
+	}
+
+	now := time.Now().UTC()
+	alerts := make([]alertmanager.Alert, 0, len(postable))
+	for i := range postable {
+		alerts = append(alerts, postable[i].ToAlert(now))
+	}
+
+	payloads := alertmanager.GroupAlerts(alerts, apiConfig.GroupBy, apiConfig.Receiver, apiConfig.ExternalURL)
+
+	logger.WithFields(logrus.Fields{
+		"alerts_count": len(alerts),
+		"groups_count": len(payloads),
+	}).Info("Received alerts API request")
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.webhookTimeout())
+	defer cancel()
+
+	response := AlertsAPIResponse{
+		Status:       "success",
+		Destinations: destinations,
+		ReceivedAt:   now,
+		AlertsCount:  len(alerts),
+		GroupsCount:  len(payloads),
+		RequestID:    requestid.FromContext(r.Context()),
+	}
+
+	paused := false
+
+	for _, destName := range destinations {
+		for _, payload := range payloads {
+			result, err := h.deliver(ctx, destName, payload)
+			if err != nil {
+				if errors.Is(err, ErrDestinationPaused) {
+					paused = true
+				}
+
+				logger.WithError(err).WithFields(logrus.Fields{
+					"destination": destName,
+					"group_key":   payload.GroupKey,
+				}).Error("Failed to send alerts to destination")
+				response.Errors = append(response.Errors, fmt.Sprintf("%s: %s: %v", destName, payload.GroupKey, err))
+				continue
+			}
+
+			if result.DeliveryID != "" {
+				response.DeliveryIDs = append(response.DeliveryIDs, result.DeliveryID)
+			}
+		}
+	}
+
+	response.ProcessingMS = time.Since(start).Milliseconds()
+
+	if len(response.Errors) > 0 {
+		response.Status = "error"
+
+		// A paused destination asks the sender to retry later
+		statusCode := http.StatusInternalServerError
+		if paused {
+			statusCode = http.StatusServiceUnavailable
+		}
+		h.sendJSONResponse(w, statusCode, response)
+		return
+	}
+
+	h.sendJSONResponse(w, http.StatusOK, response)
+}
+
+// alertsAPIConfig returns the alerts API configuration
+func (h *Handler) alertsAPIConfig() config.AlertsAPIConfig {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.config.AlertsAPI
+}
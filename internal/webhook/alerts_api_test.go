@@ -0,0 +1,160 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+	"github.com/vitalvas/alertmanager-gateway/internal/config"
+	"github.com/vitalvas/alertmanager-gateway/internal/destination"
+)
+
+func TestHandler_HandleAlertsAPI(t *testing.T) {
+	cfg := &config.Config{
+		AlertsAPI: config.AlertsAPIConfig{
+			Enabled:      true,
+			Destinations: []string{"dest-a", "dest-b"},
+			GroupBy:      []string{"alertname"},
+			Receiver:     "alerts-api",
+		},
+		Destinations: []config.DestinationConfig{
+			{Name: "dest-a", Enabled: true},
+			{Name: "dest-b", Enabled: true},
+			{Name: "failing", Enabled: true},
+		},
+	}
+
+	var mu sync.Mutex
+	received := make(map[string][]*alertmanager.WebhookPayload)
+	record := func(name string) func(context.Context, *alertmanager.WebhookPayload) error {
+		return func(_ context.Context, payload *alertmanager.WebhookPayload) error {
+			mu.Lock()
+			defer mu.Unlock()
+			received[name] = append(received[name], payload)
+			return nil
+		}
+	}
+
+	handler := &Handler{
+		config: cfg,
+		logger: logrus.New(),
+		handlers: map[string]destination.Handler{
+			"dest-a":  &mockDestinationHandler{name: "dest-a", sendFunc: record("dest-a")},
+			"dest-b":  &mockDestinationHandler{name: "dest-b", sendFunc: record("dest-b")},
+			"failing": &mockDestinationHandler{name: "failing", sendFunc: func(context.Context, *alertmanager.WebhookPayload) error { return errors.New("boom") }},
+		},
+	}
+
+	body := `[
+		{"labels": {"alertname": "A", "instance": "1"}},
+		{"labels": {"alertname": "A", "instance": "2"}},
+		{"labels": {"alertname": "B"}, "endsAt": "2020-01-01T00:00:00Z"}
+	]`
+
+	t.Run("configured destinations", func(t *testing.T) {
+		received = make(map[string][]*alertmanager.WebhookPayload)
+
+		req := httptest.NewRequest("POST", "/api/v2/alerts", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.HandleAlertsAPI(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp AlertsAPIResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "success", resp.Status)
+		assert.Equal(t, 3, resp.AlertsCount)
+		assert.Equal(t, 2, resp.GroupsCount)
+
+		require.Len(t, received["dest-a"], 2)
+		require.Len(t, received["dest-b"], 2)
+		assert.Equal(t, "firing", received["dest-a"][0].Status)
+		assert.Equal(t, "alerts-api", received["dest-a"][0].Receiver)
+		assert.Len(t, received["dest-a"][0].Alerts, 2)
+		assert.Equal(t, "resolved", received["dest-a"][1].Status)
+	})
+
+	t.Run("destination from path", func(t *testing.T) {
+		received = make(map[string][]*alertmanager.WebhookPayload)
+
+		req := httptest.NewRequest("POST", "/webhook/dest-b/api/v2/alerts", strings.NewReader(body))
+		req = mux.SetURLVars(req, map[string]string{"destination": "dest-b"})
+		w := httptest.NewRecorder()
+		handler.HandleAlertsAPI(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, received["dest-a"])
+		assert.Len(t, received["dest-b"], 2)
+	})
+
+	t.Run("unknown destination", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/webhook/missing/api/v2/alerts", strings.NewReader(body))
+		req = mux.SetURLVars(req, map[string]string{"destination": "missing"})
+		w := httptest.NewRecorder()
+		handler.HandleAlertsAPI(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/api/v2/alerts", strings.NewReader(`[{"annotations": {}}]`))
+		w := httptest.NewRecorder()
+		handler.HandleAlertsAPI(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("delivery failure", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/webhook/failing/api/v2/alerts", strings.NewReader(body))
+		req = mux.SetURLVars(req, map[string]string{"destination": "failing"})
+		w := httptest.NewRecorder()
+		handler.HandleAlertsAPI(w, req)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+
+		var resp AlertsAPIResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "error", resp.Status)
+		assert.Len(t, resp.Errors, 2)
+	})
+
+	t.Run("concurrent reload", func(t *testing.T) {
+		stop := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				reloaded := *cfg
+				handler.mu.Lock()
+				handler.config = &reloaded
+				handler.mu.Unlock()
+			}
+		}()
+
+		for i := 0; i < 50; i++ {
+			req := httptest.NewRequest("POST", "/api/v2/alerts", strings.NewReader(body))
+			w := httptest.NewRecorder()
+			handler.HandleAlertsAPI(w, req)
+			assert.Equal(t, http.StatusOK, w.Code)
+		}
+
+		close(stop)
+		<-done
+	})
+}
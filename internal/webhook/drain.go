@@ -0,0 +1,195 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+	"github.com/vitalvas/alertmanager-gateway/internal/deadletter"
+)
+
+// DrainResult summarises the deliveries still running when a drain started
+type DrainResult struct {
+	Completed int // finished before the drain timeout
+	Abandoned int // still running when the drain timeout elapsed
+	Persisted int // abandoned payloads written to the dead-letter store
+}
+
+// inflightDelivery is a delivery that has not finished yet
+type inflightDelivery struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
+	destination string
+	payload     *alertmanager.WebhookPayload
+	persist     bool // write the payload to the dead-letter store when abandoned
+
+	done chan struct{} // closed when the delivery returned
+	err  error         // outcome of the delivery, set before done is closed
+}
+
+// StartDrain makes the handler reject new webhooks while in-flight deliveries finish
+func (h *Handler) StartDrain() {
+	h.drainMu.Lock()
+	defer h.drainMu.Unlock()
+
+	if h.draining {
+		return
+	}
+
+	h.draining = true
+	h.drained = make(chan struct{})
+	if len(h.inflight) == 0 {
+		close(h.drained)
+	}
+}
+
+// Draining reports whether the handler is shutting down
+func (h *Handler) Draining() bool {
+	h.drainMu.Lock()
+	defer h.drainMu.Unlock()
+
+	return h.draining
+}
+
+// Drain waits for in-flight deliveries to finish until the context is done. Deliveries still
+// running then are cancelled, and once they returned, the payloads of those that did not
+// succeed are written to the dead-letter store.
+func (h *Handler) Drain(ctx context.Context) DrainResult {
+	h.StartDrain()
+
+	select {
+	case <-h.drained:
+	case <-ctx.Done():
+	}
+
+	h.drainMu.Lock()
+	result := DrainResult{Completed: h.drainCompleted}
+	running := make([]*inflightDelivery, 0, len(h.inflight))
+	for _, delivery := range h.inflight {
+		running = append(running, delivery)
+	}
+	h.drainMu.Unlock()
+
+	for _, delivery := range running {
+		delivery.cancel()
+	}
+
+	for _, delivery := range running {
+		<-delivery.done
+
+		// The send can still complete after the cancellation
+		if delivery.err == nil {
+			result.Completed++
+			continue
+		}
+
+		result.Abandoned++
+		if !delivery.persist {
+			continue
+		}
+
+		persistCtx := context.WithoutCancel(delivery.ctx)
+		if h.persistUndelivered(persistCtx, delivery.destination, deadletter.ReasonAbandoned, delivery.payload) {
+			result.Persisted++
+		}
+	}
+
+	return result
+}
+
+// rejectDraining responds to webhooks received during shutdown so the sender retries them elsewhere
+func (h *Handler) rejectDraining(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", "5")
+	h.sendErrorResponse(w, http.StatusServiceUnavailable, "Gateway is shutting down")
+}
+
+// trackDelivery registers an in-flight delivery. It returns the context to deliver with, which
+// Drain cancels, and the function recording the outcome of the delivery.
+func (h *Handler) trackDelivery(ctx context.Context, destName string, payload *alertmanager.WebhookPayload, persist bool) (context.Context, func(error)) {
+	ctx, cancel := context.WithCancel(ctx)
+	delivery := &inflightDelivery{
+		ctx:         ctx,
+		cancel:      cancel,
+		destination: destName,
+		payload:     payload,
+		persist:     persist,
+		done:        make(chan struct{}),
+	}
+
+	h.drainMu.Lock()
+	defer h.drainMu.Unlock()
+
+	if h.inflight == nil {
+		h.inflight = make(map[uint64]*inflightDelivery)
+	}
+
+	h.nextDeliveryID++
+	id := h.nextDeliveryID
+	h.inflight[id] = delivery
+
+	return ctx, func(err error) {
+		delivery.err = err
+		close(delivery.done)
+		cancel()
+
+		h.drainMu.Lock()
+		defer h.drainMu.Unlock()
+
+		delete(h.inflight, id)
+		if h.draining {
+			h.drainCompleted++
+			if len(h.inflight) == 0 {
+				select {
+				case <-h.drained:
+				default:
+					close(h.drained)
+				}
+			}
+		}
+	}
+}
+
+// closePending persists payloads held in memory at shutdown, or logs that they are discarded
+func (h *Handler) closePending(destName, reason string, payloads []*alertmanager.WebhookPayload, discardMessage string) {
+	persisted := 0
+	for _, payload := range payloads {
+		if h.persistUndelivered(context.Background(), destName, reason, payload) {
+			persisted++
+		}
+	}
+
+	logger := h.logger.WithFields(logrus.Fields{
+		"destination": destName,
+		"payloads":    len(payloads),
+	})
+
+	if persisted == len(payloads) && h.deadLetter != nil {
+		logger.Info("Persisted undelivered alerts to the dead-letter store")
+		return
+	}
+
+	logger.WithField("persisted", persisted).Warn(discardMessage)
+}
+
+// persistUndelivered writes a payload to the dead-letter store, reporting whether it was stored
+func (h *Handler) persistUndelivered(ctx context.Context, destName, reason string, payload *alertmanager.WebhookPayload) bool {
+	if h.deadLetter == nil {
+		return false
+	}
+
+	err := h.deadLetter.Add(ctx, deadletter.Entry{
+		Destination: destName,
+		Reason:      reason,
+		Payload:     payload,
+	})
+	if err != nil {
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"destination": destName,
+			"reason":      reason,
+		}).Error("Failed to persist undelivered alerts")
+		return false
+	}
+
+	return true
+}
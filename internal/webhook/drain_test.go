@@ -0,0 +1,234 @@
+package webhook
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+	"github.com/vitalvas/alertmanager-gateway/internal/config"
+	"github.com/vitalvas/alertmanager-gateway/internal/deadletter"
+)
+
+// newBlockingHandler returns a handler whose destination blocks until release is closed
+func newBlockingHandler(t *testing.T, release <-chan struct{}) (*Handler, <-chan struct{}) {
+	t.Helper()
+
+	received := 0
+	handler := newPausableHandler(config.PauseModeReject, 0, &received)
+
+	started := make(chan struct{}, 1)
+	handler.handlers["chat"].(*mockDestinationHandler).sendFunc = func(ctx context.Context, _ *alertmanager.WebhookPayload) error {
+		started <- struct{}{}
+		select {
+		case <-release:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return handler, started
+}
+
+func readDeadLetters(t *testing.T, path string) []deadletter.Entry {
+	t.Helper()
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var entries []deadletter.Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry deadletter.Entry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+func TestHandler_DrainCompletes(t *testing.T) {
+	release := make(chan struct{})
+	handler, started := newBlockingHandler(t, release)
+
+	payload := &alertmanager.WebhookPayload{Alerts: []alertmanager.Alert{{Fingerprint: "abc", Status: "firing"}}}
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := handler.deliver(context.Background(), "chat", payload)
+		errCh <- err
+	}()
+	<-started
+
+	handler.StartDrain()
+	assert.True(t, handler.Draining())
+
+	// New webhooks are rejected while draining
+	w := sendPaused(t, handler)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "5", w.Header().Get("Retry-After"))
+
+	close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result := handler.Drain(ctx)
+	assert.Equal(t, DrainResult{Completed: 1}, result)
+	assert.NoError(t, <-errCh)
+}
+
+func TestHandler_DrainAbandons(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead-letter.jsonl")
+	store, err := deadletter.New(path)
+	require.NoError(t, err)
+
+	release := make(chan struct{})
+	defer close(release)
+
+	handler, started := newBlockingHandler(t, release)
+	handler.deadLetter = store
+
+	payload := &alertmanager.WebhookPayload{GroupKey: "g", Alerts: []alertmanager.Alert{{Fingerprint: "abc", Status: "firing"}}}
+	go func() {
+		_, _ = handler.deliver(context.Background(), "chat", payload)
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	result := handler.Drain(ctx)
+	assert.Equal(t, DrainResult{Abandoned: 1, Persisted: 1}, result)
+	require.NoError(t, store.Close())
+
+	entries := readDeadLetters(t, path)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "chat", entries[0].Destination)
+	assert.Equal(t, deadletter.ReasonAbandoned, entries[0].Reason)
+	assert.Equal(t, "g", entries[0].Payload.GroupKey)
+}
+
+func TestHandler_DrainCancelledDeliverySucceeds(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead-letter.jsonl")
+	store, err := deadletter.New(path)
+	require.NoError(t, err)
+
+	received := 0
+	handler := newPausableHandler(config.PauseModeReject, 0, &received)
+	handler.deadLetter = store
+
+	// The send completes although its context was cancelled
+	started := make(chan struct{})
+	handler.handlers["chat"].(*mockDestinationHandler).sendFunc = func(ctx context.Context, _ *alertmanager.WebhookPayload) error {
+		close(started)
+		<-ctx.Done()
+		return nil
+	}
+
+	payload := &alertmanager.WebhookPayload{GroupKey: "g", Alerts: []alertmanager.Alert{{Fingerprint: "abc", Status: "firing"}}}
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := handler.deliver(context.Background(), "chat", payload)
+		errCh <- err
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// Drain cancels the delivery and waits for it, so a sent payload is not replayed later
+	result := handler.Drain(ctx)
+	assert.Equal(t, DrainResult{Completed: 1}, result)
+	assert.NoError(t, <-errCh)
+
+	require.NoError(t, store.Close())
+	assert.Empty(t, readDeadLetters(t, path))
+}
+
+func TestHandler_DrainIdle(t *testing.T) {
+	received := 0
+	handler := newPausableHandler(config.PauseModeReject, 0, &received)
+
+	result := handler.Drain(context.Background())
+	assert.Equal(t, DrainResult{}, result)
+}
+
+func TestHandler_ClosePersistsPending(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead-letter.jsonl")
+	store, err := deadletter.New(path)
+	require.NoError(t, err)
+
+	received := 0
+	handler := newPausableHandler(config.PauseModeBuffer, 10, &received)
+	handler.deadLetter = store
+	handler.queue = newPayloadQueue()
+	handler.queue.push("chat", &alertmanager.WebhookPayload{GroupKey: "queued"})
+
+	_, err = handler.Pause(context.Background(), "chat", 0, "maintenance")
+	require.NoError(t, err)
+	sendPaused(t, handler)
+
+	require.NoError(t, handler.Close())
+
+	entries := readDeadLetters(t, path)
+	require.Len(t, entries, 2)
+
+	reasons := []string{entries[0].Reason, entries[1].Reason}
+	assert.ElementsMatch(t, []string{deadletter.ReasonQueued, deadletter.ReasonPaused}, reasons)
+}
+
+func TestHandler_ClosePersistsDigest(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer upstream.Close()
+
+	path := filepath.Join(t.TempDir(), "dead-letter.jsonl")
+	store, err := deadletter.New(path)
+	require.NoError(t, err)
+
+	cfg := &config.Config{
+		Destinations: []config.DestinationConfig{{
+			Name:     "digest",
+			URL:      upstream.URL,
+			Method:   "POST",
+			Format:   "json",
+			Engine:   "go-template",
+			Template: `{"count": {{ len .Alerts }}}`,
+			Enabled:  true,
+			Digest:   config.DigestConfig{Window: time.Hour},
+		}},
+	}
+
+	handler, err := NewHandler(cfg, logrus.New())
+	require.NoError(t, err)
+	handler.deadLetter = store
+
+	_, err = handler.deliver(context.Background(), "digest", &alertmanager.WebhookPayload{
+		Receiver: "r",
+		Alerts:   []alertmanager.Alert{{Fingerprint: "abc", Status: "firing", Labels: map[string]string{"alertname": "Test"}}},
+	})
+	require.NoError(t, err)
+
+	// The final digest fails, so its alerts are written to the dead-letter store
+	require.NoError(t, handler.Close())
+
+	entries := readDeadLetters(t, path)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "digest", entries[0].Destination)
+	assert.Equal(t, deadletter.ReasonDigest, entries[0].Reason)
+	require.Len(t, entries[0].Payload.Alerts, 1)
+	assert.Equal(t, "abc", entries[0].Payload.Alerts[0].Fingerprint)
+}
@@ -0,0 +1,53 @@
+package webhook
+
+// recordOutcome tracks consecutive delivery failures of a destination
+func (h *Handler) recordOutcome(name string, err error) {
+	h.failMu.Lock()
+	defer h.failMu.Unlock()
+
+	if err == nil {
+		delete(h.failures, name)
+		return
+	}
+
+	if h.failures == nil {
+		h.failures = make(map[string]int)
+	}
+	h.failures[name]++
+}
+
+// ConsecutiveFailures returns the number of deliveries to a destination that failed since the last success
+func (h *Handler) ConsecutiveFailures(name string) int {
+	h.failMu.Lock()
+	defer h.failMu.Unlock()
+
+	return h.failures[name]
+}
+
+// clearFailures discards the failure count of a removed destination
+func (h *Handler) clearFailures(name string) {
+	h.failMu.Lock()
+	defer h.failMu.Unlock()
+
+	delete(h.failures, name)
+}
+
+// QueueBacklog returns the number of payloads held for later delivery, both outside active
+// time intervals and while destinations are paused
+func (h *Handler) QueueBacklog() int {
+	backlog := 0
+	if h.queue != nil {
+		for _, name := range h.queue.destinations() {
+			backlog += h.queue.len(name)
+		}
+	}
+
+	h.pauseMu.Lock()
+	defer h.pauseMu.Unlock()
+
+	for _, state := range h.pauses {
+		backlog += len(state.buffer)
+	}
+
+	return backlog
+}
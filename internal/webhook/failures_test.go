@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+	"github.com/vitalvas/alertmanager-gateway/internal/config"
+)
+
+func TestHandler_ConsecutiveFailures(t *testing.T) {
+	received := 0
+	handler := newPausableHandler(config.PauseModeBuffer, 10, &received)
+
+	failing := true
+	handler.handlers["chat"].(*mockDestinationHandler).sendFunc = func(_ context.Context, _ *alertmanager.WebhookPayload) error {
+		if failing {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+
+	payload := &alertmanager.WebhookPayload{Alerts: []alertmanager.Alert{{Fingerprint: "abc", Status: "firing"}}}
+
+	for i := 0; i < 3; i++ {
+		_, err := handler.deliver(context.Background(), "chat", payload)
+		require.Error(t, err)
+	}
+	assert.Equal(t, 3, handler.ConsecutiveFailures("chat"))
+
+	failing = false
+	_, err := handler.deliver(context.Background(), "chat", payload)
+	require.NoError(t, err)
+	assert.Equal(t, 0, handler.ConsecutiveFailures("chat"))
+}
+
+func TestHandler_QueueBacklog(t *testing.T) {
+	received := 0
+	handler := newPausableHandler(config.PauseModeBuffer, 10, &received)
+	handler.queue = newPayloadQueue()
+
+	assert.Equal(t, 0, handler.QueueBacklog())
+
+	handler.queue.push("chat", &alertmanager.WebhookPayload{})
+	handler.queue.push("other", &alertmanager.WebhookPayload{})
+
+	_, err := handler.Pause(context.Background(), "chat", time.Minute, "maintenance")
+	require.NoError(t, err)
+	defer handler.Close()
+
+	sendPaused(t, handler)
+	sendPaused(t, handler)
+
+	assert.Equal(t, 4, handler.QueueBacklog())
+}
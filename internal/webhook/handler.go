@@ -6,47 +6,285 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
 	"github.com/vitalvas/alertmanager-gateway/internal/config"
+	"github.com/vitalvas/alertmanager-gateway/internal/deadletter"
 	"github.com/vitalvas/alertmanager-gateway/internal/destination"
+	"github.com/vitalvas/alertmanager-gateway/internal/filter"
+	"github.com/vitalvas/alertmanager-gateway/internal/history"
+	"github.com/vitalvas/alertmanager-gateway/internal/requestid"
+	"github.com/vitalvas/alertmanager-gateway/internal/state"
+	"github.com/vitalvas/alertmanager-gateway/internal/timeinterval"
+	"github.com/vitalvas/alertmanager-gateway/internal/tracing"
+	"github.com/vitalvas/alertmanager-gateway/internal/transform"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+var errHandlerNotInitialized = errors.New("destination handler not initialized")
+
+// ErrNotDigestDestination is returned when flushing a destination that does not use digest delivery
+var ErrNotDigestDestination = errors.New("destination does not use digest delivery")
+
 // Handler handles incoming webhook requests
 type Handler struct {
 	config   *config.Config
 	logger   *logrus.Logger
 	handlers map[string]destination.Handler
+	filters  map[string]*filter.Filter
+	state    *state.Store
+	history  *history.Store
+
+	// mu guards handlers, filters, schedules, intervals, library and config.Destinations
+	mu sync.RWMutex
+
+	// Shared templates and jq modules
+	library *transform.Library
+
+	// Time-based activation
+	intervals     map[string]timeinterval.Set
+	schedules     map[string]*schedule
+	queue         *payloadQueue
+	now           func() time.Time
+	schedulerOnce sync.Once
+
+	// Runtime destination management
+	manageMu sync.Mutex
+	overlay  *config.Overlay
+
+	// Paused destinations
+	pauseMu sync.Mutex
+	pauses  map[string]*pauseState
+
+	// Consecutive delivery failures per destination
+	failMu   sync.Mutex
+	failures map[string]int
+
+	// Request statistics per destination and received webhook count
+	stats    *destination.Stats
+	received atomic.Int64
+
+	// Graceful shutdown of in-flight deliveries
+	drainMu        sync.Mutex
+	draining       bool
+	drained        chan struct{}
+	inflight       map[uint64]*inflightDelivery
+	nextDeliveryID uint64
+	drainCompleted int
+	deadLetter     *deadletter.Store
+
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
 }
 
 // NewHandler creates a new webhook handler
 func NewHandler(cfg *config.Config, logger *logrus.Logger) (*Handler, error) {
 	h := &Handler{
-		config:   cfg,
-		logger:   logger,
-		handlers: make(map[string]destination.Handler),
+		config:    cfg,
+		logger:    logger,
+		handlers:  make(map[string]destination.Handler),
+		filters:   make(map[string]*filter.Filter),
+		schedules: make(map[string]*schedule),
+		queue:     newPayloadQueue(),
+		now:       time.Now,
+		stats:     destination.NewStats(),
+		done:      make(chan struct{}),
 	}
 
+	intervals, err := compileIntervals(cfg)
+	if err != nil {
+		return nil, err
+	}
+	h.intervals = intervals
+
+	library, err := loadLibrary(cfg)
+	if err != nil {
+		return nil, err
+	}
+	h.library = library
+
 	// Initialize destination handlers
-	for _, destCfg := range cfg.Destinations {
+	for i := range cfg.Destinations {
+		destCfg := &cfg.Destinations[i]
 		if !destCfg.Enabled {
 			continue
 		}
 
-		destHandler, err := destination.NewHTTPHandler(&destCfg, nil)
+		components, err := h.newComponents(destCfg)
+		if err != nil {
+			return nil, err
+		}
+
+		h.setComponents(destCfg.Name, components)
+	}
+
+	// Initialize alert state store
+	if cfg.State.Enabled {
+		store, err := state.NewStore(cfg.State.SnapshotFile, cfg.State.Retention, logger)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create handler for destination %s: %w", destCfg.Name, err)
+			return nil, fmt.Errorf("failed to create state store: %w", err)
 		}
+		store.Start(cfg.State.SnapshotInterval)
+		h.state = store
+	}
 
-		h.handlers[destCfg.Name] = destHandler
+	// Initialize delivery history
+	if cfg.History.Enabled {
+		store, err := history.NewStore(cfg.History.Size, cfg.History.File, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create delivery history: %w", err)
+		}
+		h.history = store
+	}
+
+	// Initialize the store of payloads left undelivered at shutdown
+	if cfg.DeadLetter.Enabled {
+		store, err := deadletter.New(cfg.DeadLetter.File)
+		if err != nil {
+			return nil, err
+		}
+		h.deadLetter = store
+	}
+
+	// Load the overlay to keep persisting API changes on top of it. Without an overlay
+	// file, API changes are only kept in memory so reloads can apply them again.
+	if cfg.Management.OverlayFile != "" {
+		overlay, err := config.LoadOverlay(cfg.Management.OverlayFile)
+		if err != nil {
+			return nil, err
+		}
+		h.overlay = overlay
+	} else if cfg.Management.Enabled {
+		h.overlay = &config.Overlay{}
 	}
 
 	return h, nil
 }
 
+// components holds the runtime parts of an enabled destination
+type components struct {
+	handler  destination.Handler
+	filter   *filter.Filter
+	schedule *schedule
+}
+
+// newComponents creates the handler, filter and schedule of a destination
+func (h *Handler) newComponents(destCfg *config.DestinationConfig) (*components, error) {
+	h.mu.RLock()
+	intervals, library := h.intervals, h.library
+	h.mu.RUnlock()
+
+	return h.buildComponents(destCfg, intervals, library)
+}
+
+// buildComponents creates the runtime parts of a destination from the given time intervals and template library
+func (h *Handler) buildComponents(destCfg *config.DestinationConfig, intervals map[string]timeinterval.Set, library *transform.Library) (*components, error) {
+	destFilter, err := filter.New(destCfg.Filter.Include, destCfg.Filter.Exclude, destCfg.Filter.Expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter for destination %s: %w", destCfg.Name, err)
+	}
+
+	sched, err := newSchedule(destCfg, intervals)
+	if err != nil {
+		return nil, err
+	}
+
+	clientConfig := destination.DefaultHTTPClientConfig()
+	clientConfig.RequestIDHeader = h.config.Server.RequestIDHeader
+	clientConfig.Stats = h.stats
+	clientConfig.Library = library
+	clientConfig.DeliveryTimeout = h.config.Server.WebhookTimeout
+
+	destHandler, err := newDestinationHandler(destCfg, clientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create handler for destination %s: %w", destCfg.Name, err)
+	}
+
+	c := &components{handler: destHandler, schedule: sched}
+	if !destFilter.Empty() {
+		c.filter = destFilter
+	}
+
+	if sched != nil && destCfg.OutsideActive == config.OutsideActiveQueue {
+		h.startQueueScheduler()
+	}
+
+	return c, nil
+}
+
+// setComponents installs the runtime parts of a destination, returning the previous handler.
+// The caller must hold h.mu for writing unless the handler is not yet shared.
+func (h *Handler) setComponents(name string, c *components) destination.Handler {
+	previous := h.handlers[name]
+
+	delete(h.handlers, name)
+	delete(h.filters, name)
+	delete(h.schedules, name)
+
+	if c == nil {
+		return previous
+	}
+
+	h.handlers[name] = c.handler
+	if c.filter != nil {
+		h.filters[name] = c.filter
+	}
+	if c.schedule != nil {
+		h.schedules[name] = c.schedule
+	}
+
+	return previous
+}
+
+// handler returns the handler of an enabled destination
+func (h *Handler) handler(name string) (destination.Handler, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	handler, ok := h.handlers[name]
+	return handler, ok
+}
+
+// enabledDestination returns a copy of an enabled destination configuration
+func (h *Handler) enabledDestination(name string) *config.DestinationConfig {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	dest := h.config.GetDestinationByName(name)
+	if dest == nil {
+		return nil
+	}
+
+	destCopy := *dest
+	return &destCopy
+}
+
+// newDestinationHandler creates the handler for a destination according to its delivery mode
+func newDestinationHandler(destCfg *config.DestinationConfig, clientConfig *destination.HTTPClientConfig) (destination.Handler, error) {
+	httpHandler, err := destination.NewHTTPHandler(destCfg, clientConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if destCfg.Digest.Window <= 0 {
+		return httpHandler, nil
+	}
+
+	digestHandler, err := destination.NewDigestHandler(httpHandler)
+	if err != nil {
+		return nil, err
+	}
+	digestHandler.Start()
+
+	return digestHandler, nil
+}
+
 // HandleWebhook processes incoming webhook requests
 func (h *Handler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
@@ -55,15 +293,23 @@ func (h *Handler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	destName := vars["destination"]
 
+	r = ensureRequestID(w, r)
+	h.received.Add(1)
+
 	// Create a logger with request context
-	logger := h.logger.WithFields(logrus.Fields{
+	logger := h.logger.WithContext(r.Context()).WithFields(logrus.Fields{
 		"destination": destName,
 		"remote_addr": r.RemoteAddr,
-		"request_id":  r.Header.Get("X-Request-ID"),
 	})
 
+	if h.Draining() {
+		logger.Warn("Rejected webhook during shutdown")
+		h.rejectDraining(w)
+		return
+	}
+
 	// Find destination configuration
-	dest := h.config.GetDestinationByName(destName)
+	dest := h.enabledDestination(destName)
 	if dest == nil {
 		logger.Warn("Destination not found")
 		h.sendErrorResponse(w, http.StatusNotFound, "Destination not found")
@@ -71,7 +317,11 @@ func (h *Handler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Parse the webhook payload
+	_, parseSpan := tracing.Start(r.Context(), "webhook.parse")
+	parseStart := time.Now()
 	payload, err := alertmanager.ParseWebhookPayload(r)
+	parseDuration := time.Since(parseStart)
+	tracing.End(parseSpan, err)
 	if err != nil {
 		logger.WithError(err).Error("Failed to parse webhook payload")
 
@@ -107,20 +357,28 @@ func (h *Handler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 		}).Debug("Alert details")
 	}
 
-	// Get destination handler
-	handler, exists := h.handlers[destName]
-	if !exists {
-		h.sendErrorResponse(w, http.StatusNotFound, "Destination handler not initialized")
-		return
-	}
-
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	// Bound the whole delivery by the webhook deadline
+	ctx, cancel := context.WithTimeout(r.Context(), h.webhookTimeout())
 	defer cancel()
 
 	// Send to destination
-	err = handler.Send(ctx, payload)
+	result, err := h.deliver(ctx, destName, payload)
 	if err != nil {
+		if errors.Is(err, errHandlerNotInitialized) {
+			h.sendErrorResponse(w, http.StatusNotFound, "Destination handler not initialized")
+			return
+		}
+
+		if errors.Is(err, ErrDestinationPaused) {
+			if status, paused := h.PauseStatus(destName); paused && !status.Until.IsZero() {
+				retryAfter := int(time.Until(status.Until).Seconds()) + 1
+				w.Header().Set("Retry-After", fmt.Sprintf("%d", retryAfter))
+			}
+			logger.Debug("Rejected alerts for paused destination")
+			h.sendErrorResponse(w, http.StatusServiceUnavailable, "Destination is paused")
+			return
+		}
+
 		logger.WithError(err).Error("Failed to send alerts to destination")
 		h.sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to send alerts: %v", err))
 		return
@@ -128,26 +386,252 @@ func (h *Handler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 
 	// Success response
 	response := Response{
-		Status:       "success",
-		Destination:  destName,
-		ReceivedAt:   time.Now().UTC(),
-		AlertsCount:  len(payload.Alerts),
-		GroupKey:     payload.GroupKey,
-		ProcessingMS: time.Since(start).Milliseconds(),
+		Status:           "success",
+		Destination:      destName,
+		ReceivedAt:       time.Now().UTC(),
+		AlertsCount:      len(payload.Alerts),
+		GroupKey:         payload.GroupKey,
+		ProcessingMS:     time.Since(start).Milliseconds(),
+		SuppressedAlerts: result.Suppressed,
+		SkippedAlerts:    result.Skipped,
+		MutedAlerts:      result.Muted,
+		RedirectedTo:     result.RedirectedTo,
+		DeliveryID:       result.DeliveryID,
+		RequestID:        requestid.FromContext(r.Context()),
+		Timing:           newTiming(parseDuration, result.SendInfo, time.Since(start)),
+	}
+
+	switch {
+	case result.Paused && result.Queued:
+		response.Status = "buffered"
+	case result.Paused:
+		response.Status = "paused"
+	case result.RedirectedTo != "":
+		response.Status = "redirected"
+	case result.Muted > 0:
+		response.Status = "muted"
+	case result.Sent == 0 && result.Suppressed == 0 && result.Skipped > 0:
+		response.Status = "skipped"
+	case result.Queued:
+		response.Status = "queued"
+	case result.Sent == 0:
+		response.Status = "suppressed"
 	}
 
 	h.sendJSONResponse(w, http.StatusOK, response)
 }
 
+// deliveryResult describes what happened to a payload in the delivery pipeline
+type deliveryResult struct {
+	Sent         int
+	Suppressed   int
+	Skipped      int
+	Muted        int
+	Queued       bool
+	Paused       bool
+	RedirectedTo string
+	DeliveryID   string
+	SendInfo     *destination.SendInfo
+}
+
+// deliver sends a parsed payload to the named destination handler. A delivery abandoned at
+// shutdown is written to the dead-letter store.
+func (h *Handler) deliver(ctx context.Context, destName string, payload *alertmanager.WebhookPayload) (*deliveryResult, error) {
+	return h.deliverTracked(ctx, destName, payload, true)
+}
+
+// redeliver sends a payload the handler holds, such as a queued or buffered one. The caller
+// keeps the payload when the delivery fails, so it is not written to the dead-letter store.
+func (h *Handler) redeliver(ctx context.Context, destName string, payload *alertmanager.WebhookPayload) error {
+	_, err := h.deliverTracked(ctx, destName, payload, false)
+	return err
+}
+
+// deliverTracked delivers a payload as an in-flight delivery that Drain waits for
+func (h *Handler) deliverTracked(ctx context.Context, destName string, payload *alertmanager.WebhookPayload, persist bool) (result *deliveryResult, err error) {
+	ctx, span := tracing.Start(ctx, "deliver",
+		attribute.String("destination", destName),
+		attribute.Int("alerts.count", len(payload.Alerts)),
+	)
+
+	ctx, done := h.trackDelivery(ctx, destName, payload, persist)
+	defer func() { done(err) }()
+
+	result, err = h.deliverTo(ctx, destName, payload, true)
+	tracing.End(span, err)
+
+	return result, err
+}
+
+// deliverTo runs the delivery pipeline: pause, filter, time intervals, state store and send
+func (h *Handler) deliverTo(ctx context.Context, destName string, payload *alertmanager.WebhookPayload, allowRedirect bool) (*deliveryResult, error) {
+	h.mu.RLock()
+	handler, exists := h.handlers[destName]
+	destFilter, filtered := h.filters[destName]
+	sched, scheduled := h.schedules[destName]
+	h.mu.RUnlock()
+
+	if !exists {
+		return nil, errHandlerNotInitialized
+	}
+
+	dest := h.enabledDestination(destName)
+
+	if dest != nil {
+		if result, paused, err := h.applyPause(ctx, dest, payload); paused {
+			return result, err
+		}
+	}
+
+	result := &deliveryResult{Sent: len(payload.Alerts)}
+
+	if filtered {
+		kept, err := destFilter.Apply(payload)
+		if err != nil {
+			h.logger.WithContext(ctx).WithError(err).WithField("destination", destName).Warn("Filter expression failed, keeping the affected alerts")
+		}
+		result.Skipped = len(payload.Alerts) - len(kept)
+		result.Sent = len(kept)
+
+		if len(kept) == 0 {
+			return result, nil
+		}
+
+		if result.Skipped > 0 {
+			payload = withAlerts(payload, kept)
+		}
+	}
+
+	if scheduled && dest != nil && !sched.isActive(h.now()) {
+		return h.handleInactive(ctx, dest, payload, result, allowRedirect)
+	}
+
+	if h.state != nil && dest != nil {
+		payload = h.applyState(ctx, dest, payload, result)
+		if len(payload.Alerts) == 0 {
+			return result, nil
+		}
+	}
+
+	_, queued := handler.(destination.Flusher)
+
+	info := &destination.SendInfo{}
+	sendStart := time.Now()
+	err := handler.Send(destination.WithSendInfo(ctx, info), payload)
+	result.DeliveryID = h.recordDelivery(ctx, destName, payload, info, sendStart, queued, err)
+	result.SendInfo = info
+	h.recordOutcome(destName, err)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Queued = queued
+
+	if h.state != nil {
+		// Alerts a split send failed to deliver are not recorded, so they are not suppressed as repeats
+		failed := make(map[int]bool)
+		for _, index := range info.FailedAlerts() {
+			failed[index] = true
+		}
+
+		now := time.Now()
+		for i := range payload.Alerts {
+			if !failed[i] {
+				h.state.MarkSent(destName, &payload.Alerts[i], now)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// applyState drops alerts the state store decides should not be sent again
+func (h *Handler) applyState(ctx context.Context, dest *config.DestinationConfig, payload *alertmanager.WebhookPayload, result *deliveryResult) *alertmanager.WebhookPayload {
+	policy := state.Policy{
+		SendResolved:    dest.SendResolvedEnabled(),
+		RepeatInterval:  dest.RepeatInterval,
+		OnlyOnChange:    dest.OnlyOnChange,
+		FlapTransitions: dest.FlapDetection.Transitions,
+		FlapWindow:      dest.FlapDetection.Window,
+	}
+
+	now := time.Now()
+	kept := make([]alertmanager.Alert, 0, len(payload.Alerts))
+
+	for i := range payload.Alerts {
+		alert := &payload.Alerts[i]
+
+		send, reason := h.state.Decide(dest.Name, alert, policy, now)
+		if !send {
+			h.logger.WithContext(ctx).WithFields(logrus.Fields{
+				"destination": dest.Name,
+				"fingerprint": alert.Fingerprint,
+				"status":      alert.Status,
+				"reason":      reason,
+			}).Debug("Alert suppressed by state store")
+			continue
+		}
+
+		kept = append(kept, *alert)
+	}
+
+	result.Suppressed = len(payload.Alerts) - len(kept)
+	result.Sent = len(kept)
+
+	if result.Suppressed == 0 {
+		return payload
+	}
+
+	return withAlerts(payload, kept)
+}
+
+// withAlerts returns a shallow copy of the payload with a different alert list
+func withAlerts(payload *alertmanager.WebhookPayload, alerts []alertmanager.Alert) *alertmanager.WebhookPayload {
+	filtered := *payload
+	filtered.Alerts = alerts
+
+	filtered.Status = "resolved"
+	for i := range alerts {
+		if alerts[i].IsFiring() {
+			filtered.Status = "firing"
+			break
+		}
+	}
+
+	return &filtered
+}
+
+// FlushDigest sends the pending digest of a destination immediately
+func (h *Handler) FlushDigest(ctx context.Context, destName string) (int, error) {
+	handler, exists := h.handler(destName)
+	if !exists {
+		return 0, errHandlerNotInitialized
+	}
+
+	flusher, ok := handler.(destination.Flusher)
+	if !ok {
+		return 0, ErrNotDigestDestination
+	}
+
+	return flusher.Flush(ctx)
+}
+
 // Response represents the response for a webhook request
 type Response struct {
-	Status       string    `json:"status"`
-	Destination  string    `json:"destination"`
-	ReceivedAt   time.Time `json:"received_at"`
-	AlertsCount  int       `json:"alerts_count"`
-	GroupKey     string    `json:"group_key"`
-	ProcessingMS int64     `json:"processing_ms"`
-	Error        string    `json:"error,omitempty"`
+	Status           string    `json:"status"`
+	Destination      string    `json:"destination"`
+	ReceivedAt       time.Time `json:"received_at"`
+	AlertsCount      int       `json:"alerts_count"`
+	GroupKey         string    `json:"group_key"`
+	ProcessingMS     int64     `json:"processing_ms"`
+	Error            string    `json:"error,omitempty"`
+	SuppressedAlerts int       `json:"suppressed_alerts,omitempty"`
+	SkippedAlerts    int       `json:"skipped_alerts,omitempty"`
+	MutedAlerts      int       `json:"muted_alerts,omitempty"`
+	RedirectedTo     string    `json:"redirected_to,omitempty"`
+	DeliveryID       string    `json:"delivery_id,omitempty"`
+	RequestID        string    `json:"request_id,omitempty"`
+	Timing           *Timing   `json:"timing,omitempty"`
 }
 
 // ErrorResponse represents an error response
@@ -180,10 +664,73 @@ func (h *Handler) sendErrorResponse(w http.ResponseWriter, statusCode int, messa
 
 // Close cleans up all destination handlers
 func (h *Handler) Close() error {
+	h.closeOnce.Do(func() {
+		if h.done != nil {
+			close(h.done)
+		}
+	})
+	h.wg.Wait()
+
+	if h.queue != nil {
+		for _, destName := range h.queue.destinations() {
+			h.closePending(destName, deadletter.ReasonQueued, h.queue.take(destName),
+				"Discarding alerts queued outside active time intervals")
+		}
+	}
+
+	h.pauseMu.Lock()
+	buffers := make(map[string][]*alertmanager.WebhookPayload)
+	for destName, state := range h.pauses {
+		if state.timer != nil {
+			state.timer.Stop()
+		}
+		if len(state.buffer) > 0 {
+			buffers[destName] = state.buffer
+		}
+	}
+	h.pauseMu.Unlock()
+
+	for destName, payloads := range buffers {
+		h.closePending(destName, deadletter.ReasonPaused, payloads,
+			"Discarding alerts buffered for paused destination")
+	}
+
+	h.mu.RLock()
+	handlers := make(map[string]destination.Handler, len(h.handlers))
 	for name, handler := range h.handlers {
+		handlers[name] = handler
+	}
+	h.mu.RUnlock()
+
+	for name, handler := range handlers {
 		if err := handler.Close(); err != nil {
 			h.logger.WithError(err).WithField("destination", name).Error("Failed to close destination handler")
 		}
+
+		// A digest that could not be sent on close is kept like other undelivered payloads
+		if flusher, ok := handler.(destination.Flusher); ok {
+			if payload := flusher.TakePending(); payload != nil {
+				h.closePending(name, deadletter.ReasonDigest, []*alertmanager.WebhookPayload{payload},
+					"Discarding undelivered digest alerts")
+			}
+		}
+	}
+
+	if h.state != nil {
+		if err := h.state.Close(); err != nil {
+			h.logger.WithError(err).Error("Failed to close state store")
+		}
 	}
+
+	if h.history != nil {
+		if err := h.history.Close(); err != nil {
+			h.logger.WithError(err).Error("Failed to close delivery history")
+		}
+	}
+
+	if err := h.deadLetter.Close(); err != nil {
+		h.logger.WithError(err).Error("Failed to close dead-letter store")
+	}
+
 	return nil
 }
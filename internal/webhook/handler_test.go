@@ -3,9 +3,11 @@ package webhook
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -16,6 +18,9 @@ import (
 	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
 	"github.com/vitalvas/alertmanager-gateway/internal/config"
 	"github.com/vitalvas/alertmanager-gateway/internal/destination"
+	"github.com/vitalvas/alertmanager-gateway/internal/filter"
+	"github.com/vitalvas/alertmanager-gateway/internal/history"
+	"github.com/vitalvas/alertmanager-gateway/internal/state"
 )
 
 // mockDestinationHandler is a mock implementation for testing
@@ -131,6 +136,12 @@ func TestHandler_HandleWebhook(t *testing.T) {
 				assert.Equal(t, float64(1), resp["alerts_count"])
 				assert.Equal(t, "test-group", resp["group_key"])
 				assert.NotNil(t, resp["processing_ms"])
+
+				timing, ok := resp["timing"].(map[string]interface{})
+				require.True(t, ok)
+				for _, key := range []string{"parse_ms", "transform_ms", "format_ms", "send_ms", "total_ms"} {
+					assert.Contains(t, timing, key)
+				}
 			},
 		},
 		{
@@ -217,6 +228,57 @@ func TestHandler_HandleWebhook(t *testing.T) {
 	}
 }
 
+func TestHandler_WebhookTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		want    time.Duration
+	}{
+		{name: "configured", timeout: 2 * time.Second, want: 2 * time.Second},
+		{name: "default", want: defaultWebhookTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				Server: config.ServerConfig{WebhookTimeout: tt.timeout},
+				Destinations: []config.DestinationConfig{
+					{Name: "test-dest", Enabled: true},
+				},
+			}
+
+			var remaining time.Duration
+			handler := &Handler{
+				config: cfg,
+				logger: logrus.New(),
+				handlers: map[string]destination.Handler{
+					"test-dest": &mockDestinationHandler{
+						name: "test-dest",
+						sendFunc: func(ctx context.Context, _ *alertmanager.WebhookPayload) error {
+							deadline, ok := ctx.Deadline()
+							require.True(t, ok)
+							remaining = time.Until(deadline)
+							return nil
+						},
+					},
+				},
+			}
+
+			body := `{"version":"4","groupKey":"g","status":"firing","alerts":[{"status":"firing","labels":{"alertname":"Test"},"fingerprint":"abc","startsAt":"2024-01-01T00:00:00Z"}]}`
+			req := httptest.NewRequest("POST", "/webhook/test-dest", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req = mux.SetURLVars(req, map[string]string{"destination": "test-dest"})
+
+			w := httptest.NewRecorder()
+			handler.HandleWebhook(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.LessOrEqual(t, remaining, tt.want)
+			assert.Greater(t, remaining, tt.want-time.Second)
+		})
+	}
+}
+
 func TestWebhookResponse_JSON(t *testing.T) {
 	resp := Response{
 		Status:       "success",
@@ -298,3 +360,306 @@ func TestHandler_sendErrorResponse(t *testing.T) {
 	assert.Equal(t, "Bad request", result["error"])
 	assert.NotNil(t, result["timestamp"])
 }
+
+func TestHandler_StateSuppression(t *testing.T) {
+	cfg := &config.Config{
+		Destinations: []config.DestinationConfig{
+			{Name: "test-dest", Enabled: true, OnlyOnChange: true},
+		},
+	}
+
+	store, err := state.NewStore("", time.Hour, logrus.New())
+	require.NoError(t, err)
+
+	sendCount := 0
+	handler := &Handler{
+		config: cfg,
+		logger: logrus.New(),
+		handlers: map[string]destination.Handler{
+			"test-dest": &mockDestinationHandler{
+				name: "test-dest",
+				sendFunc: func(_ context.Context, _ *alertmanager.WebhookPayload) error {
+					sendCount++
+					return nil
+				},
+			},
+		},
+		state: store,
+	}
+
+	payload := alertmanager.WebhookPayload{
+		Version:  "4",
+		GroupKey: "test-group",
+		Status:   "firing",
+		Alerts: []alertmanager.Alert{
+			{Status: "firing", Fingerprint: "abc123", StartsAt: time.Now()},
+		},
+	}
+	body, err := json.Marshal(payload)
+	require.NoError(t, err)
+
+	send := func() map[string]interface{} {
+		req := httptest.NewRequest("POST", "/webhook/test-dest", strings.NewReader(string(body)))
+		req = mux.SetURLVars(req, map[string]string{"destination": "test-dest"})
+		w := httptest.NewRecorder()
+		handler.HandleWebhook(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp
+	}
+
+	first := send()
+	assert.Equal(t, "success", first["status"])
+	assert.NotContains(t, first, "suppressed_alerts")
+
+	second := send()
+	assert.Equal(t, "suppressed", second["status"])
+	assert.Equal(t, float64(1), second["suppressed_alerts"])
+
+	assert.Equal(t, 1, sendCount)
+}
+
+func TestHandler_StatePartialSplitFailure(t *testing.T) {
+	var mu sync.Mutex
+	received := make(map[string]int)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		mu.Lock()
+		received[body["fingerprint"]]++
+		mu.Unlock()
+
+		if body["fingerprint"] == "bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	cfg := &config.Config{
+		Destinations: []config.DestinationConfig{{
+			Name:         "split",
+			URL:          upstream.URL,
+			Method:       "POST",
+			Format:       "json",
+			Engine:       "go-template",
+			Template:     `{"fingerprint": "{{ .Fingerprint }}"}`,
+			SplitAlerts:  true,
+			BatchSize:    1,
+			OnlyOnChange: true,
+			Enabled:      true,
+		}},
+	}
+
+	handler, err := NewHandler(cfg, logrus.New())
+	require.NoError(t, err)
+	defer handler.Close()
+
+	store, err := state.NewStore("", time.Hour, logrus.New())
+	require.NoError(t, err)
+	handler.state = store
+
+	payload := &alertmanager.WebhookPayload{
+		Status: "firing",
+		Alerts: []alertmanager.Alert{
+			{Status: "firing", Fingerprint: "good", StartsAt: time.Now()},
+			{Status: "firing", Fingerprint: "bad", StartsAt: time.Now()},
+		},
+	}
+
+	// Only one alert failed, so the send succeeds, but the failed alert is not marked as sent
+	_, err = handler.deliver(context.Background(), "split", payload)
+	require.NoError(t, err)
+
+	result, err := handler.deliver(context.Background(), "split", payload)
+	require.Error(t, err)
+	assert.Nil(t, result)
+
+	assert.Equal(t, map[string]int{"good": 1, "bad": 2}, received)
+}
+
+func TestHandler_DigestQueued(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		Destinations: []config.DestinationConfig{
+			{
+				Name:     "digest-dest",
+				URL:      server.URL,
+				Method:   "POST",
+				Format:   "json",
+				Engine:   "go-template",
+				Template: `{"count": {{ len .Alerts }}}`,
+				Enabled:  true,
+				Digest:   config.DigestConfig{Window: time.Hour},
+			},
+			{
+				Name:     "plain-dest",
+				URL:      server.URL,
+				Method:   "POST",
+				Format:   "json",
+				Engine:   "go-template",
+				Template: `{}`,
+				Enabled:  true,
+			},
+		},
+	}
+
+	handler, err := NewHandler(cfg, logrus.New())
+	require.NoError(t, err)
+	defer handler.Close()
+
+	body := `{"version":"4","groupKey":"g","status":"firing","receiver":"r","alerts":[{"status":"firing","fingerprint":"abc","labels":{"alertname":"Test"},"startsAt":"2024-01-01T00:00:00Z"}]}`
+	req := httptest.NewRequest("POST", "/webhook/digest-dest", strings.NewReader(body))
+	req = mux.SetURLVars(req, map[string]string{"destination": "digest-dest"})
+	w := httptest.NewRecorder()
+	handler.HandleWebhook(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "queued", resp.Status)
+	assert.Equal(t, 0, requests)
+
+	sent, err := handler.FlushDigest(context.Background(), "digest-dest")
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, 1, requests)
+
+	_, err = handler.FlushDigest(context.Background(), "plain-dest")
+	assert.ErrorIs(t, err, ErrNotDigestDestination)
+
+	_, err = handler.FlushDigest(context.Background(), "missing")
+	assert.Error(t, err)
+}
+
+func TestHandler_Filter(t *testing.T) {
+	destFilter, err := filter.New([]string{`severity=~"critical|warning"`}, []string{`team="sandbox"`}, `alert.status == "firing"`)
+	require.NoError(t, err)
+
+	var received []alertmanager.Alert
+	handler := &Handler{
+		config: &config.Config{
+			Destinations: []config.DestinationConfig{{Name: "test-dest", Enabled: true}},
+		},
+		logger: logrus.New(),
+		handlers: map[string]destination.Handler{
+			"test-dest": &mockDestinationHandler{
+				name: "test-dest",
+				sendFunc: func(_ context.Context, payload *alertmanager.WebhookPayload) error {
+					received = append(received, payload.Alerts...)
+					return nil
+				},
+			},
+		},
+		filters: map[string]*filter.Filter{"test-dest": destFilter},
+	}
+
+	send := func(alerts ...alertmanager.Alert) Response {
+		body, err := json.Marshal(alertmanager.WebhookPayload{
+			Version:  "4",
+			GroupKey: "test-group",
+			Status:   "firing",
+			Alerts:   alerts,
+		})
+		require.NoError(t, err)
+
+		req := httptest.NewRequest("POST", "/webhook/test-dest", strings.NewReader(string(body)))
+		req = mux.SetURLVars(req, map[string]string{"destination": "test-dest"})
+		w := httptest.NewRecorder()
+		handler.HandleWebhook(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp
+	}
+
+	alert := func(fingerprint string, labels map[string]string) alertmanager.Alert {
+		return alertmanager.Alert{Status: "firing", Fingerprint: fingerprint, Labels: labels, StartsAt: time.Now()}
+	}
+
+	resp := send(
+		alert("a", map[string]string{"alertname": "A", "severity": "critical"}),
+		alert("b", map[string]string{"alertname": "B", "severity": "info"}),
+		alert("c", map[string]string{"alertname": "C", "severity": "warning", "team": "sandbox"}),
+		alertmanager.Alert{Status: "resolved", Fingerprint: "e", Labels: map[string]string{"alertname": "E", "severity": "critical"}, StartsAt: time.Now()},
+	)
+	assert.Equal(t, "success", resp.Status)
+	assert.Equal(t, 3, resp.SkippedAlerts)
+	require.Len(t, received, 1)
+	assert.Equal(t, "a", received[0].Fingerprint)
+
+	resp = send(alert("d", map[string]string{"alertname": "D", "severity": "info"}))
+	assert.Equal(t, "skipped", resp.Status)
+	assert.Equal(t, 1, resp.SkippedAlerts)
+	assert.Len(t, received, 1)
+}
+
+func TestHandler_DeliveryHistory(t *testing.T) {
+	store, err := history.NewStore(10, "", logrus.New())
+	require.NoError(t, err)
+
+	fail := false
+	handler := &Handler{
+		config: &config.Config{
+			Destinations: []config.DestinationConfig{{Name: "test-dest", Enabled: true}},
+		},
+		logger: logrus.New(),
+		handlers: map[string]destination.Handler{
+			"test-dest": &mockDestinationHandler{
+				name: "test-dest",
+				sendFunc: func(_ context.Context, _ *alertmanager.WebhookPayload) error {
+					if fail {
+						return errors.New("connection refused")
+					}
+					return nil
+				},
+			},
+		},
+		history: store,
+	}
+
+	send := func() *httptest.ResponseRecorder {
+		body := `{"version":"4","groupKey":"g","status":"firing","alerts":[{"status":"firing","fingerprint":"abc","startsAt":"2024-01-01T00:00:00Z"}]}`
+		req := httptest.NewRequest("POST", "/webhook/test-dest", strings.NewReader(body))
+		req.Header.Set("X-Request-ID", "req-1")
+		req = mux.SetURLVars(req, map[string]string{"destination": "test-dest"})
+		w := httptest.NewRecorder()
+		handler.HandleWebhook(w, req)
+		return w
+	}
+
+	w := send()
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotEmpty(t, resp.DeliveryID)
+
+	record, exists := handler.Delivery(resp.DeliveryID)
+	require.True(t, exists)
+	assert.Equal(t, "test-dest", record.Destination)
+	assert.Equal(t, "g", record.GroupKey)
+	assert.Equal(t, []string{"abc"}, record.Fingerprints)
+	assert.Equal(t, history.StatusSuccess, record.Status)
+	assert.Equal(t, "req-1", record.RequestID)
+
+	fail = true
+	w = send()
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+
+	records := handler.Deliveries(history.Query{Fingerprint: "abc"})
+	require.Len(t, records, 2)
+	assert.Equal(t, history.StatusError, records[0].Status)
+	assert.Equal(t, "connection refused", records[0].Error)
+}
@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+	"github.com/vitalvas/alertmanager-gateway/internal/destination"
+	"github.com/vitalvas/alertmanager-gateway/internal/history"
+	"github.com/vitalvas/alertmanager-gateway/internal/requestid"
+)
+
+// ensureRequestID makes sure the request carries a request id when it was not set by the server middleware
+func ensureRequestID(w http.ResponseWriter, r *http.Request) *http.Request {
+	if requestid.FromContext(r.Context()) != "" {
+		return r
+	}
+
+	id := r.Header.Get(requestid.Header)
+	if id == "" {
+		id = requestid.New()
+	}
+	w.Header().Set(requestid.Header, id)
+
+	return r.WithContext(requestid.NewContext(r.Context(), id))
+}
+
+// Deliveries returns delivery records matching the query, newest first
+func (h *Handler) Deliveries(q history.Query) []history.Record {
+	if h.history == nil {
+		return []history.Record{}
+	}
+
+	return h.history.Query(q)
+}
+
+// Delivery returns a delivery record by ID
+func (h *Handler) Delivery(id string) (history.Record, bool) {
+	if h.history == nil {
+		return history.Record{}, false
+	}
+
+	return h.history.Get(id)
+}
+
+// HistoryEnabled reports whether delivery history is recorded
+func (h *Handler) HistoryEnabled() bool {
+	return h.history != nil
+}
+
+// recordDelivery adds a delivery attempt to the history and returns its ID
+func (h *Handler) recordDelivery(ctx context.Context, destName string, payload *alertmanager.WebhookPayload, info *destination.SendInfo, start time.Time, queued bool, err error) string {
+	if h.history == nil {
+		return ""
+	}
+
+	fingerprints := make([]string, 0, len(payload.Alerts))
+	for _, alert := range payload.Alerts {
+		fingerprints = append(fingerprints, alert.Fingerprint)
+	}
+
+	record := history.Record{
+		RequestID:    requestid.FromContext(ctx),
+		Destination:  destName,
+		GroupKey:     payload.GroupKey,
+		Fingerprints: fingerprints,
+		Status:       history.StatusSuccess,
+		StatusCode:   info.StatusCode(),
+		Requests:     info.Requests(),
+		LatencyMS:    time.Since(start).Milliseconds(),
+		BodySize:     info.BodySize(),
+		Timestamp:    start.UTC(),
+	}
+
+	switch {
+	case err != nil:
+		record.Status = history.StatusError
+		record.Error = err.Error()
+	case queued:
+		record.Status = history.StatusQueued
+	}
+
+	return h.history.Add(record)
+}
@@ -0,0 +1,276 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vitalvas/alertmanager-gateway/internal/config"
+	"github.com/vitalvas/alertmanager-gateway/internal/destination"
+)
+
+// Errors returned by runtime destination management
+var (
+	ErrDestinationExists   = errors.New("destination already exists")
+	ErrDestinationNotFound = errors.New("destination not found")
+	ErrInvalidDestination  = errors.New("invalid destination")
+)
+
+// Destinations returns a snapshot of all configured destinations
+func (h *Handler) Destinations() []config.DestinationConfig {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return append([]config.DestinationConfig(nil), h.config.Destinations...)
+}
+
+// Destination returns a destination by name regardless of its enabled status
+func (h *Handler) Destination(name string) (config.DestinationConfig, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	dest := h.config.GetDestinationByNameAny(name)
+	if dest == nil {
+		return config.DestinationConfig{}, false
+	}
+
+	return *dest, true
+}
+
+// CreateDestination validates a new destination and starts routing alerts to it
+func (h *Handler) CreateDestination(dest config.DestinationConfig) (config.DestinationConfig, error) {
+	h.manageMu.Lock()
+	defer h.manageMu.Unlock()
+
+	if _, exists := h.Destination(dest.Name); exists {
+		return config.DestinationConfig{}, ErrDestinationExists
+	}
+
+	if err := h.loadSourceFiles(&dest, config.DestinationConfig{}); err != nil {
+		return config.DestinationConfig{}, err
+	}
+
+	now := time.Now().UTC()
+	dest.SetDefaults()
+	dest.Version = 1
+	dest.CreatedAt = now
+	dest.UpdatedAt = now
+
+	destinations := append(h.Destinations(), dest)
+	if err := h.applyDestinations(destinations, &dest, dest.Name); err != nil {
+		return config.DestinationConfig{}, err
+	}
+
+	h.logger.WithField("destination", dest.Name).Info("Destination created")
+
+	return dest, nil
+}
+
+// UpdateDestination validates a destination and replaces the existing one with the same name
+func (h *Handler) UpdateDestination(name string, dest config.DestinationConfig) (config.DestinationConfig, error) {
+	h.manageMu.Lock()
+	defer h.manageMu.Unlock()
+
+	current, exists := h.Destination(name)
+	if !exists {
+		return config.DestinationConfig{}, ErrDestinationNotFound
+	}
+
+	if dest.Name != name {
+		return config.DestinationConfig{}, fmt.Errorf("%w: name cannot be changed", ErrInvalidDestination)
+	}
+
+	if err := h.loadSourceFiles(&dest, current); err != nil {
+		return config.DestinationConfig{}, err
+	}
+
+	dest.SetDefaults()
+	dest.Version = current.Version + 1
+	dest.CreatedAt = current.CreatedAt
+	dest.UpdatedAt = time.Now().UTC()
+
+	destinations := h.Destinations()
+	for i := range destinations {
+		if destinations[i].Name == name {
+			destinations[i] = dest
+		}
+	}
+
+	if err := h.applyDestinations(destinations, &dest, name); err != nil {
+		return config.DestinationConfig{}, err
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"destination": name,
+		"version":     dest.Version,
+		"enabled":     dest.Enabled,
+	}).Info("Destination updated")
+
+	return dest, nil
+}
+
+// DeleteDestination stops routing alerts to a destination and removes it
+func (h *Handler) DeleteDestination(name string) error {
+	h.manageMu.Lock()
+	defer h.manageMu.Unlock()
+
+	if _, exists := h.Destination(name); !exists {
+		return ErrDestinationNotFound
+	}
+
+	current := h.Destinations()
+	destinations := make([]config.DestinationConfig, 0, len(current))
+	for _, dest := range current {
+		if dest.Name != name {
+			destinations = append(destinations, dest)
+		}
+	}
+
+	if err := h.applyDestinations(destinations, nil, name); err != nil {
+		return err
+	}
+
+	h.clearPause(name)
+	h.clearFailures(name)
+	h.ResetStats(name)
+
+	h.logger.WithField("destination", name).Info("Destination deleted")
+
+	return nil
+}
+
+// applyDestinations validates the new destination list, persists it and hot-swaps the handler of the
+// changed destination. A nil dest removes the destination's handler.
+func (h *Handler) applyDestinations(destinations []config.DestinationConfig, dest *config.DestinationConfig, name string) error {
+	h.mu.RLock()
+	candidate := *h.config
+	h.mu.RUnlock()
+
+	candidate.Destinations = destinations
+	if err := candidate.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDestination, err)
+	}
+
+	var next *components
+	if dest != nil && dest.Enabled {
+		var err error
+		next, err = h.newComponents(dest)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidDestination, err)
+		}
+	}
+
+	if err := h.persistOverlay(dest, name); err != nil {
+		if next != nil {
+			h.closeHandler(name, next.handler)
+		}
+		return err
+	}
+
+	h.mu.Lock()
+	h.config.Destinations = destinations
+	previous := h.setComponents(name, next)
+	h.mu.Unlock()
+
+	var handler destination.Handler
+	if next != nil {
+		handler = next.handler
+	}
+	h.replaceHandler(name, previous, handler)
+
+	return nil
+}
+
+// persistOverlay records the change in the overlay, and in the overlay file if one is configured
+func (h *Handler) persistOverlay(dest *config.DestinationConfig, name string) error {
+	if h.overlay == nil {
+		return nil
+	}
+
+	overlay := h.overlay.Clone()
+	if dest != nil {
+		overlay.Set(*dest)
+	} else {
+		overlay.Delete(name)
+	}
+
+	if file := h.config.Management.OverlayFile; file != "" {
+		if err := config.SaveOverlay(file, overlay); err != nil {
+			return fmt.Errorf("failed to persist destination change: %w", err)
+		}
+	}
+
+	h.overlay = overlay
+
+	return nil
+}
+
+// loadSourceFiles reads the template_file and transform_file of a destination submitted
+// through the API. Files the current destination already uses are kept; other files must
+// be inside management.source_directory.
+func (h *Handler) loadSourceFiles(dest *config.DestinationConfig, current config.DestinationConfig) error {
+	h.mu.RLock()
+	dir := filepath.Dir(h.config.Path())
+	root := h.config.Management.SourceDirectory
+	h.mu.RUnlock()
+
+	if root != "" && !filepath.IsAbs(root) {
+		root = filepath.Join(dir, root)
+	}
+
+	files := []struct {
+		field   string
+		path    *string
+		current string
+	}{
+		{"template_file", &dest.TemplateFile, current.TemplateFile},
+		{"transform_file", &dest.TransformFile, current.TransformFile},
+	}
+
+	for _, file := range files {
+		if *file.path == "" || *file.path == file.current {
+			continue
+		}
+
+		if root == "" {
+			return fmt.Errorf("%w: %s requires management.source_directory", ErrInvalidDestination, file.field)
+		}
+
+		resolved, err := config.ResolveSourceFile(root, *file.path)
+		if err != nil {
+			return fmt.Errorf("%w: failed to read %s: %v", ErrInvalidDestination, file.field, err)
+		}
+		*file.path = resolved
+	}
+
+	if err := dest.LoadSourceFiles(dir); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDestination, err)
+	}
+
+	return nil
+}
+
+// replaceHandler closes the handler a destination used before next. A digest moves its pending
+// alerts and window to next instead of being sent early.
+func (h *Handler) replaceHandler(name string, previous, next destination.Handler) {
+	if digest, ok := previous.(*destination.DigestHandler); ok {
+		if nextDigest, ok := next.(*destination.DigestHandler); ok {
+			nextDigest.Adopt(digest)
+		}
+	}
+
+	h.closeHandler(name, previous)
+}
+
+// closeHandler closes a replaced destination handler
+func (h *Handler) closeHandler(name string, handler destination.Handler) {
+	if handler == nil {
+		return
+	}
+
+	if err := handler.Close(); err != nil {
+		h.logger.WithError(err).WithField("destination", name).Error("Failed to close replaced destination handler")
+	}
+}
@@ -0,0 +1,124 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/alertmanager-gateway/internal/config"
+)
+
+func newManagedHandler(t *testing.T, targetURL, overlayFile string) *Handler {
+	t.Helper()
+
+	cfg := &config.Config{
+		Server:     config.ServerConfig{Address: ":8080"},
+		Management: config.ManagementConfig{OverlayFile: overlayFile},
+		Destinations: []config.DestinationConfig{
+			{Name: "static", URL: targetURL, Method: "POST", Format: "json", Engine: "go-template", Template: "{}", Enabled: true, Version: 1},
+		},
+	}
+
+	handler, err := NewHandler(cfg, logrus.New())
+	require.NoError(t, err)
+	t.Cleanup(func() { handler.Close() })
+
+	return handler
+}
+
+func postWebhook(handler *Handler, destName string) int {
+	body := `{"version":"4","groupKey":"g","status":"firing","alerts":[{"status":"firing","fingerprint":"abc","startsAt":"2024-01-01T00:00:00Z"}]}`
+	req := httptest.NewRequest("POST", "/webhook/"+destName, strings.NewReader(body))
+	req = mux.SetURLVars(req, map[string]string{"destination": destName})
+	w := httptest.NewRecorder()
+	handler.HandleWebhook(w, req)
+	return w.Code
+}
+
+func TestHandler_DestinationLifecycle(t *testing.T) {
+	var requests int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	overlayFile := filepath.Join(t.TempDir(), "overlay.yaml")
+	handler := newManagedHandler(t, target.URL, overlayFile)
+
+	dest := config.DestinationConfig{Name: "dynamic", URL: target.URL, Template: "{}", Enabled: true}
+
+	created, err := handler.CreateDestination(dest)
+	require.NoError(t, err)
+	assert.Equal(t, 1, created.Version)
+	assert.Equal(t, "POST", created.Method)
+	assert.False(t, created.CreatedAt.IsZero())
+
+	_, err = handler.CreateDestination(dest)
+	assert.ErrorIs(t, err, ErrDestinationExists)
+
+	assert.Equal(t, http.StatusOK, postWebhook(handler, "dynamic"))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	// Disable through an update
+	dest.Enabled = false
+	updated, err := handler.UpdateDestination("dynamic", dest)
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated.Version)
+	assert.Equal(t, created.CreatedAt, updated.CreatedAt)
+	assert.Equal(t, http.StatusNotFound, postWebhook(handler, "dynamic"))
+
+	overlay, err := config.LoadOverlay(overlayFile)
+	require.NoError(t, err)
+	require.Len(t, overlay.Destinations, 1)
+	assert.False(t, overlay.Destinations[0].Enabled)
+	assert.Equal(t, 2, overlay.Destinations[0].Version)
+
+	require.NoError(t, handler.DeleteDestination("dynamic"))
+	_, exists := handler.Destination("dynamic")
+	assert.False(t, exists)
+	assert.ErrorIs(t, handler.DeleteDestination("dynamic"), ErrDestinationNotFound)
+
+	overlay, err = config.LoadOverlay(overlayFile)
+	require.NoError(t, err)
+	assert.Empty(t, overlay.Destinations)
+	assert.Equal(t, []string{"dynamic"}, overlay.Deleted)
+}
+
+func TestHandler_DestinationValidation(t *testing.T) {
+	handler := newManagedHandler(t, "https://example.com", "")
+
+	tests := []struct {
+		name string
+		dest config.DestinationConfig
+	}{
+		{name: "missing url", dest: config.DestinationConfig{Name: "bad", Template: "{}"}},
+		{name: "invalid name", dest: config.DestinationConfig{Name: "bad name", URL: "https://example.com", Template: "{}"}},
+		{name: "invalid template", dest: config.DestinationConfig{Name: "bad", URL: "https://example.com", Template: "{{ .Broken", Enabled: true}},
+		{name: "invalid filter", dest: config.DestinationConfig{Name: "bad", URL: "https://example.com", Template: "{}", Filter: config.FilterConfig{Include: []string{"nope"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := handler.CreateDestination(tt.dest)
+			assert.ErrorIs(t, err, ErrInvalidDestination)
+		})
+	}
+
+	_, err := handler.UpdateDestination("static", config.DestinationConfig{Name: "renamed", URL: "https://example.com", Template: "{}"})
+	assert.ErrorIs(t, err, ErrInvalidDestination)
+
+	_, err = handler.UpdateDestination("missing", config.DestinationConfig{Name: "missing"})
+	assert.ErrorIs(t, err, ErrDestinationNotFound)
+
+	// Removing the only destination fails validation
+	assert.ErrorIs(t, handler.DeleteDestination("static"), ErrInvalidDestination)
+	assert.Len(t, handler.Destinations(), 1)
+}
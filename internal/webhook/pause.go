@@ -0,0 +1,285 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+	"github.com/vitalvas/alertmanager-gateway/internal/config"
+	"github.com/vitalvas/alertmanager-gateway/internal/deadletter"
+)
+
+// ErrDestinationPaused is returned when a paused destination rejects alerts
+var ErrDestinationPaused = errors.New("destination is paused")
+
+// PauseStatus describes a paused destination
+type PauseStatus struct {
+	Since    time.Time
+	Until    time.Time // zero when paused until resumed
+	Reason   string
+	Mode     string
+	Buffered int
+}
+
+// pauseState holds the runtime state of a paused destination
+type pauseState struct {
+	since  time.Time
+	until  time.Time
+	reason string
+	buffer []*alertmanager.WebhookPayload
+	timer  *time.Timer
+}
+
+// Pause stops delivery to an enabled destination until it is resumed or the TTL expires
+func (h *Handler) Pause(ctx context.Context, name string, ttl time.Duration, reason string) (PauseStatus, error) {
+	dest := h.enabledDestination(name)
+	if dest == nil {
+		return PauseStatus{}, ErrDestinationNotFound
+	}
+
+	h.pauseMu.Lock()
+	defer h.pauseMu.Unlock()
+
+	if h.pauses == nil {
+		h.pauses = make(map[string]*pauseState)
+	}
+
+	state, paused := h.pauses[name]
+	if !paused {
+		state = &pauseState{since: time.Now().UTC()}
+		h.pauses[name] = state
+	}
+
+	if state.timer != nil {
+		state.timer.Stop()
+		state.timer = nil
+	}
+
+	state.reason = reason
+	state.until = time.Time{}
+
+	if ttl > 0 {
+		state.until = time.Now().UTC().Add(ttl)
+		state.timer = time.AfterFunc(ttl, func() {
+			h.expirePause(name, state)
+		})
+	}
+
+	h.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"destination": name,
+		"ttl":         ttl.String(),
+		"reason":      reason,
+		"mode":        dest.Pause.Mode,
+	}).Warn("Destination paused")
+
+	return state.status(dest.Pause.Mode), nil
+}
+
+// Resume restarts delivery to a paused destination and sends any buffered payloads.
+// It returns the number of buffered payloads delivered. Payloads that fail are written to the
+// dead-letter store, or kept buffered on a paused destination when the store is disabled.
+func (h *Handler) Resume(ctx context.Context, name string) (int, error) {
+	h.pauseMu.Lock()
+	state, paused := h.pauses[name]
+	if paused {
+		delete(h.pauses, name)
+		if state.timer != nil {
+			state.timer.Stop()
+		}
+	}
+	h.pauseMu.Unlock()
+
+	if !paused {
+		if _, exists := h.Destination(name); !exists {
+			return 0, ErrDestinationNotFound
+		}
+		return 0, nil
+	}
+
+	h.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"destination": name,
+		"buffered":    len(state.buffer),
+	}).Info("Destination resumed")
+
+	// Buffered payloads must not be lost when the caller goes away
+	ctx = context.WithoutCancel(ctx)
+
+	delivered, failed, err := h.deliverBuffered(ctx, name, state.buffer)
+	if len(failed) > 0 {
+		h.keepUndelivered(ctx, name, state, failed)
+	}
+
+	return delivered, err
+}
+
+// keepUndelivered dead-letters buffered payloads that failed on resume, or pauses the destination
+// again with them at the front of its buffer when the dead-letter store is disabled
+func (h *Handler) keepUndelivered(ctx context.Context, name string, resumed *pauseState, failed []*alertmanager.WebhookPayload) {
+	if h.deadLetter != nil {
+		h.closePending(name, deadletter.ReasonPaused, failed, "Discarding buffered alerts that failed on resume")
+		return
+	}
+
+	h.pauseMu.Lock()
+	defer h.pauseMu.Unlock()
+
+	if h.pauses == nil {
+		h.pauses = make(map[string]*pauseState)
+	}
+
+	state, paused := h.pauses[name]
+	if !paused {
+		state = &pauseState{since: resumed.since, reason: resumed.reason}
+		h.pauses[name] = state
+	}
+	state.buffer = append(failed, state.buffer...)
+
+	h.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"destination": name,
+		"buffered":    len(failed),
+	}).Warn("Destination paused again with buffered alerts that failed on resume")
+}
+
+// PauseStatus returns the pause status of a destination
+func (h *Handler) PauseStatus(name string) (PauseStatus, bool) {
+	h.pauseMu.Lock()
+	defer h.pauseMu.Unlock()
+
+	state, paused := h.pauses[name]
+	if !paused {
+		return PauseStatus{}, false
+	}
+
+	mode := config.PauseModeReject
+	if dest, exists := h.Destination(name); exists {
+		mode = dest.Pause.Mode
+	}
+
+	return state.status(mode), true
+}
+
+// PausedDestinations returns the names of all paused destinations
+func (h *Handler) PausedDestinations() []string {
+	h.pauseMu.Lock()
+	defer h.pauseMu.Unlock()
+
+	names := make([]string, 0, len(h.pauses))
+	for name := range h.pauses {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// clearPause discards the pause state of a removed destination
+func (h *Handler) clearPause(name string) {
+	h.pauseMu.Lock()
+	defer h.pauseMu.Unlock()
+
+	if state, paused := h.pauses[name]; paused {
+		if state.timer != nil {
+			state.timer.Stop()
+		}
+		delete(h.pauses, name)
+	}
+}
+
+// applyPause handles a payload for a paused destination. It reports false if the destination is not paused.
+func (h *Handler) applyPause(ctx context.Context, dest *config.DestinationConfig, payload *alertmanager.WebhookPayload) (*deliveryResult, bool, error) {
+	h.pauseMu.Lock()
+	defer h.pauseMu.Unlock()
+
+	state, paused := h.pauses[dest.Name]
+	if !paused {
+		return nil, false, nil
+	}
+
+	logger := h.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"destination":  dest.Name,
+		"mode":         dest.Pause.Mode,
+		"alerts_count": len(payload.Alerts),
+	})
+
+	result := &deliveryResult{Paused: true}
+
+	switch dest.Pause.Mode {
+	case config.PauseModeDrop:
+		logger.Debug("Dropped alerts for paused destination")
+		return result, true, nil
+
+	case config.PauseModeBuffer:
+		state.buffer = append(state.buffer, payload)
+		if limit := dest.Pause.BufferSize; limit > 0 && len(state.buffer) > limit {
+			state.buffer = state.buffer[len(state.buffer)-limit:]
+			logger.Warn("Pause buffer is full, dropped oldest payload")
+		}
+		logger.Debug("Buffered alerts for paused destination")
+
+		result.Queued = true
+		return result, true, nil
+
+	default:
+		return nil, true, ErrDestinationPaused
+	}
+}
+
+// expirePause resumes a destination when its pause TTL elapses
+func (h *Handler) expirePause(name string, expired *pauseState) {
+	h.pauseMu.Lock()
+	current, paused := h.pauses[name]
+	h.pauseMu.Unlock()
+
+	// Ignore timers of pauses that were already resumed or replaced
+	if !paused || current != expired {
+		return
+	}
+
+	if _, err := h.Resume(context.Background(), name); err != nil {
+		h.logger.WithError(err).WithField("destination", name).Error("Failed to deliver buffered alerts after pause expired")
+	}
+}
+
+// deliverBuffered sends payloads buffered while the destination was paused and returns those that failed
+func (h *Handler) deliverBuffered(ctx context.Context, name string, payloads []*alertmanager.WebhookPayload) (int, []*alertmanager.WebhookPayload, error) {
+	delivered := 0
+	var failed []*alertmanager.WebhookPayload
+	var firstErr error
+
+	// Each buffered payload gets the deadline of a webhook
+	timeout := h.webhookTimeout()
+
+	for _, payload := range payloads {
+		sendCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := h.redeliver(sendCtx, name, payload)
+		cancel()
+
+		if err != nil {
+			h.logger.WithContext(ctx).WithError(err).WithField("destination", name).Error("Failed to deliver buffered alerts")
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed = append(failed, payload)
+			continue
+		}
+		delivered++
+	}
+
+	if firstErr != nil {
+		return delivered, failed, fmt.Errorf("failed to deliver %d of %d buffered payloads: %w", len(failed), len(payloads), firstErr)
+	}
+
+	return delivered, nil, nil
+}
+
+func (s *pauseState) status(mode string) PauseStatus {
+	return PauseStatus{
+		Since:    s.since,
+		Until:    s.until,
+		Reason:   s.reason,
+		Mode:     mode,
+		Buffered: len(s.buffer),
+	}
+}
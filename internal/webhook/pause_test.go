@@ -0,0 +1,255 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+	"github.com/vitalvas/alertmanager-gateway/internal/config"
+	"github.com/vitalvas/alertmanager-gateway/internal/deadletter"
+	"github.com/vitalvas/alertmanager-gateway/internal/destination"
+)
+
+func newPausableHandler(mode string, bufferSize int, received *int) *Handler {
+	cfg := &config.Config{
+		Destinations: []config.DestinationConfig{
+			{Name: "chat", Enabled: true, Pause: config.PauseConfig{Mode: mode, BufferSize: bufferSize}},
+		},
+	}
+
+	return &Handler{
+		config: cfg,
+		logger: logrus.New(),
+		handlers: map[string]destination.Handler{
+			"chat": &mockDestinationHandler{
+				name: "chat",
+				sendFunc: func(_ context.Context, payload *alertmanager.WebhookPayload) error {
+					*received += len(payload.Alerts)
+					return nil
+				},
+			},
+		},
+		now: time.Now,
+	}
+}
+
+func sendPaused(t *testing.T, handler *Handler) *httptest.ResponseRecorder {
+	t.Helper()
+
+	body := `{"version":"4","groupKey":"g","status":"firing","alerts":[{"status":"firing","fingerprint":"abc","startsAt":"2024-01-01T00:00:00Z"}]}`
+	req := httptest.NewRequest("POST", "/webhook/chat", strings.NewReader(body))
+	req = mux.SetURLVars(req, map[string]string{"destination": "chat"})
+	w := httptest.NewRecorder()
+	handler.HandleWebhook(w, req)
+
+	return w
+}
+
+func TestHandler_PauseModes(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       string
+		wantCode   int
+		wantStatus string
+		wantResume int
+	}{
+		{name: "reject", mode: config.PauseModeReject, wantCode: http.StatusServiceUnavailable, wantStatus: "error"},
+		{name: "drop", mode: config.PauseModeDrop, wantCode: http.StatusOK, wantStatus: "paused"},
+		{name: "buffer", mode: config.PauseModeBuffer, wantCode: http.StatusOK, wantStatus: "buffered", wantResume: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received := 0
+			handler := newPausableHandler(tt.mode, 10, &received)
+
+			_, err := handler.Pause(context.Background(), "chat", 0, "maintenance")
+			require.NoError(t, err)
+
+			w := sendPaused(t, handler)
+			assert.Equal(t, tt.wantCode, w.Code)
+
+			var resp struct {
+				Status string `json:"status"`
+			}
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+			assert.Equal(t, tt.wantStatus, resp.Status)
+			assert.Equal(t, 0, received)
+
+			delivered, err := handler.Resume(context.Background(), "chat")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantResume, delivered)
+			assert.Equal(t, tt.wantResume, received)
+
+			_, paused := handler.PauseStatus("chat")
+			assert.False(t, paused)
+
+			w = sendPaused(t, handler)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.wantResume+1, received)
+		})
+	}
+}
+
+func TestHandler_PauseTTL(t *testing.T) {
+	received := 0
+	handler := newPausableHandler(config.PauseModeReject, 0, &received)
+
+	status, err := handler.Pause(context.Background(), "chat", time.Hour, "deploy")
+	require.NoError(t, err)
+	assert.Equal(t, "deploy", status.Reason)
+	assert.Equal(t, config.PauseModeReject, status.Mode)
+	assert.False(t, status.Until.IsZero())
+
+	w := sendPaused(t, handler)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	// Re-pausing with a short TTL replaces the previous timer
+	_, err = handler.Pause(context.Background(), "chat", 10*time.Millisecond, "deploy")
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		_, paused := handler.PauseStatus("chat")
+		return !paused
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestHandler_PauseBufferLimit(t *testing.T) {
+	received := 0
+	handler := newPausableHandler(config.PauseModeBuffer, 2, &received)
+
+	_, err := handler.Pause(context.Background(), "chat", 0, "")
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		sendPaused(t, handler)
+	}
+
+	status, paused := handler.PauseStatus("chat")
+	require.True(t, paused)
+	assert.Equal(t, 2, status.Buffered)
+
+	delivered, err := handler.Resume(context.Background(), "chat")
+	require.NoError(t, err)
+	assert.Equal(t, 2, delivered)
+}
+
+func TestHandler_PauseBufferedDeadline(t *testing.T) {
+	received := 0
+	handler := newPausableHandler(config.PauseModeBuffer, 10, &received)
+	handler.config.Server.WebhookTimeout = 50 * time.Millisecond
+
+	var deadline time.Duration
+	handler.handlers["chat"].(*mockDestinationHandler).sendFunc = func(ctx context.Context, _ *alertmanager.WebhookPayload) error {
+		until, _ := ctx.Deadline()
+		deadline = time.Until(until)
+		return nil
+	}
+
+	_, err := handler.Pause(context.Background(), "chat", 0, "")
+	require.NoError(t, err)
+	sendPaused(t, handler)
+
+	delivered, err := handler.Resume(context.Background(), "chat")
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.LessOrEqual(t, deadline, 50*time.Millisecond)
+	assert.Positive(t, deadline)
+}
+
+func TestHandler_PauseUnknownDestination(t *testing.T) {
+	received := 0
+	handler := newPausableHandler(config.PauseModeReject, 0, &received)
+
+	_, err := handler.Pause(context.Background(), "missing", 0, "")
+	assert.ErrorIs(t, err, ErrDestinationNotFound)
+
+	_, err = handler.Resume(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrDestinationNotFound)
+
+	delivered, err := handler.Resume(context.Background(), "chat")
+	require.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+}
+
+func TestHandler_ResumeKeepsFailedPayloads(t *testing.T) {
+	newFailingHandler := func(t *testing.T) *Handler {
+		t.Helper()
+
+		received := 0
+		handler := newPausableHandler(config.PauseModeBuffer, 10, &received)
+
+		attempts := 0
+		handler.handlers["chat"].(*mockDestinationHandler).sendFunc = func(ctx context.Context, _ *alertmanager.WebhookPayload) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			attempts++
+			if attempts == 2 {
+				return assert.AnError
+			}
+			return nil
+		}
+
+		_, err := handler.Pause(context.Background(), "chat", 0, "maintenance")
+		require.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			sendPaused(t, handler)
+		}
+
+		return handler
+	}
+
+	// A request that is already gone must not stop the buffered payloads
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	t.Run("kept buffered", func(t *testing.T) {
+		handler := newFailingHandler(t)
+
+		delivered, err := handler.Resume(cancelled, "chat")
+		require.Error(t, err)
+		assert.Equal(t, 2, delivered)
+
+		status, paused := handler.PauseStatus("chat")
+		require.True(t, paused)
+		assert.Equal(t, 1, status.Buffered)
+		assert.Equal(t, "maintenance", status.Reason)
+
+		delivered, err = handler.Resume(context.Background(), "chat")
+		require.NoError(t, err)
+		assert.Equal(t, 1, delivered)
+	})
+
+	t.Run("dead-lettered", func(t *testing.T) {
+		handler := newFailingHandler(t)
+
+		path := filepath.Join(t.TempDir(), "deadletter.jsonl")
+		store, err := deadletter.New(path)
+		require.NoError(t, err)
+		handler.deadLetter = store
+
+		delivered, err := handler.Resume(cancelled, "chat")
+		require.Error(t, err)
+		assert.Equal(t, 2, delivered)
+
+		_, paused := handler.PauseStatus("chat")
+		assert.False(t, paused)
+
+		require.NoError(t, store.Close())
+		entries := readDeadLetters(t, path)
+		require.Len(t, entries, 1)
+		assert.Equal(t, deadletter.ReasonPaused, entries[0].Reason)
+	})
+}
@@ -0,0 +1,149 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vitalvas/alertmanager-gateway/internal/config"
+	"github.com/vitalvas/alertmanager-gateway/internal/destination"
+	"github.com/vitalvas/alertmanager-gateway/internal/transform"
+)
+
+// ReloadResult summarises an applied configuration reload
+type ReloadResult struct {
+	Destinations int
+	Templates    []string
+	Modules      []string
+}
+
+// loadLibrary builds the shared template library of a configuration
+func loadLibrary(cfg *config.Config) (*transform.Library, error) {
+	library, err := transform.LoadLibrary(cfg.Templates.Directory, cfg.Templates.Go, cfg.Templates.JQ)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load templates: %w", err)
+	}
+
+	return library, nil
+}
+
+// Library returns the shared templates and jq modules available to destinations
+func (h *Handler) Library() *transform.Library {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.library
+}
+
+// Reload applies the templates, time intervals and destinations of a new configuration. Every
+// destination handler is rebuilt first, so a configuration that fails to load leaves the running
+// one untouched. Other sections only take effect on restart.
+func (h *Handler) Reload(ctx context.Context, cfg *config.Config) (*ReloadResult, error) {
+	return h.ReloadFrom(ctx, func(*config.Overlay) (*config.Config, error) {
+		return cfg, nil
+	})
+}
+
+// ReloadFrom applies the configuration returned by load like Reload. load receives a copy of
+// the destination changes made through the API, or nil when destinations are not managed
+// through the API, so it can merge them. API changes wait until the reload completes.
+func (h *Handler) ReloadFrom(ctx context.Context, load func(overlay *config.Overlay) (*config.Config, error)) (*ReloadResult, error) {
+	h.manageMu.Lock()
+	defer h.manageMu.Unlock()
+
+	var overlay *config.Overlay
+	if h.overlay != nil {
+		overlay = h.overlay.Clone()
+	}
+
+	cfg, err := load(overlay)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the version and timestamps of destinations that are still running
+	h.mu.RLock()
+	cfg.CarryMetadata(h.config.Destinations, time.Now().UTC())
+	h.mu.RUnlock()
+
+	library, err := loadLibrary(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	intervals, err := compileIntervals(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	built := make(map[string]*components)
+	for i := range cfg.Destinations {
+		destCfg := &cfg.Destinations[i]
+		if !destCfg.Enabled {
+			continue
+		}
+
+		c, err := h.buildComponents(destCfg, intervals, library)
+		if err != nil {
+			for name, c := range built {
+				h.closeHandler(name, c.handler)
+			}
+			return nil, err
+		}
+		built[destCfg.Name] = c
+	}
+
+	h.mu.Lock()
+	previous := make(map[string]destination.Handler, len(h.handlers))
+	for name, handler := range h.handlers {
+		previous[name] = handler
+		h.setComponents(name, nil)
+	}
+	for name, c := range built {
+		h.setComponents(name, c)
+	}
+
+	removed := make([]string, 0)
+	for _, dest := range h.config.Destinations {
+		if cfg.GetDestinationByNameAny(dest.Name) == nil {
+			removed = append(removed, dest.Name)
+		}
+	}
+
+	h.config.Destinations = cfg.Destinations
+	h.config.Templates = cfg.Templates
+	h.config.TimeIntervals = cfg.TimeIntervals
+	h.intervals = intervals
+	h.library = library
+	h.mu.Unlock()
+
+	// Digests of destinations that are still enabled move to the new handlers; others are sent
+	for name, handler := range previous {
+		var next destination.Handler
+		if c, ok := built[name]; ok {
+			next = c.handler
+		}
+		h.replaceHandler(name, handler, next)
+	}
+
+	for _, name := range removed {
+		h.clearPause(name)
+		h.clearFailures(name)
+		h.ResetStats(name)
+	}
+
+	result := &ReloadResult{
+		Destinations: len(cfg.Destinations),
+		Templates:    library.Templates(),
+		Modules:      library.Modules(),
+	}
+
+	h.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"destinations": result.Destinations,
+		"templates":    len(result.Templates),
+		"modules":      len(result.Modules),
+	}).Info("Configuration reloaded")
+
+	return result, nil
+}
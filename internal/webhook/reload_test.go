@@ -0,0 +1,131 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/alertmanager-gateway/internal/config"
+)
+
+func TestHandler_Reload(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		bodies []map[string]interface{}
+	)
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]interface{}
+		_ = json.Unmarshal(data, &body)
+
+		mu.Lock()
+		bodies = append(bodies, body)
+		mu.Unlock()
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	newConfig := func(title string, destinations ...string) *config.Config {
+		cfg := &config.Config{
+			Server:    config.ServerConfig{Address: ":8080"},
+			Templates: config.TemplatesConfig{Go: map[string]string{"title": title}},
+		}
+		for _, name := range destinations {
+			cfg.Destinations = append(cfg.Destinations, config.DestinationConfig{
+				Name:     name,
+				URL:      target.URL,
+				Method:   "POST",
+				Format:   "json",
+				Engine:   "go-template",
+				Template: `{"title": "{{ template "title" . }}"}`,
+				Enabled:  true,
+			})
+		}
+		return cfg
+	}
+
+	handler, err := NewHandler(newConfig("v1 {{ .Status }}", "first", "second"), logrus.New())
+	require.NoError(t, err)
+	defer handler.Close()
+
+	assert.Equal(t, http.StatusOK, postWebhook(handler, "first"))
+
+	result, err := handler.Reload(context.Background(), newConfig("v2 {{ .Status }}", "first"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Destinations)
+	assert.Equal(t, []string{"title"}, result.Templates)
+
+	assert.Equal(t, http.StatusOK, postWebhook(handler, "first"))
+	assert.Equal(t, http.StatusNotFound, postWebhook(handler, "second"))
+
+	mu.Lock()
+	require.Len(t, bodies, 2)
+	assert.Equal(t, "v1 firing", bodies[0]["title"])
+	assert.Equal(t, "v2 firing", bodies[1]["title"])
+	mu.Unlock()
+
+	t.Run("invalid configuration keeps the running one", func(t *testing.T) {
+		_, err := handler.Reload(context.Background(), newConfig("{{ .Status ", "first"))
+		require.Error(t, err)
+
+		assert.Equal(t, http.StatusOK, postWebhook(handler, "first"))
+		assert.Equal(t, []string{"title"}, handler.Library().Templates())
+
+		mu.Lock()
+		assert.Equal(t, "v2 firing", bodies[len(bodies)-1]["title"])
+		mu.Unlock()
+	})
+}
+
+func TestHandler_ReloadKeepsDigests(t *testing.T) {
+	var requests atomic.Int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	newConfig := func(destinations ...string) *config.Config {
+		cfg := &config.Config{Server: config.ServerConfig{Address: ":8080"}}
+		for _, name := range destinations {
+			cfg.Destinations = append(cfg.Destinations, config.DestinationConfig{
+				Name:     name,
+				URL:      target.URL,
+				Method:   "POST",
+				Format:   "json",
+				Engine:   "go-template",
+				Template: `{"count": {{ len .Alerts }}}`,
+				Enabled:  true,
+				Digest:   config.DigestConfig{Window: time.Hour},
+			})
+		}
+		return cfg
+	}
+
+	handler, err := NewHandler(newConfig("kept", "removed"), logrus.New())
+	require.NoError(t, err)
+	defer handler.Close()
+
+	assert.Equal(t, http.StatusOK, postWebhook(handler, "kept"))
+	assert.Equal(t, http.StatusOK, postWebhook(handler, "removed"))
+
+	// The digest of the kept destination waits for its window; the removed one is sent
+	_, err = handler.Reload(context.Background(), newConfig("kept"))
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), requests.Load())
+
+	sent, err := handler.FlushDigest(context.Background(), "kept")
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, int32(2), requests.Load())
+}
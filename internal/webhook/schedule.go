@@ -0,0 +1,267 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+	"github.com/vitalvas/alertmanager-gateway/internal/config"
+	"github.com/vitalvas/alertmanager-gateway/internal/timeinterval"
+)
+
+// maxQueuedPayloads limits how many payloads are held per destination outside active intervals
+const maxQueuedPayloads = 1000
+
+// queueCheckInterval is how often queued payloads are checked against destination schedules
+var queueCheckInterval = 30 * time.Second
+
+// schedule describes when a destination is active
+type schedule struct {
+	active timeinterval.Set
+	mute   timeinterval.Set
+}
+
+// isActive reports whether the destination accepts alerts at the given time
+func (s *schedule) isActive(t time.Time) bool {
+	if len(s.active) > 0 && !s.active.Contains(t) {
+		return false
+	}
+
+	return !s.mute.Contains(t)
+}
+
+// compileIntervals compiles all named time intervals of the configuration
+func compileIntervals(cfg *config.Config) (map[string]timeinterval.Set, error) {
+	intervals := make(map[string]timeinterval.Set, len(cfg.TimeIntervals))
+	for _, interval := range cfg.TimeIntervals {
+		set, err := timeinterval.CompileSet(interval.TimeIntervals)
+		if err != nil {
+			return nil, fmt.Errorf("time interval %s: %w", interval.Name, err)
+		}
+		intervals[interval.Name] = set
+	}
+
+	return intervals, nil
+}
+
+// newSchedule builds the schedule of a destination, returning nil if it is always active
+func newSchedule(dest *config.DestinationConfig, intervals map[string]timeinterval.Set) (*schedule, error) {
+	if len(dest.ActiveTimeIntervals) == 0 && len(dest.MuteTimeIntervals) == 0 {
+		return nil, nil
+	}
+
+	sched := &schedule{}
+	for _, name := range dest.ActiveTimeIntervals {
+		set, ok := intervals[name]
+		if !ok {
+			return nil, fmt.Errorf("destination %s: unknown time interval %s", dest.Name, name)
+		}
+		sched.active = append(sched.active, set...)
+	}
+
+	for _, name := range dest.MuteTimeIntervals {
+		set, ok := intervals[name]
+		if !ok {
+			return nil, fmt.Errorf("destination %s: unknown time interval %s", dest.Name, name)
+		}
+		sched.mute = append(sched.mute, set...)
+	}
+
+	return sched, nil
+}
+
+// payloadQueue holds payloads received outside a destination's active intervals
+type payloadQueue struct {
+	mu       sync.Mutex
+	payloads map[string][]*alertmanager.WebhookPayload
+}
+
+func newPayloadQueue() *payloadQueue {
+	return &payloadQueue{payloads: make(map[string][]*alertmanager.WebhookPayload)}
+}
+
+// push adds a payload, dropping the oldest one when the destination queue is full
+func (q *payloadQueue) push(destName string, payload *alertmanager.WebhookPayload) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	queue := append(q.payloads[destName], payload)
+	dropped := len(queue) > maxQueuedPayloads
+	if dropped {
+		queue = queue[1:]
+	}
+	q.payloads[destName] = queue
+
+	return dropped
+}
+
+// requeue puts payloads that could not be delivered back at the front of a destination queue,
+// ahead of payloads that arrived meanwhile. When the queue is full the newest payloads are
+// dropped, so the undelivered ones are retried first. It returns the number dropped.
+func (q *payloadQueue) requeue(destName string, payloads []*alertmanager.WebhookPayload) int {
+	if len(payloads) == 0 {
+		return 0
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	queue := append(append([]*alertmanager.WebhookPayload{}, payloads...), q.payloads[destName]...)
+	dropped := 0
+	if len(queue) > maxQueuedPayloads {
+		dropped = len(queue) - maxQueuedPayloads
+		queue = queue[:maxQueuedPayloads]
+	}
+	q.payloads[destName] = queue
+
+	return dropped
+}
+
+// take removes and returns all queued payloads of a destination
+func (q *payloadQueue) take(destName string) []*alertmanager.WebhookPayload {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	payloads := q.payloads[destName]
+	delete(q.payloads, destName)
+
+	return payloads
+}
+
+// len returns the number of queued payloads for a destination
+func (q *payloadQueue) len(destName string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.payloads[destName])
+}
+
+// destinations returns the names of destinations with queued payloads
+func (q *payloadQueue) destinations() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	names := make([]string, 0, len(q.payloads))
+	for name := range q.payloads {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// handleInactive applies the outside_active behaviour of a destination that is not currently active
+func (h *Handler) handleInactive(ctx context.Context, dest *config.DestinationConfig, payload *alertmanager.WebhookPayload, result *deliveryResult, allowRedirect bool) (*deliveryResult, error) {
+	logger := h.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"destination":    dest.Name,
+		"outside_active": dest.OutsideActive,
+		"alerts_count":   len(payload.Alerts),
+	})
+
+	switch dest.OutsideActive {
+	case config.OutsideActiveQueue:
+		if h.queue.push(dest.Name, payload) {
+			logger.Warn("Queue for inactive destination is full, dropped oldest payload")
+		}
+		logger.Debug("Queued alerts until destination becomes active")
+
+		result.Sent = 0
+		result.Queued = true
+		return result, nil
+
+	case config.OutsideActiveRedirect:
+		if _, exists := h.handler(dest.FallbackDestination); allowRedirect && exists {
+			logger.WithField("fallback_destination", dest.FallbackDestination).Debug("Redirecting alerts to fallback destination")
+
+			fallbackResult, err := h.deliverTo(ctx, dest.FallbackDestination, payload, false)
+			if err != nil {
+				return nil, fmt.Errorf("fallback destination %s: %w", dest.FallbackDestination, err)
+			}
+
+			fallbackResult.Skipped += result.Skipped
+			fallbackResult.RedirectedTo = dest.FallbackDestination
+			return fallbackResult, nil
+		}
+
+		logger.WithField("fallback_destination", dest.FallbackDestination).Warn("Fallback destination unavailable, dropping alerts")
+	}
+
+	result.Muted = result.Sent
+	result.Sent = 0
+
+	return result, nil
+}
+
+// startQueueScheduler periodically delivers queued payloads to destinations that became active
+func (h *Handler) startQueueScheduler() {
+	h.schedulerOnce.Do(h.runQueueScheduler)
+}
+
+func (h *Handler) runQueueScheduler() {
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+
+		ticker := time.NewTicker(queueCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				h.flushQueues(context.Background())
+			case <-h.done:
+				return
+			}
+		}
+	}()
+}
+
+// flushQueues delivers queued payloads of every destination that is active now
+func (h *Handler) flushQueues(ctx context.Context) {
+	now := h.now()
+
+	// Each queued payload gets the deadline of a webhook
+	timeout := h.webhookTimeout()
+
+	for _, destName := range h.queue.destinations() {
+		h.mu.RLock()
+		sched, ok := h.schedules[destName]
+		h.mu.RUnlock()
+
+		if ok && !sched.isActive(now) {
+			continue
+		}
+
+		payloads := h.queue.take(destName)
+		delivered := 0
+
+		for _, payload := range payloads {
+			sendCtx, cancel := context.WithTimeout(ctx, timeout)
+			err := h.redeliver(sendCtx, destName, payload)
+			cancel()
+
+			if err != nil {
+				h.logger.WithContext(ctx).WithError(err).WithField("destination", destName).Error("Failed to deliver queued alerts")
+				break
+			}
+			delivered++
+		}
+
+		// Keep undelivered payloads for the next attempt
+		if dropped := h.queue.requeue(destName, payloads[delivered:]); dropped > 0 {
+			h.logger.WithContext(ctx).WithFields(logrus.Fields{
+				"destination": destName,
+				"dropped":     dropped,
+			}).Warn("Queue outside active time intervals is full, dropped newest payloads")
+		}
+
+		if delivered > 0 {
+			h.logger.WithContext(ctx).WithFields(logrus.Fields{
+				"destination": destName,
+				"payloads":    delivered,
+			}).Info("Delivered alerts queued outside active time intervals")
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/alertmanager-gateway/internal/alertmanager"
+	"github.com/vitalvas/alertmanager-gateway/internal/config"
+	"github.com/vitalvas/alertmanager-gateway/internal/destination"
+	"github.com/vitalvas/alertmanager-gateway/internal/timeinterval"
+)
+
+func newScheduledHandler(t *testing.T, outsideActive string, received map[string]int) *Handler {
+	t.Helper()
+
+	cfg := &config.Config{
+		TimeIntervals: []config.TimeIntervalConfig{
+			{
+				Name: "business-hours",
+				TimeIntervals: []timeinterval.Spec{
+					{Weekdays: []string{"monday:friday"}, Times: []timeinterval.TimeRange{{StartTime: "09:00", EndTime: "17:00"}}},
+				},
+			},
+		},
+		Destinations: []config.DestinationConfig{
+			{
+				Name:                "chat",
+				Enabled:             true,
+				ActiveTimeIntervals: []string{"business-hours"},
+				OutsideActive:       outsideActive,
+				FallbackDestination: "sms",
+			},
+			{Name: "sms", Enabled: true},
+		},
+	}
+
+	intervals, err := compileIntervals(cfg)
+	require.NoError(t, err)
+
+	sched, err := newSchedule(&cfg.Destinations[0], intervals)
+	require.NoError(t, err)
+
+	handlers := make(map[string]destination.Handler)
+	for _, name := range []string{"chat", "sms"} {
+		handlers[name] = &mockDestinationHandler{
+			name: name,
+			sendFunc: func(_ context.Context, payload *alertmanager.WebhookPayload) error {
+				received[name] += len(payload.Alerts)
+				return nil
+			},
+		}
+	}
+
+	return &Handler{
+		config:    cfg,
+		logger:    logrus.New(),
+		handlers:  handlers,
+		schedules: map[string]*schedule{"chat": sched},
+		queue:     newPayloadQueue(),
+		now:       time.Now,
+	}
+}
+
+func sendScheduled(t *testing.T, handler *Handler) Response {
+	t.Helper()
+
+	body := `{"version":"4","groupKey":"g","status":"firing","alerts":[{"status":"firing","fingerprint":"abc","startsAt":"2024-01-01T00:00:00Z"}]}`
+	req := httptest.NewRequest("POST", "/webhook/chat", strings.NewReader(body))
+	req = mux.SetURLVars(req, map[string]string{"destination": "chat"})
+	w := httptest.NewRecorder()
+	handler.HandleWebhook(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp
+}
+
+func TestHandler_TimeIntervals(t *testing.T) {
+	businessHours := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC) // Monday
+	night := time.Date(2024, 1, 15, 22, 0, 0, 0, time.UTC)
+
+	t.Run("active destination sends", func(t *testing.T) {
+		received := map[string]int{}
+		handler := newScheduledHandler(t, config.OutsideActiveDrop, received)
+		handler.now = func() time.Time { return businessHours }
+
+		resp := sendScheduled(t, handler)
+		assert.Equal(t, "success", resp.Status)
+		assert.Equal(t, 1, received["chat"])
+	})
+
+	t.Run("drop outside active intervals", func(t *testing.T) {
+		received := map[string]int{}
+		handler := newScheduledHandler(t, config.OutsideActiveDrop, received)
+		handler.now = func() time.Time { return night }
+
+		resp := sendScheduled(t, handler)
+		assert.Equal(t, "muted", resp.Status)
+		assert.Equal(t, 1, resp.MutedAlerts)
+		assert.Empty(t, received)
+	})
+
+	t.Run("queue until active", func(t *testing.T) {
+		received := map[string]int{}
+		handler := newScheduledHandler(t, config.OutsideActiveQueue, received)
+		handler.now = func() time.Time { return night }
+
+		resp := sendScheduled(t, handler)
+		assert.Equal(t, "queued", resp.Status)
+		assert.Equal(t, 1, handler.queue.len("chat"))
+
+		handler.flushQueues(context.Background())
+		assert.Empty(t, received)
+
+		handler.now = func() time.Time { return businessHours }
+		handler.flushQueues(context.Background())
+		assert.Equal(t, 1, received["chat"])
+		assert.Equal(t, 0, handler.queue.len("chat"))
+	})
+
+	t.Run("redirect to fallback", func(t *testing.T) {
+		received := map[string]int{}
+		handler := newScheduledHandler(t, config.OutsideActiveRedirect, received)
+		handler.now = func() time.Time { return night }
+
+		resp := sendScheduled(t, handler)
+		assert.Equal(t, "redirected", resp.Status)
+		assert.Equal(t, "sms", resp.RedirectedTo)
+		assert.Equal(t, 1, received["sms"])
+		assert.Zero(t, received["chat"])
+	})
+}
+
+func TestSchedule_MuteIntervals(t *testing.T) {
+	cfg := &config.Config{
+		TimeIntervals: []config.TimeIntervalConfig{
+			{Name: "maintenance", TimeIntervals: []timeinterval.Spec{{Weekdays: []string{"sunday"}}}},
+		},
+		Destinations: []config.DestinationConfig{
+			{Name: "muted", MuteTimeIntervals: []string{"maintenance"}},
+			{Name: "always"},
+		},
+	}
+
+	intervals, err := compileIntervals(cfg)
+	require.NoError(t, err)
+
+	muted, err := newSchedule(&cfg.Destinations[0], intervals)
+	require.NoError(t, err)
+	require.NotNil(t, muted)
+
+	always, err := newSchedule(&cfg.Destinations[1], intervals)
+	require.NoError(t, err)
+	assert.Nil(t, always)
+
+	assert.False(t, muted.isActive(time.Date(2024, 1, 14, 12, 0, 0, 0, time.UTC))) // Sunday
+	assert.True(t, muted.isActive(time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)))  // Monday
+
+	cfg.Destinations[0].MuteTimeIntervals = []string{"missing"}
+	_, err = newSchedule(&cfg.Destinations[0], intervals)
+	assert.Error(t, err)
+}
+
+func TestPayloadQueue_Limit(t *testing.T) {
+	queue := newPayloadQueue()
+
+	for i := 0; i < maxQueuedPayloads; i++ {
+		assert.False(t, queue.push("dest", &alertmanager.WebhookPayload{}))
+	}
+	assert.True(t, queue.push("dest", &alertmanager.WebhookPayload{}))
+	assert.Equal(t, maxQueuedPayloads, queue.len("dest"))
+}
+
+func TestPayloadQueue_Requeue(t *testing.T) {
+	queue := newPayloadQueue()
+
+	undelivered := []*alertmanager.WebhookPayload{{GroupKey: "first"}, {GroupKey: "second"}}
+	for i := 0; i < maxQueuedPayloads; i++ {
+		queue.push("dest", &alertmanager.WebhookPayload{GroupKey: "arrived"})
+	}
+
+	assert.Equal(t, 2, queue.requeue("dest", undelivered))
+	assert.Zero(t, queue.requeue("dest", nil))
+
+	payloads := queue.take("dest")
+	require.Len(t, payloads, maxQueuedPayloads)
+	assert.Equal(t, "first", payloads[0].GroupKey)
+	assert.Equal(t, "second", payloads[1].GroupKey)
+	assert.Equal(t, "arrived", payloads[2].GroupKey)
+}
+
+func TestHandler_FlushQueuesKeepsOrder(t *testing.T) {
+	received := map[string]int{}
+	handler := newScheduledHandler(t, config.OutsideActiveQueue, received)
+	handler.now = time.Now
+
+	var sent []string
+	handler.handlers["chat"].(*mockDestinationHandler).sendFunc = func(_ context.Context, payload *alertmanager.WebhookPayload) error {
+		if payload.GroupKey == "second" {
+			// A payload arrives while the queue is being flushed
+			handler.queue.push("chat", &alertmanager.WebhookPayload{GroupKey: "arrived"})
+			return assert.AnError
+		}
+		sent = append(sent, payload.GroupKey)
+		return nil
+	}
+
+	for _, key := range []string{"first", "second", "third"} {
+		handler.queue.push("chat", &alertmanager.WebhookPayload{GroupKey: key})
+	}
+	delete(handler.schedules, "chat")
+
+	handler.flushQueues(context.Background())
+	assert.Equal(t, []string{"first"}, sent)
+
+	keys := make([]string, 0)
+	for _, payload := range handler.queue.take("chat") {
+		keys = append(keys, payload.GroupKey)
+	}
+	assert.Equal(t, []string{"second", "third", "arrived"}, keys)
+}
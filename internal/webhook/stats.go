@@ -0,0 +1,24 @@
+package webhook
+
+import "github.com/vitalvas/alertmanager-gateway/internal/destination"
+
+// DestinationStats returns the request statistics of a destination
+func (h *Handler) DestinationStats(name string) destination.StatsSnapshot {
+	return h.stats.Snapshot(name)
+}
+
+// ResetStats clears the request statistics of a destination
+func (h *Handler) ResetStats(name string) {
+	h.stats.Reset(name)
+}
+
+// ResetAllStats clears the request statistics of every destination and the received webhook count
+func (h *Handler) ResetAllStats() {
+	h.stats.ResetAll()
+	h.received.Store(0)
+}
+
+// ReceivedWebhooks returns the number of webhook and alerts API requests received since the last reset
+func (h *Handler) ReceivedWebhooks() int64 {
+	return h.received.Load()
+}
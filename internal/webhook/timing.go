@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/vitalvas/alertmanager-gateway/internal/destination"
+)
+
+// defaultWebhookTimeout bounds a webhook when the server configuration sets no deadline
+const defaultWebhookTimeout = 30 * time.Second
+
+// Timing breaks down where the time handling a webhook was spent, in milliseconds
+type Timing struct {
+	ParseMS     float64 `json:"parse_ms"`
+	TransformMS float64 `json:"transform_ms"`
+	FormatMS    float64 `json:"format_ms"`
+	SendMS      float64 `json:"send_ms"`
+	TotalMS     float64 `json:"total_ms"`
+}
+
+// newTiming builds the timing breakdown of a webhook. The send details are absent when
+// the payload was not sent, for example when it was filtered out or buffered.
+func newTiming(parse time.Duration, info *destination.SendInfo, total time.Duration) *Timing {
+	timing := &Timing{
+		ParseMS: milliseconds(parse),
+		TotalMS: milliseconds(total),
+	}
+
+	if info != nil {
+		timing.TransformMS = milliseconds(info.TransformDuration())
+		timing.FormatMS = milliseconds(info.FormatDuration())
+		timing.SendMS = milliseconds(info.SendDuration())
+	}
+
+	return timing
+}
+
+// milliseconds converts a duration to fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// webhookTimeout returns the deadline for handling a single webhook
+func (h *Handler) webhookTimeout() time.Duration {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if h.config.Server.WebhookTimeout > 0 {
+		return h.config.Server.WebhookTimeout
+	}
+
+	return defaultWebhookTimeout
+}
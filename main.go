@@ -7,6 +7,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/vitalvas/alertmanager-gateway/internal/config"
+	"github.com/vitalvas/alertmanager-gateway/internal/requestid"
 	"github.com/vitalvas/alertmanager-gateway/internal/server"
 )
 
@@ -49,6 +50,7 @@ func run(_ *cobra.Command, _ []string) {
 
 	logger.WithFields(logrus.Fields{
 		"address":      cfg.Server.Address,
+		"listeners":    len(cfg.Server.Listeners),
 		"destinations": len(cfg.Destinations),
 		"auth_enabled": cfg.Server.Auth.Enabled,
 	}).Info("Configuration loaded successfully")
@@ -99,5 +101,10 @@ func setupLogger(level, format string) *logrus.Logger {
 		})
 	}
 
+	// Add the request id of the entry context to every log line, including the standard
+	// logger used by destination handlers
+	logger.AddHook(requestid.LogHook{})
+	logrus.AddHook(requestid.LogHook{})
+
 	return logger
 }